@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 
 	"github.com/thetillhoff/temingo/internal/refcheck"
+	"github.com/thetillhoff/temingo/pkg/markdown2html"
+	"github.com/thetillhoff/temingo/pkg/temingo"
 	"github.com/urfave/cli/v3"
 	"gopkg.in/yaml.v3"
 )
@@ -43,6 +45,87 @@ func allowlistFromConfig(config map[string]interface{}) refcheck.Allowlist {
 	return list
 }
 
+// markdownFromConfig reads the markdown block: the extensions to enable, hard
+// wraps, raw html, and the style and stylesheet path of code highlighting.
+// Absent or malformed keys keep their defaults.
+func markdownFromConfig(config map[string]interface{}) (markdown2html.Options, string) {
+	options := markdown2html.Options{}
+	stylesheet := "highlight.css"
+
+	raw, ok := config["markdown"].(map[string]interface{})
+	if !ok {
+		return options, stylesheet
+	}
+
+	getBool := func(key string, target *bool) {
+		if b, ok := raw[key].(bool); ok {
+			*target = b
+		}
+	}
+	getBool("footnotes", &options.Footnotes)
+	getBool("definitionLists", &options.DefinitionLists)
+	getBool("typographer", &options.Typographer)
+	getBool("tableOfContents", &options.TableOfContents)
+	getBool("attributes", &options.Attributes)
+	getBool("unsafe", &options.Unsafe)
+	if hardWraps, ok := raw["hardWraps"].(bool); ok {
+		options.NoHardWraps = !hardWraps
+	}
+
+	if highlight, ok := raw["highlight"].(map[string]interface{}); ok {
+		if style, ok := highlight["style"].(string); ok {
+			options.HighlightStyle = style
+		}
+		if path, ok := highlight["stylesheet"].(string); ok && path != "" {
+			stylesheet = path
+		}
+	}
+
+	return options, stylesheet
+}
+
+// imagesFromConfig reads the images block: the default widths of image
+// variants and their quality. Absent or malformed keys keep their defaults.
+func imagesFromConfig(config map[string]interface{}) temingo.ImageOptions {
+	options := temingo.DefaultEngine().Images
+
+	raw, ok := config["images"].(map[string]interface{})
+	if !ok {
+		return options
+	}
+
+	if widths, ok := raw["widths"].([]interface{}); ok {
+		options.Widths = []int{}
+		for _, w := range widths {
+			if width, ok := w.(int); ok {
+				options.Widths = append(options.Widths, width)
+			}
+		}
+	}
+	if quality, ok := raw["quality"].(int); ok {
+		options.Quality = quality
+	}
+
+	return options
+}
+
+// taxonomiesFromConfig reads the taxonomies list: the meta keys whose terms are
+// collected across every page. An absent or malformed list keeps the default.
+func taxonomiesFromConfig(config map[string]interface{}) []string {
+	raw, ok := config["taxonomies"].([]interface{})
+	if !ok {
+		return temingo.DefaultEngine().Taxonomies
+	}
+
+	taxonomies := []string{}
+	for _, item := range raw {
+		if taxonomy, ok := item.(string); ok {
+			taxonomies = append(taxonomies, taxonomy)
+		}
+	}
+	return taxonomies
+}
+
 // loadConfig reads configuration from a YAML file in the current working directory
 // It supports reading from a specific file path or defaults to .temingo.yaml in the current directory
 func loadConfig(cfgFile string) (map[string]interface{}, error) {
@@ -84,11 +167,11 @@ func loadConfig(cfgFile string) (map[string]interface{}, error) {
 // Config values are applied first, then CLI/env values override if they were explicitly set
 func applyConfigToFlags(cmd *cli.Command, config map[string]interface{},
 	inputDirFlag, outputDirFlag, temingoignoreFlag *string,
-	templateExtensionFlag, metaTemplateExtensionFlag, partialExtensionFlag *string,
-	metaFilenameFlag, markdownFilenameFlag *string,
+	templateExtensionFlag, metaTemplateExtensionFlag, partialExtensionFlag, componentExtensionFlag *string,
+	metaFilenameFlag, markdownFilenameFlag, valuesFilenameFlag, cacheDirFlag, librariesFileFlag, baseURLFlag *string,
 	valueFlags, valuesFileFlags *[]string,
-	verboseFlag, dryRunFlag, noDeleteOutputDirFlag, strictFlag *bool,
-	noRemoteChecksFlag, allowInsecureSchemeFlag *bool) {
+	verboseFlag, dryRunFlag, noDeleteOutputDirFlag, strictFlag, strictValuesFlag *bool,
+	noRemoteChecksFlag, allowInsecureSchemeFlag, sitemapFlag, noAutoIndentFlag, minifyFlag, validateFlag, flatMarkdownPagesFlag, markdownTemplatesFlag *bool) {
 	// Helper function to get string value from config
 	getString := func(key string) string {
 		if val, ok := config[key]; ok {
@@ -172,14 +255,26 @@ func applyConfigToFlags(cmd *cli.Command, config map[string]interface{},
 	applyStringFlag("templateExtension", "templateExtension", templateExtensionFlag)
 	applyStringFlag("metaTemplateExtension", "metaTemplateExtension", metaTemplateExtensionFlag)
 	applyStringFlag("partialExtension", "partialExtension", partialExtensionFlag)
+	applyStringFlag("componentExtension", "componentExtension", componentExtensionFlag)
 	applyStringFlag("metaFilename", "metaFilename", metaFilenameFlag)
 	applyStringFlag("markdownFilename", "markdownFilename", markdownFilenameFlag)
+	applyStringFlag("valuesFilename", "valuesFilename", valuesFilenameFlag)
+	applyStringFlag("cacheDir", "cacheDir", cacheDirFlag)
+	applyStringFlag("librariesFile", "librariesFile", librariesFileFlag)
+	applyStringFlag("baseURL", "baseURL", baseURLFlag)
 	applyBoolFlag("verbose", "verbose", verboseFlag)
 	applyBoolFlag("dry-run", "dryRun", dryRunFlag)
 	applyBoolFlag("noDeleteOutputDir", "noDeleteOutputDir", noDeleteOutputDirFlag)
 	applyBoolFlag("strict", "strict", strictFlag)
+	applyBoolFlag("strict-values", "strictValues", strictValuesFlag)
 	applyBoolFlag("no-remote-checks", "noRemoteChecks", noRemoteChecksFlag)
 	applyBoolFlag("allow-insecure-scheme", "allowInsecureScheme", allowInsecureSchemeFlag)
+	applyBoolFlag("sitemap", "sitemap", sitemapFlag)
+	applyBoolFlag("no-auto-indent", "noAutoIndent", noAutoIndentFlag)
+	applyBoolFlag("minify", "minify", minifyFlag)
+	applyBoolFlag("validate", "validate", validateFlag)
+	applyBoolFlag("flat-markdown-pages", "flatMarkdownPages", flatMarkdownPagesFlag)
+	applyBoolFlag("markdown-templates", "markdownTemplates", markdownTemplatesFlag)
 	applyStringSliceFlag("value", "value", valueFlags)
 	applyStringSliceFlag("valuesfile", "valuesfile", valuesFileFlags)
 }
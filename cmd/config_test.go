@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/thetillhoff/temingo/internal/refcheck"
+	"github.com/thetillhoff/temingo/pkg/markdown2html"
+	"github.com/thetillhoff/temingo/pkg/temingo"
 )
 
 func TestAllowlistFromConfig(t *testing.T) {
@@ -60,3 +62,144 @@ func TestAllowlistFromConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkdownFromConfig(t *testing.T) {
+	tests := []struct {
+		name               string
+		config             map[string]interface{}
+		expected           markdown2html.Options
+		expectedStylesheet string
+	}{
+		{
+			name:               "absent key keeps the defaults",
+			config:             map[string]interface{}{},
+			expected:           markdown2html.Options{},
+			expectedStylesheet: "highlight.css",
+		},
+		{
+			name: "extensions, hard wraps and highlighting",
+			config: map[string]interface{}{
+				"markdown": map[string]interface{}{
+					"footnotes":       true,
+					"definitionLists": true,
+					"typographer":     true,
+					"tableOfContents": true,
+					"attributes":      true,
+					"unsafe":          true,
+					"hardWraps":       false,
+					"highlight": map[string]interface{}{
+						"style":      "github",
+						"stylesheet": "css/code.css",
+					},
+				},
+			},
+			expected: markdown2html.Options{
+				Footnotes:       true,
+				DefinitionLists: true,
+				Typographer:     true,
+				TableOfContents: true,
+				Attributes:      true,
+				Unsafe:          true,
+				NoHardWraps:     true,
+				HighlightStyle:  "github",
+			},
+			expectedStylesheet: "css/code.css",
+		},
+		{
+			name: "malformed values are skipped",
+			config: map[string]interface{}{
+				"markdown": map[string]interface{}{
+					"footnotes": "yes",
+					"highlight": "github",
+				},
+			},
+			expected:           markdown2html.Options{},
+			expectedStylesheet: "highlight.css",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, gotStylesheet := markdownFromConfig(test.config)
+			if got != test.expected || gotStylesheet != test.expectedStylesheet {
+				t.Errorf("markdownFromConfig() = %+v, %q, want %+v, %q", got, gotStylesheet, test.expected, test.expectedStylesheet)
+			}
+		})
+	}
+}
+
+func TestImagesFromConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		expected temingo.ImageOptions
+	}{
+		{
+			name:     "absent key keeps the defaults",
+			config:   map[string]interface{}{},
+			expected: temingo.ImageOptions{Widths: []int{640, 1280, 1920}, Quality: 80},
+		},
+		{
+			name: "widths and quality",
+			config: map[string]interface{}{
+				"images": map[string]interface{}{
+					"widths":  []interface{}{400, 800},
+					"quality": 60,
+				},
+			},
+			expected: temingo.ImageOptions{Widths: []int{400, 800}, Quality: 60},
+		},
+		{
+			name: "malformed values are skipped",
+			config: map[string]interface{}{
+				"images": map[string]interface{}{
+					"widths":  "800w",
+					"quality": "high",
+				},
+			},
+			expected: temingo.ImageOptions{Widths: []int{640, 1280, 1920}, Quality: 80},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := imagesFromConfig(test.config)
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("imagesFromConfig() = %+v, want %+v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestTaxonomiesFromConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "absent key keeps the defaults",
+			config:   map[string]interface{}{},
+			expected: []string{"tags", "categories"},
+		},
+		{
+			name:     "listed taxonomies",
+			config:   map[string]interface{}{"taxonomies": []interface{}{"tags", "authors", 3}},
+			expected: []string{"tags", "authors"},
+		},
+		{
+			name:     "empty list turns them off",
+			config:   map[string]interface{}{"taxonomies": []interface{}{}},
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := taxonomiesFromConfig(test.config)
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("taxonomiesFromConfig() = %v, want %v", got, test.expected)
+			}
+		})
+	}
+}
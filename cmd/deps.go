@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/thetillhoff/temingo/pkg/temingo"
+	"github.com/urfave/cli/v3"
+)
+
+// depsCommand represents the deps command and its subcommands
+var depsCommand = &cli.Command{
+	Name:  "deps",
+	Usage: "Manages the partial libraries declared in the librariesFile",
+	Commands: []*cli.Command{
+		{
+			Name:  "fetch",
+			Usage: "Vendors the libraries into the cacheDir, and pins the ones the lockfile does not pin yet",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "update",
+					Usage: "re-resolves every ref, pinning each library to the commit it points to now",
+				},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				libraries, err := depsLibraries(cmd, cmd.Bool("update"))
+				if err != nil {
+					return err
+				}
+				for _, library := range libraries {
+					slog.Info("Fetched library", "name", library.Name, "ref", library.Ref, "commit", library.Commit, "partials", len(library.Partials))
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "list",
+			Usage: "Lists the libraries with the commit they are pinned to",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				libraries, err := depsLibraries(cmd, false)
+				if err != nil {
+					return err
+				}
+				for _, library := range libraries {
+					fmt.Printf("%s\t%s\t%s\t%s\n", library.Name, library.URL, library.Ref, shortCommit(library.Commit))
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "tree",
+			Usage: "Prints the partials of every library, and the input files overriding them",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				libraries, err := depsLibraries(cmd, false)
+				if err != nil {
+					return err
+				}
+				printLibraryTree(os.Stdout, libraries)
+				return nil
+			},
+		},
+	},
+}
+
+// depsLibraries builds an engine from the flags and config file, and returns
+// its libraries, vendoring them first.
+func depsLibraries(cmd *cli.Command, update bool) ([]temingo.Library, error) {
+	cfgFile := cmd.String("config")
+	inputDirFlag := cmd.String("inputDir")
+	outputDirFlag := cmd.String("outputDir")
+	temingoignoreFlag := cmd.String("temingoignore")
+	templateExtensionFlag := cmd.String("templateExtension")
+	metaTemplateExtensionFlag := cmd.String("metaTemplateExtension")
+	partialExtensionFlag := cmd.String("partialExtension")
+	componentExtensionFlag := cmd.String("componentExtension")
+	metaFilenameFlag := cmd.String("metaFilename")
+	markdownFilenameFlag := cmd.String("markdownFilename")
+	valuesFilenameFlag := cmd.String("valuesFilename")
+	cacheDirFlag := cmd.String("cacheDir")
+	librariesFileFlag := cmd.String("librariesFile")
+	baseURLFlag := cmd.String("baseURL")
+	valueFlags := cmd.StringSlice("value")
+	valuesFileFlags := cmd.StringSlice("valuesfile")
+	verboseFlag := cmd.Bool("verbose")
+	dryRunFlag := cmd.Bool("dry-run")
+	noDeleteOutputDirFlag := cmd.Bool("noDeleteOutputDir")
+	strictFlag := cmd.Bool("strict")
+	strictValuesFlag := cmd.Bool("strict-values")
+	noRemoteChecksFlag := cmd.Bool("no-remote-checks")
+	allowInsecureSchemeFlag := cmd.Bool("allow-insecure-scheme")
+	sitemapFlag := cmd.Bool("sitemap")
+	noAutoIndentFlag := cmd.Bool("no-auto-indent")
+	minifyFlag := cmd.Bool("minify")
+	validateFlag := cmd.Bool("validate")
+	flatMarkdownPagesFlag := cmd.Bool("flat-markdown-pages")
+	markdownTemplatesFlag := cmd.Bool("markdown-templates")
+
+	// Load config file if specified
+	config, err := loadConfig(cfgFile)
+	if err != nil {
+		slog.Error("Failed to load config", "error", err)
+		return nil, err
+	}
+
+	// Apply config values to flags (CLI/env flags take precedence if explicitly set)
+	applyConfigToFlags(cmd, config, &inputDirFlag, &outputDirFlag, &temingoignoreFlag,
+		&templateExtensionFlag, &metaTemplateExtensionFlag, &partialExtensionFlag, &componentExtensionFlag,
+		&metaFilenameFlag, &markdownFilenameFlag, &valuesFilenameFlag, &cacheDirFlag, &librariesFileFlag, &baseURLFlag, &valueFlags, &valuesFileFlags,
+		&verboseFlag, &dryRunFlag, &noDeleteOutputDirFlag, &strictFlag, &strictValuesFlag,
+		&noRemoteChecksFlag, &allowInsecureSchemeFlag, &sitemapFlag, &noAutoIndentFlag, &minifyFlag, &validateFlag, &flatMarkdownPagesFlag, &markdownTemplatesFlag)
+
+	if !strings.HasSuffix(inputDirFlag, "/") {
+		inputDirFlag += "/"
+	}
+
+	// Create logger based on verbose flag
+	var loggerLevel slog.Level
+	if verboseFlag {
+		loggerLevel = slog.LevelDebug
+	} else {
+		loggerLevel = slog.LevelInfo
+	}
+	loggerOpts := &slog.HandlerOptions{
+		Level: loggerLevel,
+	}
+	temingoLogger := slog.New(slog.NewTextHandler(os.Stdout, loggerOpts))
+
+	engine := temingo.Engine{
+		InputDir:           inputDirFlag,
+		PartialExtension:   partialExtensionFlag,
+		ComponentExtension: componentExtensionFlag,
+		CacheDir:           cacheDirFlag,
+		LibrariesFile:      librariesFileFlag,
+		Logger:             temingoLogger,
+	}
+
+	libraries, err := engine.Libraries(update)
+	if err != nil {
+		slog.Error("Failed to fetch libraries", "error", err)
+		return nil, fmt.Errorf("failed to fetch libraries: %w", err)
+	}
+	if len(libraries) == 0 {
+		slog.Info("No libraries declared", "file", librariesFileFlag)
+	}
+	return libraries, nil
+}
+
+// printLibraryTree writes every library with its partials to w, marking the
+// ones an input file overrides.
+func printLibraryTree(w io.Writer, libraries []temingo.Library) {
+	for _, library := range libraries {
+		fmt.Fprintf(w, "%s (%s @ %s, %s)\n", library.Name, library.Ref, shortCommit(library.Commit), library.URL)
+		for i, partial := range library.Partials {
+			branch := "├── "
+			if i == len(library.Partials)-1 {
+				branch = "└── "
+			}
+			line := branch + partial.Path
+			if partial.OverriddenBy != "" {
+				line += " (overridden by " + partial.OverriddenBy + ")"
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// shortCommit abbreviates commit for display.
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
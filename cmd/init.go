@@ -44,16 +44,28 @@ var initCommand = &cli.Command{
 		templateExtensionFlag := cmd.String("templateExtension")
 		metaTemplateExtensionFlag := cmd.String("metaTemplateExtension")
 		partialExtensionFlag := cmd.String("partialExtension")
+		componentExtensionFlag := cmd.String("componentExtension")
 		metaFilenameFlag := cmd.String("metaFilename")
 		markdownFilenameFlag := cmd.String("markdownFilename")
+		valuesFilenameFlag := cmd.String("valuesFilename")
+		cacheDirFlag := cmd.String("cacheDir")
+		librariesFileFlag := cmd.String("librariesFile")
+		baseURLFlag := cmd.String("baseURL")
 		valueFlags := cmd.StringSlice("value")
 		valuesFileFlags := cmd.StringSlice("valuesfile")
 		verboseFlag := cmd.Bool("verbose")
 		dryRunFlag := cmd.Bool("dry-run")
 		noDeleteOutputDirFlag := cmd.Bool("noDeleteOutputDir")
 		strictFlag := cmd.Bool("strict")
+		strictValuesFlag := cmd.Bool("strict-values")
 		noRemoteChecksFlag := cmd.Bool("no-remote-checks")
 		allowInsecureSchemeFlag := cmd.Bool("allow-insecure-scheme")
+		sitemapFlag := cmd.Bool("sitemap")
+		noAutoIndentFlag := cmd.Bool("no-auto-indent")
+		minifyFlag := cmd.Bool("minify")
+		validateFlag := cmd.Bool("validate")
+		flatMarkdownPagesFlag := cmd.Bool("flat-markdown-pages")
+		markdownTemplatesFlag := cmd.Bool("markdown-templates")
 
 		// Load config file if specified
 		config, err := loadConfig(cfgFile)
@@ -64,13 +76,13 @@ var initCommand = &cli.Command{
 
 		// Apply config values to flags (CLI/env flags take precedence if explicitly set)
 		applyConfigToFlags(cmd, config, &inputDirFlag, &outputDirFlag, &temingoignoreFlag,
-			&templateExtensionFlag, &metaTemplateExtensionFlag, &partialExtensionFlag,
-			&metaFilenameFlag, &markdownFilenameFlag, &valueFlags, &valuesFileFlags,
-			&verboseFlag, &dryRunFlag, &noDeleteOutputDirFlag, &strictFlag,
-			&noRemoteChecksFlag, &allowInsecureSchemeFlag)
+			&templateExtensionFlag, &metaTemplateExtensionFlag, &partialExtensionFlag, &componentExtensionFlag,
+			&metaFilenameFlag, &markdownFilenameFlag, &valuesFilenameFlag, &cacheDirFlag, &librariesFileFlag, &baseURLFlag, &valueFlags, &valuesFileFlags,
+			&verboseFlag, &dryRunFlag, &noDeleteOutputDirFlag, &strictFlag, &strictValuesFlag,
+			&noRemoteChecksFlag, &allowInsecureSchemeFlag, &sitemapFlag, &noAutoIndentFlag, &minifyFlag, &validateFlag, &flatMarkdownPagesFlag, &markdownTemplatesFlag)
 
 		var (
-			values = map[string]string{}
+			values = map[string]interface{}{}
 		)
 
 		if !strings.HasSuffix(inputDirFlag, "/") {
@@ -100,7 +112,10 @@ var initCommand = &cli.Command{
 				slog.Error("No value set for value keypair", "value", value)
 				return fmt.Errorf("no value set for value keypair: %s", value)
 			case 2:
-				values[splitString[0]] = splitString[1]
+				if err = setValue(values, splitString[0], splitString[1]); err != nil { // Dotted keys set nested entries
+					slog.Error("Invalid value key", "value", value)
+					return err
+				}
 			default:
 				slog.Error("Invalid value flag", "value", value)
 				return fmt.Errorf("invalid value flag: %s", value)
@@ -126,14 +141,18 @@ var initCommand = &cli.Command{
 			TemplateExtension:       templateExtensionFlag,
 			MetaTemplateExtension:   metaTemplateExtensionFlag,
 			PartialExtension:        partialExtensionFlag,
+			ComponentExtension:      componentExtensionFlag,
 			MetaFilename:            metaFilenameFlag,
 			MarkdownContentFilename: markdownFilenameFlag,
+			ValuesFilename:          valuesFilenameFlag,
 			Values:                  values,
 			ValuesFilePaths:         valuesFileFlags,
 			NoDeleteOutputDir:       noDeleteOutputDirFlag,
 			Verbose:                 verboseFlag,
 			DryRun:                  dryRunFlag,
 			Logger:                  temingoLogger,
+			CacheDir:                cacheDirFlag,
+			LibrariesFile:           librariesFileFlag,
 		}
 
 		// Get current directory to pass as targetDir
@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/thetillhoff/temingo/pkg/mergeYaml"
 	"gopkg.in/yaml.v3"
 )
 
-// parseValuesFromFile reads a YAML file and returns a map of string key-value pairs
-// for use in template rendering
-func parseValuesFromFile(filePath string) (map[string]string, error) {
-	var yamlData map[string]interface{}
+// parseValuesFromFile reads a YAML file and returns its top-level keys with
+// their values for use in template rendering. Values keep their structure, so
+// maps, lists, numbers and booleans are available as such in the templates.
+func parseValuesFromFile(filePath string) (map[interface{}]interface{}, error) {
+	yamlData := map[interface{}]interface{}{}
 
 	// Read the file
 	data, err := os.ReadFile(filePath)
@@ -24,24 +26,13 @@ func parseValuesFromFile(filePath string) (map[string]string, error) {
 		return nil, err
 	}
 
-	// Convert to map[string]string
-	result := make(map[string]string)
-	for key, value := range yamlData {
-		if strValue, ok := value.(string); ok {
-			result[key] = strValue
-		} else {
-			// Convert non-string values to string representation
-			result[key] = fmt.Sprintf("%v", value)
-		}
-	}
-
-	return result, nil
+	return yamlData, nil
 }
 
 // parseValuesFromFiles reads multiple YAML files and merges them into a single map
-// Files are merged in order, with later files overriding earlier ones
-func parseValuesFromFiles(filePaths []string) (map[string]string, error) {
-	mergedValues := make(map[string]string)
+// Files are merged in order, with later files overriding the top-level keys of earlier ones
+func parseValuesFromFiles(filePaths []string) (map[string]interface{}, error) {
+	var mergedValues interface{} = map[interface{}]interface{}{}
 
 	for _, filePath := range filePaths {
 		fileValues, err := parseValuesFromFile(filePath)
@@ -50,10 +41,13 @@ func parseValuesFromFiles(filePaths []string) (map[string]string, error) {
 		}
 
 		// Merge with existing values (later files override earlier ones)
-		for key, value := range fileValues {
-			mergedValues[key] = value
-		}
+		mergedValues = mergeYaml.Merge(fileValues, mergedValues, true)
+	}
+
+	result := map[string]interface{}{}
+	for key, value := range mergedValues.(map[interface{}]interface{}) {
+		result[fmt.Sprint(key)] = value
 	}
 
-	return mergedValues, nil
+	return result, nil
 }
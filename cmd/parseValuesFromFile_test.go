@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseValuesFromFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"base.yaml": "siteName: My Blog\n" +
+			"postsPerPage: 10\n" +
+			"draft: false\n" +
+			"nav:\n  - title: Home\n    url: /\n  - title: Blog\n    url: /blog/\n" +
+			"social:\n  github: base\n  mastodon: base\n",
+		"production.yaml": "draft: true\n" +
+			"social:\n  github: production\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := parseValuesFromFiles([]string{filepath.Join(tmpDir, "base.yaml"), filepath.Join(tmpDir, "production.yaml")})
+	if err != nil {
+		t.Fatalf("parseValuesFromFiles() unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"siteName":     "My Blog",
+		"postsPerPage": 10,
+		"draft":        true,
+		"nav": []interface{}{
+			map[string]interface{}{"title": "Home", "url": "/"},
+			map[string]interface{}{"title": "Blog", "url": "/blog/"},
+		},
+		"social": map[string]interface{}{"github": "production"}, // Later files override whole top-level keys
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseValuesFromFiles() = %#v, want %#v", got, expected)
+	}
+
+	if _, err = parseValuesFromFiles([]string{filepath.Join(tmpDir, "missing.yaml")}); err == nil {
+		t.Errorf("parseValuesFromFiles() with a missing file should fail")
+	}
+}
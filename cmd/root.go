@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/thetillhoff/fileIO"
+	"github.com/thetillhoff/temingo/internal/livereload"
 	"github.com/thetillhoff/temingo/pkg/temingo"
 	"github.com/urfave/cli/v3"
 )
@@ -79,6 +80,12 @@ func Execute() {
 				Value:   ".partial",
 				Sources: cli.EnvVars("TEMINGO_PARTIAL_EXT"),
 			},
+			&cli.StringFlag{
+				Name:    "componentExtension",
+				Usage:   "componentExtension marks a file as component, a partial template called with typed arguments",
+				Value:   ".component",
+				Sources: cli.EnvVars("TEMINGO_COMPONENT_EXT"),
+			},
 			&cli.StringFlag{
 				Name:    "metaFilename",
 				Usage:   "the yaml files for the metadata",
@@ -91,6 +98,29 @@ func Execute() {
 				Value:   "content.md",
 				Sources: cli.EnvVars("TEMINGO_MARKDOWN_FILENAME"),
 			},
+			&cli.StringFlag{
+				Name:    "valuesFilename",
+				Usage:   "the yaml files for values, discovered in every folder from the inputDir down to a template (empty disables discovery)",
+				Value:   "values.yaml",
+				Sources: cli.EnvVars("TEMINGO_VALUES_FILENAME"),
+			},
+			&cli.StringFlag{
+				Name:    "cacheDir",
+				Usage:   "cacheDir keeps state between builds, like the hashes of the written outputs, relative to the folder holding the inputDir (empty disables it)",
+				Value:   ".temingo-cache/",
+				Sources: cli.EnvVars("TEMINGO_CACHE_DIR"),
+			},
+			&cli.StringFlag{
+				Name:    "librariesFile",
+				Usage:   "the yaml file declaring partial libraries to vendor from git repositories, pinned by the .lock file next to it",
+				Value:   "component.yaml",
+				Sources: cli.EnvVars("TEMINGO_LIBRARIES_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "baseURL",
+				Usage:   "the absolute URL the outputDir is served at, like https://example.com/ (required for the sitemap)",
+				Sources: cli.EnvVars("TEMINGO_BASE_URL"),
+			},
 			&cli.StringSliceFlag{
 				Name:    "value",
 				Usage:   "value for the templates (`key=value`), multiple occurrences are possible",
@@ -122,6 +152,11 @@ func Execute() {
 				Usage:   "exit non-zero if any reference finding is reported",
 				Sources: cli.EnvVars("TEMINGO_STRICT"),
 			},
+			&cli.BoolFlag{
+				Name:    "strict-values",
+				Usage:   "exit non-zero if a value or meta key is never read by any template",
+				Sources: cli.EnvVars("TEMINGO_STRICT_VALUES"),
+			},
 			&cli.BoolFlag{
 				Name:  "no-remote-checks",
 				Usage: "skip reference checks that need a network request; static and internal checks still run",
@@ -130,6 +165,36 @@ func Execute() {
 				Name:  "allow-insecure-scheme",
 				Usage: "don't report references fetched over plain http",
 			},
+			&cli.BoolFlag{
+				Name:    "no-auto-indent",
+				Usage:   "don't indent the lines of a {{ template }} output to match the line it is called on",
+				Sources: cli.EnvVars("TEMINGO_NO_AUTO_INDENT"),
+			},
+			&cli.BoolFlag{
+				Name:    "validate",
+				Usage:   "report html, css, json, xml, svg and yaml output with syntax or structure errors",
+				Sources: cli.EnvVars("TEMINGO_VALIDATE"),
+			},
+			&cli.BoolFlag{
+				Name:    "minify",
+				Usage:   "minify html, css, js, svg, json and xml output, including static files, instead of beautifying html",
+				Sources: cli.EnvVars("TEMINGO_MINIFY"),
+			},
+			&cli.BoolFlag{
+				Name:    "sitemap",
+				Usage:   "generate a sitemap.xml of every html page under the baseURL",
+				Sources: cli.EnvVars("TEMINGO_SITEMAP"),
+			},
+			&cli.BoolFlag{
+				Name:    "flat-markdown-pages",
+				Usage:   "render a markdown page like docs/intro.md to docs/intro.html instead of docs/intro/index.html",
+				Sources: cli.EnvVars("TEMINGO_FLAT_MARKDOWN_PAGES"),
+			},
+			&cli.BoolFlag{
+				Name:    "markdown-templates",
+				Usage:   "execute markdown content and pages as templates, with the meta object of their page, before converting them",
+				Sources: cli.EnvVars("TEMINGO_MARKDOWN_TEMPLATES"),
+			},
 			&cli.BoolFlag{
 				Name:    "watch",
 				Aliases: []string{"w"},
@@ -145,6 +210,7 @@ func Execute() {
 		},
 		Commands: []*cli.Command{
 			initCommand,
+			depsCommand,
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			cfgFile := cmd.String("config")
@@ -154,16 +220,28 @@ func Execute() {
 			templateExtensionFlag := cmd.String("templateExtension")
 			metaTemplateExtensionFlag := cmd.String("metaTemplateExtension")
 			partialExtensionFlag := cmd.String("partialExtension")
+			componentExtensionFlag := cmd.String("componentExtension")
 			metaFilenameFlag := cmd.String("metaFilename")
 			markdownFilenameFlag := cmd.String("markdownFilename")
+			valuesFilenameFlag := cmd.String("valuesFilename")
+			cacheDirFlag := cmd.String("cacheDir")
+			librariesFileFlag := cmd.String("librariesFile")
+			baseURLFlag := cmd.String("baseURL")
 			valueFlags := cmd.StringSlice("value")
 			valuesFileFlags := cmd.StringSlice("valuesfile")
 			verboseFlag := cmd.Bool("verbose")
 			dryRunFlag := cmd.Bool("dry-run")
 			noDeleteOutputDirFlag := cmd.Bool("noDeleteOutputDir")
 			strictFlag := cmd.Bool("strict")
+			strictValuesFlag := cmd.Bool("strict-values")
 			noRemoteChecksFlag := cmd.Bool("no-remote-checks")
 			allowInsecureSchemeFlag := cmd.Bool("allow-insecure-scheme")
+			sitemapFlag := cmd.Bool("sitemap")
+			noAutoIndentFlag := cmd.Bool("no-auto-indent")
+			minifyFlag := cmd.Bool("minify")
+			validateFlag := cmd.Bool("validate")
+			flatMarkdownPagesFlag := cmd.Bool("flat-markdown-pages")
+			markdownTemplatesFlag := cmd.Bool("markdown-templates")
 			watchFlag := cmd.Bool("watch")
 			serveFlag := cmd.Bool("serve")
 
@@ -176,13 +254,13 @@ func Execute() {
 
 			// Apply config values to flags (CLI/env flags take precedence if explicitly set)
 			applyConfigToFlags(cmd, config, &inputDirFlag, &outputDirFlag, &temingoignoreFlag,
-				&templateExtensionFlag, &metaTemplateExtensionFlag, &partialExtensionFlag,
-				&metaFilenameFlag, &markdownFilenameFlag, &valueFlags, &valuesFileFlags,
-				&verboseFlag, &dryRunFlag, &noDeleteOutputDirFlag, &strictFlag,
-				&noRemoteChecksFlag, &allowInsecureSchemeFlag)
+				&templateExtensionFlag, &metaTemplateExtensionFlag, &partialExtensionFlag, &componentExtensionFlag,
+				&metaFilenameFlag, &markdownFilenameFlag, &valuesFilenameFlag, &cacheDirFlag, &librariesFileFlag, &baseURLFlag, &valueFlags, &valuesFileFlags,
+				&verboseFlag, &dryRunFlag, &noDeleteOutputDirFlag, &strictFlag, &strictValuesFlag,
+				&noRemoteChecksFlag, &allowInsecureSchemeFlag, &sitemapFlag, &noAutoIndentFlag, &minifyFlag, &validateFlag, &flatMarkdownPagesFlag, &markdownTemplatesFlag)
 
 			var (
-				values = map[string]string{}
+				values = map[string]interface{}{}
 			)
 
 			if !strings.HasSuffix(inputDirFlag, "/") {
@@ -212,7 +290,10 @@ func Execute() {
 					slog.Error("No value set for value keypair", "value", value)
 					return fmt.Errorf("no value set for value keypair: %s", value)
 				case 2:
-					values[splitString[0]] = splitString[1]
+					if err = setValue(values, splitString[0], splitString[1]); err != nil { // Dotted keys set nested entries
+						slog.Error("Invalid value key", "value", value)
+						return err
+					}
 				default:
 					slog.Error("Invalid value flag", "value", value)
 					return fmt.Errorf("invalid value flag: %s", value)
@@ -231,6 +312,8 @@ func Execute() {
 			}
 			temingoLogger := slog.New(slog.NewTextHandler(os.Stdout, loggerOpts))
 
+			markdownOptions, highlightStylesheet := markdownFromConfig(config)
+
 			temingoEngine := temingo.Engine{
 				InputDir:                inputDirFlag,
 				OutputDir:               outputDirFlag,
@@ -238,20 +321,35 @@ func Execute() {
 				TemplateExtension:       templateExtensionFlag,
 				MetaTemplateExtension:   metaTemplateExtensionFlag,
 				PartialExtension:        partialExtensionFlag,
+				ComponentExtension:      componentExtensionFlag,
 				MetaFilename:            metaFilenameFlag,
 				MarkdownContentFilename: markdownFilenameFlag,
+				ValuesFilename:          valuesFilenameFlag,
 				Values:                  values,
 				ValuesFilePaths:         valuesFileFlags,
 				NoDeleteOutputDir:       noDeleteOutputDirFlag,
 				Verbose:                 verboseFlag,
 				DryRun:                  dryRunFlag,
-				Beautify:                true,
-				Minify:                  false,
+				Beautify:                !minifyFlag,
+				Minify:                  minifyFlag,
 				Strict:                  strictFlag,
+				StrictValues:            strictValuesFlag,
 				Allow:                   allowlistFromConfig(config),
 				NoRemoteChecks:          noRemoteChecksFlag,
 				AllowInsecureScheme:     allowInsecureSchemeFlag,
 				Logger:                  temingoLogger,
+				CacheDir:                cacheDirFlag,
+				LibrariesFile:           librariesFileFlag,
+				BaseURL:                 baseURLFlag,
+				Sitemap:                 sitemapFlag,
+				Validate:                validateFlag,
+				NoAutoIndent:            noAutoIndentFlag,
+				Markdown:                markdownOptions,
+				HighlightStylesheet:     highlightStylesheet,
+				FlatMarkdownPages:       flatMarkdownPagesFlag,
+				MarkdownTemplates:       markdownTemplatesFlag,
+				Images:                  imagesFromConfig(config),
+				Taxonomies:              taxonomiesFromConfig(config),
 			}
 
 			// Build once
@@ -262,10 +360,14 @@ func Execute() {
 			}
 			slog.Info("Build complete")
 
+			// The live reload server is only set when serving
+			var reloader *livereload.Server
+
 			if serveFlag { // Start webserver if desired
 				addr := "127.0.0.1:3000"
+				reloader = livereload.New(outputDirFlag) // Serves the outputDir, with a reload client injected into html pages
 				mux := http.NewServeMux()
-				mux.Handle("/", http.FileServer(http.Dir(outputDirFlag)))
+				mux.Handle("/", reloader)
 				go func() {
 					slog.Info("Listening", "addr", "http://"+addr)
 					if err := http.ListenAndServe(addr, mux); err != nil {
@@ -291,9 +393,14 @@ func Execute() {
 					100*time.Millisecond,
 					func(path string) error {
 						slog.Info("Rebuild triggered by file change", "path", path)
-						err = temingoEngine.Render()
+						err = temingoEngine.RenderChanged(path) // Only rebuilds the outputs that depend on the changed file
 						if err != nil {
 							slog.Error("Rebuild failed", "error", err) // Print errors when in watch mode
+							if reloader != nil {
+								reloader.BuildFailed(err) // Shown as an overlay in the browser
+							}
+						} else if reloader != nil {
+							reloader.Reload(temingoEngine.ChangedOutputs())
 						}
 						return nil // Ignore errors on Rendering when in watch mode (apart from printing them)
 					})
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// setValue sets the value for a `--value` key in values. A dotted key like
+// `social.github` sets a nested entry, creating the maps on the way or
+// replacing whatever non-map value was in their place.
+func setValue(values map[string]interface{}, key string, value string) error {
+	keys := strings.Split(key, ".")
+	for _, k := range keys {
+		if k == "" {
+			return fmt.Errorf("invalid value key: %s", key)
+		}
+	}
+
+	current := values
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := current[k].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			current[k] = next
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		key      string
+		value    string
+		expected map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "plain key",
+			values:   map[string]interface{}{},
+			key:      "siteName",
+			value:    "My Blog",
+			expected: map[string]interface{}{"siteName": "My Blog"},
+		},
+		{
+			name:     "dotted key creates nested maps",
+			values:   map[string]interface{}{},
+			key:      "social.github",
+			value:    "foo",
+			expected: map[string]interface{}{"social": map[string]interface{}{"github": "foo"}},
+		},
+		{
+			name:   "dotted key keeps sibling entries from values files",
+			values: map[string]interface{}{"social": map[string]interface{}{"github": "old", "mastodon": "bar"}},
+			key:    "social.github",
+			value:  "foo",
+			expected: map[string]interface{}{
+				"social": map[string]interface{}{"github": "foo", "mastodon": "bar"},
+			},
+		},
+		{
+			name:     "dotted key replaces a non-map value",
+			values:   map[string]interface{}{"social": "none"},
+			key:      "social.github",
+			value:    "foo",
+			expected: map[string]interface{}{"social": map[string]interface{}{"github": "foo"}},
+		},
+		{
+			name:     "empty key segment",
+			values:   map[string]interface{}{},
+			key:      "social..github",
+			value:    "foo",
+			expected: map[string]interface{}{},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setValue(tt.values, tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.values, tt.expected) {
+				t.Errorf("setValue() = %v, want %v", tt.values, tt.expected)
+			}
+		})
+	}
+}
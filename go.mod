@@ -3,15 +3,19 @@ module github.com/thetillhoff/temingo
 go 1.27.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/alecthomas/chroma/v2 v2.20.0
 	github.com/thetillhoff/fileIO v1.1.0
 	github.com/urfave/cli/v3 v3.11.0
 	github.com/yuin/goldmark v1.8.5
+	golang.org/x/image v0.25.0
 	golang.org/x/net v0.58.0
 	golang.org/x/text v0.41.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/dlclark/regexp2 v1.11.5 // indirect
 	github.com/fsnotify/fsnotify v1.10.1 // indirect
 	github.com/sabhiram/go-gitignore v0.0.0-20210923224102-525f6e181f06 // indirect
 	golang.org/x/sys v0.47.0 // indirect
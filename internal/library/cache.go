@@ -0,0 +1,189 @@
+package library
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Cache keeps a bare mirror of every library repository and an extracted copy
+// of every commit a build used, under Dir. Extracted commits never change, so
+// a build whose libraries are all pinned and extracted runs no git command.
+type Cache struct {
+	Dir string
+}
+
+// Resolve fetches the repository of library and returns the commit its ref
+// points to now.
+func (cache Cache) Resolve(library Library) (string, error) {
+	mirror, err := cache.mirror(library)
+	if err != nil {
+		return "", err
+	}
+	if err = fetch(mirror); err != nil {
+		return "", fmt.Errorf("fetching library %s from %s: %w", library.Name, library.URL, err)
+	}
+
+	commit, err := git(mirror, "rev-parse", "--verify", "--quiet", library.Ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("library %s: %s has no tag, branch or commit %q", library.Name, library.URL, library.Ref)
+	}
+	return commit, nil
+}
+
+// Checkout returns the directory holding the files of library at commit,
+// restricted to the library's path, and extracts them first if needed.
+func (cache Cache) Checkout(library Library, commit string) (string, error) {
+	dir := filepath.Join(cache.Dir, "checkouts", repositoryKey(library.URL+"#"+library.Path), commit)
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	mirror, err := cache.mirror(library)
+	if err != nil {
+		return "", err
+	}
+	if _, err = git(mirror, "cat-file", "-e", commit+"^{commit}"); err != nil { // Pinned, but fetched into another cache
+		if err = fetch(mirror); err != nil {
+			return "", fmt.Errorf("fetching library %s from %s: %w", library.Name, library.URL, err)
+		}
+		if _, err = git(mirror, "cat-file", "-e", commit+"^{commit}"); err != nil {
+			return "", fmt.Errorf("library %s: %s has no commit %s; update the lockfile if the history was rewritten", library.Name, library.URL, commit)
+		}
+	}
+
+	treeish := commit
+	if library.Path != "" {
+		treeish += ":" + library.Path
+	}
+	archive, err := gitOutput(mirror, "archive", "--format=zip", treeish)
+	if err != nil {
+		return "", fmt.Errorf("library %s: reading %s at %s: %w", library.Name, library.Path, commit, err)
+	}
+
+	// Extracted next to the final directory and renamed, so an interrupted
+	// extraction never leaves a directory that looks complete
+	if err = os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".extract-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	if err = extract(archive, tmp); err != nil {
+		return "", fmt.Errorf("library %s: extracting %s: %w", library.Name, commit, err)
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		if _, statErr := os.Stat(dir); statErr != nil { // Unless another build extracted it meanwhile
+			return "", err
+		}
+	}
+
+	return dir, nil
+}
+
+// mirror returns the bare mirror of the repository of library, cloning it
+// first if there is none yet.
+func (cache Cache) mirror(library Library) (string, error) {
+	mirror := filepath.Join(cache.Dir, "repositories", repositoryKey(library.URL)+".git")
+	if _, err := os.Stat(mirror); err == nil {
+		return mirror, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(mirror), 0755); err != nil {
+		return "", err
+	}
+	if _, err := git("", "clone", "--bare", "--quiet", "--", library.URL, mirror); err != nil {
+		os.RemoveAll(mirror)
+		return "", fmt.Errorf("cloning library %s from %s: %w", library.Name, library.URL, err)
+	}
+	return mirror, nil
+}
+
+// fetch updates every branch and tag of mirror from its origin, including
+// tags that were moved.
+func fetch(mirror string) error {
+	_, err := git(mirror, "fetch", "--quiet", "--force", "--prune", "origin", "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*")
+	return err
+}
+
+// repositoryKey returns the name the mirror of the repository at url is kept
+// under.
+func repositoryKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:8])
+}
+
+// git runs git with args in dir, or the working directory if dir is empty, and
+// returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	output, err := gitOutput(dir, args...)
+	return strings.TrimSpace(string(output)), err
+}
+
+// gitOutput runs git with args in dir, or the working directory if dir is
+// empty, and returns its output. It never prompts for credentials, since a
+// build has nobody to answer.
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	command := exec.Command("git", args...)
+	command.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, errors.New(message)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// extract writes the regular files of the zip archive into dir.
+func extract(archive []byte, dir string) error {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range reader.File {
+		if !file.Mode().IsRegular() {
+			continue // Directories are created along with their files, and links could point outside dir
+		}
+		name := filepath.FromSlash(file.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q is outside the repository", file.Name)
+		}
+		target := filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		source, err := file.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(source)
+		source.Close()
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package library
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRepository creates a git repository holding files, committed and tagged
+// v1, and returns its path.
+func newRepository(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet")
+	commitFiles(t, dir, files, "v1")
+	return dir
+}
+
+// commitFiles writes files into the repository at dir, commits them and tags
+// the commit, moving the tag if it exists.
+func commitFiles(t *testing.T, dir string, files map[string]string, tag string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, dir, "add", "--all")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message", tag)
+	runGit(t, dir, "tag", "--force", tag)
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := git(dir, args...)
+	if err != nil {
+		t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return output
+}
+
+func TestCache_ResolveAndCheckout(t *testing.T) {
+	repository := newRepository(t, map[string]string{
+		"partials/header.partial.html": "<header>v1</header>",
+		"README.md":                    "not a partial",
+	})
+	cache := Cache{Dir: t.TempDir()}
+	lib := Library{Name: "shared", URL: "file://" + repository, Ref: "v1", Path: "partials"}
+
+	commit, err := cache.Resolve(lib)
+	if err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	if want := runGit(t, repository, "rev-parse", "v1"); commit != want {
+		t.Errorf("Resolve() = %s, want %s", commit, want)
+	}
+
+	dir, err := cache.Checkout(lib, commit)
+	if err != nil {
+		t.Fatalf("Checkout() unexpected error: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "header.partial.html")); err != nil || string(content) != "<header>v1</header>" {
+		t.Errorf("Checkout() header.partial.html = %q, %v, want the file of v1", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); err == nil {
+		t.Error("Checkout() extracted README.md, which is outside the library path")
+	}
+
+	// A moved tag resolves to the new commit, while the pinned one stays extracted
+	commitFiles(t, repository, map[string]string{"partials/header.partial.html": "<header>v2</header>"}, "v1")
+	moved, err := cache.Resolve(lib)
+	if err != nil {
+		t.Fatalf("Resolve() after moving the tag: %v", err)
+	}
+	if moved == commit {
+		t.Error("Resolve() after moving the tag returned the old commit")
+	}
+	if again, err := cache.Checkout(lib, commit); err != nil || again != dir {
+		t.Errorf("Checkout() of the pinned commit = %q, %v, want %q", again, err, dir)
+	}
+}
+
+func TestCache_CheckoutFetchesIntoAFreshCache(t *testing.T) {
+	repository := newRepository(t, map[string]string{"a.partial.html": "a"})
+	lib := Library{Name: "shared", URL: repository, Ref: "v1"}
+	commit := runGit(t, repository, "rev-parse", "v1")
+
+	dir, err := Cache{Dir: t.TempDir()}.Checkout(lib, commit)
+	if err != nil {
+		t.Fatalf("Checkout() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.partial.html")); err != nil {
+		t.Errorf("Checkout() did not extract a.partial.html: %v", err)
+	}
+}
+
+func TestCache_Errors(t *testing.T) {
+	repository := newRepository(t, map[string]string{"a.partial.html": "a"})
+	cache := Cache{Dir: t.TempDir()}
+
+	if _, err := cache.Resolve(Library{Name: "shared", URL: repository, Ref: "v9"}); err == nil || !strings.Contains(err.Error(), `has no tag, branch or commit "v9"`) {
+		t.Errorf("Resolve() of an unknown ref = %v, want an error naming it", err)
+	}
+	if _, err := cache.Resolve(Library{Name: "gone", URL: filepath.Join(repository, "missing"), Ref: "v1"}); err == nil || !strings.Contains(err.Error(), "cloning library gone") {
+		t.Errorf("Resolve() of a missing repository = %v, want a clone error", err)
+	}
+	if _, err := cache.Checkout(Library{Name: "shared", URL: repository, Ref: "v1"}, strings.Repeat("0", 40)); err == nil || !strings.Contains(err.Error(), "has no commit") {
+		t.Errorf("Checkout() of an unknown commit = %v, want an error naming it", err)
+	}
+}
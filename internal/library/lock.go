@@ -0,0 +1,74 @@
+package library
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lockHeader is written at the top of every lockfile.
+const lockHeader = "# The commit each library in the manifest is pinned to. Written by temingo; commit it, but do not edit it.\n"
+
+// Locked is the commit a library was pinned to.
+type Locked struct {
+	Name   string `yaml:"name"`
+	URL    string `yaml:"url"`
+	Ref    string `yaml:"ref"`
+	Commit string `yaml:"commit"`
+}
+
+// Lock is the lockfile, pinning every library of the manifest to the commit
+// its ref resolved to, so later builds use the same commit even if the ref
+// moves.
+type Lock struct {
+	Libraries []Locked `yaml:"libraries"`
+}
+
+// ReadLock reads the lockfile at filePath. A missing lockfile pins nothing.
+func ReadLock(filePath string) (Lock, error) {
+	var lock Lock
+
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	} else if err != nil {
+		return lock, err
+	}
+
+	if err = yaml.NewDecoder(bytes.NewReader(content)).Decode(&lock); err != nil && !errors.Is(err, io.EOF) {
+		return lock, fmt.Errorf("parsing %s: %w", filePath, err)
+	}
+	return lock, nil
+}
+
+// Commit returns the commit library is pinned to, if it is pinned with its
+// current url and ref. A library whose url or ref changed is not pinned.
+func (lock Lock) Commit(library Library) (string, bool) {
+	for _, locked := range lock.Libraries {
+		if locked.Name == library.Name && locked.URL == library.URL && locked.Ref == library.Ref && locked.Commit != "" {
+			return locked.Commit, true
+		}
+	}
+	return "", false
+}
+
+// Write writes the lockfile to filePath.
+func (lock Lock) Write(filePath string) error {
+	var buf bytes.Buffer
+	buf.WriteString(lockHeader)
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(lock); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, buf.Bytes(), 0644)
+}
@@ -0,0 +1,84 @@
+// Package library vendors partial libraries from git repositories: it reads the
+// manifest declaring them, pins each to a commit in a lockfile, and extracts
+// that commit into a cache, so a build reads them like local files.
+package library
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Library is a partial library declared in the manifest.
+type Library struct {
+	// Name is the namespace its partials are available under, as in
+	// {{ template "shared/header.partial.html" }}.
+	Name string `yaml:"name"`
+	// URL is anything git clones from: a remote, a file:// URL or the path of
+	// a local repository.
+	URL string `yaml:"url"`
+	// Ref is the tag, branch or commit to use.
+	Ref string `yaml:"ref"`
+	// Path is the directory in the repository that holds the partials, or ""
+	// for its root.
+	Path string `yaml:"path,omitempty"`
+}
+
+// Manifest is the file declaring the libraries of a project.
+type Manifest struct {
+	Libraries []Library `yaml:"libraries"`
+}
+
+// ReadManifest reads and checks the manifest at filePath. A missing manifest
+// declares no libraries.
+func ReadManifest(filePath string) (Manifest, error) {
+	var manifest Manifest
+
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	} else if err != nil {
+		return manifest, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&manifest); err != nil && !errors.Is(err, io.EOF) {
+		return manifest, fmt.Errorf("parsing %s: %w", filePath, err)
+	}
+
+	seen := map[string]bool{}
+	for i, library := range manifest.Libraries {
+		switch {
+		case library.Name == "":
+			return manifest, fmt.Errorf("%s: library %d has no name", filePath, i+1)
+		case strings.ContainsAny(library.Name, "/\\") || library.Name == "." || library.Name == "..":
+			return manifest, fmt.Errorf("%s: library name %q has to be a single folder name", filePath, library.Name)
+		case seen[library.Name]:
+			return manifest, fmt.Errorf("%s: library name %q is declared twice", filePath, library.Name)
+		case library.URL == "":
+			return manifest, fmt.Errorf("%s: library %q has no url", filePath, library.Name)
+		case library.Ref == "":
+			return manifest, fmt.Errorf("%s: library %q has no ref; pin it to a tag or commit", filePath, library.Name)
+		}
+		if library.Path != "" {
+			cleaned := path.Clean(library.Path)
+			if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+				return manifest, fmt.Errorf("%s: path %q of library %q has to be inside the repository", filePath, library.Path, library.Name)
+			}
+			if cleaned == "." {
+				cleaned = ""
+			}
+			manifest.Libraries[i].Path = cleaned
+		}
+		seen[library.Name] = true
+	}
+
+	return manifest, nil
+}
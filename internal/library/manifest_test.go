@@ -0,0 +1,79 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		want      []Library
+		wantError string
+	}{
+		{
+			name:    "libraries",
+			content: "libraries:\n  - name: shared\n    url: https://example.com/shared.git\n    ref: v1.2.0\n    path: ./partials/\n",
+			want:    []Library{{Name: "shared", URL: "https://example.com/shared.git", Ref: "v1.2.0", Path: "partials"}},
+		},
+		{name: "empty", content: ""},
+		{name: "unknown key", content: "libraries:\n  - name: a\n    url: u\n    tag: v1\n", wantError: "field tag not found"},
+		{name: "no ref", content: "libraries:\n  - name: a\n    url: u\n", wantError: `library "a" has no ref`},
+		{name: "nested name", content: "libraries:\n  - name: a/b\n    url: u\n    ref: v1\n", wantError: "has to be a single folder name"},
+		{name: "duplicate name", content: "libraries:\n  - {name: a, url: u, ref: v1}\n  - {name: a, url: v, ref: v1}\n", wantError: "declared twice"},
+		{name: "path outside", content: "libraries:\n  - {name: a, url: u, ref: v1, path: ../x}\n", wantError: "has to be inside the repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "component.yaml")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			manifest, err := ReadManifest(filePath)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("ReadManifest() error = %v, want it to contain %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadManifest() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(manifest.Libraries, tt.want) {
+				t.Errorf("ReadManifest() = %+v, want %+v", manifest.Libraries, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		manifest, err := ReadManifest(filepath.Join(t.TempDir(), "component.yaml"))
+		if err != nil || len(manifest.Libraries) != 0 {
+			t.Errorf("ReadManifest() = %+v, %v, want no libraries", manifest, err)
+		}
+	})
+}
+
+func TestLock_RoundTrip(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "component.lock")
+	lock := Lock{Libraries: []Locked{{Name: "shared", URL: "u", Ref: "v1", Commit: "abc"}}}
+	if err := lock.Write(filePath); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	read, err := ReadLock(filePath)
+	if err != nil {
+		t.Fatalf("ReadLock() unexpected error: %v", err)
+	}
+	if commit, ok := read.Commit(Library{Name: "shared", URL: "u", Ref: "v1"}); !ok || commit != "abc" {
+		t.Errorf("Commit() = %q, %v, want abc", commit, ok)
+	}
+	if _, ok := read.Commit(Library{Name: "shared", URL: "u", Ref: "v2"}); ok {
+		t.Error("Commit() pinned a library whose ref changed")
+	}
+}
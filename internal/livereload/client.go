@@ -0,0 +1,54 @@
+package livereload
+
+// clientScript subscribes a page to reload events. "reload" reloads the page,
+// "css" swaps its stylesheets without a reload, and "build-error" shows the build
+// error as an overlay. A new stylesheet is only swapped in once it loaded, so
+// the page never flashes unstyled.
+const clientScript = `(function () {
+  var overlayId = "temingo-error-overlay";
+
+  function hideError() {
+    var overlay = document.getElementById(overlayId);
+    if (overlay) {
+      overlay.remove();
+    }
+  }
+
+  function showError(message) {
+    hideError();
+    var overlay = document.createElement("div");
+    overlay.id = overlayId;
+    overlay.style.cssText = "position:fixed;inset:0;z-index:2147483647;overflow:auto;padding:2rem;" +
+      "background:rgba(20,20,20,0.92);color:#ff6b6b;font:14px/1.5 monospace;";
+    var title = document.createElement("strong");
+    title.textContent = "temingo: build failed";
+    title.style.cssText = "display:block;margin-bottom:1rem;color:#fff;font-size:16px;";
+    var pre = document.createElement("pre");
+    pre.textContent = message;
+    pre.style.cssText = "white-space:pre-wrap;margin:0;";
+    overlay.appendChild(title);
+    overlay.appendChild(pre);
+    document.body.appendChild(overlay);
+  }
+
+  function swapStylesheets() {
+    var links = document.querySelectorAll('link[rel~="stylesheet"]');
+    links.forEach(function (link) {
+      var url = new URL(link.href, location.href);
+      if (url.origin !== location.origin) {
+        return;
+      }
+      url.searchParams.set("temingo-reload", Date.now());
+      var next = link.cloneNode();
+      next.href = url.href;
+      next.onload = function () { link.remove(); };
+      link.after(next);
+    });
+  }
+
+  var events = new EventSource("` + EventsPath + `");
+  events.addEventListener("reload", function () { location.reload(); });
+  events.addEventListener("css", function () { hideError(); swapStylesheets(); });
+  events.addEventListener("build-error", function (e) { showError(e.data); });
+})();
+`
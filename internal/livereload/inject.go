@@ -0,0 +1,20 @@
+package livereload
+
+import "bytes"
+
+// clientTag loads the live reload client.
+const clientTag = `<script src="` + ClientPath + `"></script>`
+
+// injectClient adds the client script to an HTML page, right before the last
+// closing body tag, or at the end if there is none.
+func injectClient(content []byte) []byte {
+	i := bytes.LastIndex(bytes.ToLower(content), []byte("</body>"))
+	if i < 0 {
+		return append(append([]byte{}, content...), clientTag...)
+	}
+
+	injected := make([]byte, 0, len(content)+len(clientTag))
+	injected = append(injected, content[:i]...)
+	injected = append(injected, clientTag...)
+	return append(injected, content[i:]...)
+}
@@ -0,0 +1,40 @@
+package livereload
+
+import "testing"
+
+func TestInjectClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "before the closing body tag",
+			content: "<html><body><p>hi</p></body></html>",
+			want:    "<html><body><p>hi</p>" + clientTag + "</body></html>",
+		},
+		{
+			name:    "closing body tag in any case",
+			content: "<BODY>hi</BODY>",
+			want:    "<BODY>hi" + clientTag + "</BODY>",
+		},
+		{
+			name:    "last closing body tag, not one inside a code sample",
+			content: "<body><code>&lt;/body&gt;</code><pre></body></pre></body>",
+			want:    "<body><code>&lt;/body&gt;</code><pre></body></pre>" + clientTag + "</body>",
+		},
+		{
+			name:    "appended without a body tag",
+			content: "<p>fragment</p>",
+			want:    "<p>fragment</p>" + clientTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(injectClient([]byte(tt.content))); got != tt.want {
+				t.Errorf("injectClient() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
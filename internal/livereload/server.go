@@ -0,0 +1,207 @@
+package livereload
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// EventsPath is where browsers subscribe to reload events.
+const EventsPath = "/_temingo/livereload"
+
+// ClientPath serves the script injected into every HTML page.
+const ClientPath = "/_temingo/livereload.js"
+
+// event is one server-sent event. Name is one of "reload", "css" or "build-error".
+type event struct {
+	Name string
+	Data string
+}
+
+// Server serves a directory like http.FileServer, but injects the live reload
+// client into HTML responses and pushes rebuild outcomes to every open page.
+// The files on disk are never modified.
+type Server struct {
+	dir   string
+	files http.Handler
+
+	mu      sync.Mutex
+	clients map[chan event]struct{}
+	// lastError is the message of the last failed build, or empty once a build
+	// succeeded. A page loaded while the build is broken gets it on connect.
+	lastError string
+}
+
+// New returns a Server for dir.
+func New(dir string) *Server {
+	return &Server{
+		dir:     dir,
+		files:   http.FileServer(http.Dir(dir)),
+		clients: map[chan event]struct{}{},
+	}
+}
+
+// Reload tells every page that a build succeeded and changed the given output
+// paths. If each of them is a stylesheet the pages swap their stylesheets in
+// place; anything else reloads them. A build that changed nothing is only
+// announced if it clears a previous build error.
+func (s *Server) Reload(changedPaths []string) {
+	s.mu.Lock()
+	hadError := s.lastError != ""
+	s.lastError = ""
+	s.mu.Unlock()
+
+	if len(changedPaths) == 0 {
+		if hadError {
+			s.broadcast(event{Name: "reload"})
+		}
+		return
+	}
+
+	for _, changedPath := range changedPaths {
+		if path.Ext(changedPath) != ".css" {
+			s.broadcast(event{Name: "reload"})
+			return
+		}
+	}
+	s.broadcast(event{Name: "css", Data: strings.Join(changedPaths, "\n")})
+}
+
+// BuildFailed shows err as an overlay on every page, until the next Reload.
+func (s *Server) BuildFailed(err error) {
+	s.mu.Lock()
+	s.lastError = err.Error()
+	s.mu.Unlock()
+
+	s.broadcast(event{Name: "build-error", Data: err.Error()})
+}
+
+// broadcast sends e to every subscribed page. A page that is not keeping up
+// misses it rather than blocking the build.
+func (s *Server) broadcast(e event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		select {
+		case client <- e:
+		default:
+		}
+	}
+}
+
+// subscribe registers a new page. The returned function unregisters it.
+func (s *Server) subscribe() (chan event, func()) {
+	client := make(chan event, 8)
+
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	if s.lastError != "" {
+		client <- event{Name: "build-error", Data: s.lastError}
+	}
+	s.mu.Unlock()
+
+	return client, func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case EventsPath:
+		s.serveEvents(w, r)
+		return
+	case ClientPath:
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write([]byte(clientScript))
+		return
+	}
+
+	if s.serveHTML(w, r) {
+		return
+	}
+	s.files.ServeHTTP(w, r)
+}
+
+// serveEvents streams events to one page until it disconnects.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	client, unsubscribe := s.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-client:
+			fmt.Fprintf(w, "event: %s\n", e.Name)
+			for _, line := range strings.Split(e.Data, "\n") { // Data spanning lines needs one data field per line
+				fmt.Fprintf(w, "data: %s\n", line)
+			}
+			fmt.Fprint(w, "\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// serveHTML serves the HTML page r asks for with the client injected. It
+// reports false for everything else, including the redirects http.FileServer
+// issues for directories without a trailing slash and for index.html, which
+// are left to it.
+func (s *Server) serveHTML(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	urlPath := r.URL.Path
+	if !strings.HasPrefix(urlPath, "/") {
+		urlPath = "/" + urlPath
+	}
+	if strings.HasSuffix(urlPath, "/index.html") {
+		return false
+	}
+	name := path.Clean(urlPath)
+	if strings.HasSuffix(urlPath, "/") {
+		name = path.Join(name, "index.html")
+	}
+	if path.Ext(name) != ".html" {
+		return false
+	}
+
+	file, err := http.Dir(s.dir).Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(injectClient(content)))
+	return true
+}
@@ -0,0 +1,181 @@
+package livereload
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeHTTP(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.html":      "<body>home</body>",
+		"blog/index.html": "<body>blog</body>",
+		"style.css":       "body {}",
+	}
+	for relPath, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(relPath)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, relPath), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := httptest.NewServer(New(dir))
+	defer srv.Close()
+	client := srv.Client()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "html page gets the client", path: "/", wantStatus: 200, wantBody: "home" + clientTag + "</body>"},
+		{name: "nested index gets the client", path: "/blog/", wantStatus: 200, wantBody: "blog" + clientTag + "</body>"},
+		{name: "directory without slash is redirected", path: "/blog", wantStatus: http.StatusMovedPermanently},
+		{name: "other files are served unchanged", path: "/style.css", wantStatus: 200, wantBody: "body {}"},
+		{name: "missing files are not found", path: "/missing.html", wantStatus: 404},
+		{name: "client script", path: ClientPath, wantStatus: 200, wantBody: "EventSource"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatalf("GET %s: %v", tt.path, err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantBody != "" && !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("GET %s body = %q, want it to contain %q", tt.path, body, tt.wantBody)
+			}
+		})
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(dir, "index.html")); string(content) != files["index.html"] {
+		t.Errorf("serving modified the file on disk: %q", content)
+	}
+}
+
+// nextEvent reads one event from a server-sent event stream.
+func nextEvent(t *testing.T, reader *bufio.Reader) event {
+	t.Helper()
+	var (
+		e    event
+		data []string
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			e.Data = strings.Join(data, "\n")
+			return e
+		case strings.HasPrefix(line, "event: "):
+			e.Name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	reloader := New(t.TempDir())
+	srv := httptest.NewServer(reloader)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + EventsPath)
+	if err != nil {
+		t.Fatalf("GET %s: %v", EventsPath, err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", got)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	// The subscription is registered after the headers are sent
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		reloader.mu.Lock()
+		subscribed := len(reloader.clients)
+		reloader.mu.Unlock()
+		if subscribed == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("page never subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	tests := []struct {
+		name   string
+		notify func()
+		want   event
+	}{
+		{
+			name:   "only stylesheets changed",
+			notify: func() { reloader.Reload([]string{"a.css", "theme/b.css"}) },
+			want:   event{Name: "css", Data: "a.css\ntheme/b.css"},
+		},
+		{
+			name:   "a page changed",
+			notify: func() { reloader.Reload([]string{"a.css", "index.html"}) },
+			want:   event{Name: "reload"},
+		},
+		{
+			name:   "build failed",
+			notify: func() { reloader.BuildFailed(errors.New("rendering template index.html:\nunexpected EOF")) },
+			want:   event{Name: "build-error", Data: "rendering template index.html:\nunexpected EOF"},
+		},
+		{
+			name:   "an unchanged build clears the error",
+			notify: func() { reloader.Reload(nil) },
+			want:   event{Name: "reload"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.notify()
+			if got := nextEvent(t, reader); got != tt.want {
+				t.Errorf("event = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribeWhileBuildIsBroken(t *testing.T) {
+	reloader := New(t.TempDir())
+	reloader.BuildFailed(errors.New("broken"))
+
+	client, unsubscribe := reloader.subscribe()
+	defer unsubscribe()
+
+	select {
+	case got := <-client:
+		if want := (event{Name: "build-error", Data: "broken"}); got != want {
+			t.Errorf("event = %+v, want %+v", got, want)
+		}
+	default:
+		t.Error("a page loaded while the build is broken should get the error on connect")
+	}
+}
@@ -49,8 +49,12 @@ type Finding struct {
 }
 
 func (f Finding) String() string {
+	location := f.Ref.File
+	if f.Ref.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", f.Ref.File, f.Ref.Line, f.Ref.Col)
+	}
 	return fmt.Sprintf("%s: %s %s: %s: %s",
-		f.Ref.File, f.Ref.Role, f.Ref.URL, f.Category, f.Reason)
+		location, f.Ref.Role, f.Ref.URL, f.Category, f.Reason)
 }
 
 // SortFindings orders findings by file then URL, so output is stable across
@@ -0,0 +1,62 @@
+package refcheck
+
+import (
+	"bytes"
+	"html"
+)
+
+// Locate sets the line and column of every reference to where its URL appears
+// in content, the file the references were collected from. It is separate from
+// collection because a reference in a style attribute or element is collected
+// from a fragment of the file, which a position has to be relative to.
+//
+// References are collected in document order, so each URL is searched from
+// where the previous one was found; that tells apart repeated occurrences of
+// the same URL. A URL not found after that point is searched from the start,
+// and one that does not appear verbatim at all - written with entities, say -
+// is tried HTML-escaped before being left unlocated.
+func Locate(content []byte, refs []Reference) {
+	cursor := 0
+	for i := range refs {
+		offset, length := -1, 0
+		for _, needle := range []string{refs[i].URL, html.EscapeString(refs[i].URL)} {
+			if offset = indexFrom(content, []byte(needle), cursor); offset < 0 {
+				offset = bytes.Index(content, []byte(needle))
+			}
+			if offset >= 0 {
+				length = len(needle)
+				break
+			}
+		}
+		if offset < 0 {
+			refs[i].Line, refs[i].Col = 0, 0
+			continue
+		}
+
+		refs[i].Line, refs[i].Col = LineCol(content, offset)
+		cursor = offset + length
+	}
+}
+
+// indexFrom is bytes.Index starting at from, returning an offset into s.
+func indexFrom(s, sep []byte, from int) int {
+	if from > len(s) {
+		return -1
+	}
+	i := bytes.Index(s[from:], sep)
+	if i < 0 {
+		return -1
+	}
+	return from + i
+}
+
+// LineCol returns the 1-based line and column of the byte offset in content.
+// The column counts bytes, as text/template's positions do.
+func LineCol(content []byte, offset int) (int, int) {
+	line := 1 + bytes.Count(content[:offset], []byte("\n"))
+	col := offset + 1
+	if i := bytes.LastIndexByte(content[:offset], '\n'); i >= 0 {
+		col = offset - i
+	}
+	return line, col
+}
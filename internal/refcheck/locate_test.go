@@ -0,0 +1,37 @@
+package refcheck
+
+import "testing"
+
+func TestLocate(t *testing.T) {
+	content := []byte("<html>\n" +
+		"  <a href=\"/a/\">a</a>\n" +
+		"  <a href=\"/a/\">again</a>\n" +
+		"  <img src=\"x.jpg?w=1&amp;h=2\">\n" +
+		"</html>")
+
+	refs := []Reference{
+		{URL: "/a/"},
+		{URL: "/a/"},
+		{URL: "x.jpg?w=1&h=2"},
+		{URL: "/nowhere/"},
+	}
+	Locate(content, refs)
+
+	want := [][2]int{{2, 12}, {3, 12}, {4, 13}, {0, 0}}
+	for i, ref := range refs {
+		if ref.Line != want[i][0] || ref.Col != want[i][1] {
+			t.Errorf("Locate() refs[%d] at %d:%d, want %d:%d", i, ref.Line, ref.Col, want[i][0], want[i][1])
+		}
+	}
+}
+
+func TestFindingStringWithPosition(t *testing.T) {
+	f := Finding{
+		Ref:      Reference{File: "index.html", Line: 3, Col: 12, URL: "/a/", Role: "a href"},
+		Category: CategoryMissingTarget,
+		Reason:   "no such output",
+	}
+	if got, want := f.String(), "index.html:3:12: a href /a/: missing-target: no such output"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -21,6 +21,10 @@ const (
 type Reference struct {
 	// File is the output-relative path of the file the reference was found in.
 	File string
+	// Line and Col locate the URL within File, 1-based. They are 0 when the URL
+	// does not appear verbatim, such as when it was written with entities.
+	Line int
+	Col  int
 	// URL is the target exactly as written.
 	URL string
 	// Role names the syntactic position, for reporting back to the author.
@@ -0,0 +1,146 @@
+package validate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/thetillhoff/temingo/internal/refcheck"
+)
+
+// CSS reports the syntax errors in the stylesheet content that make a browser
+// drop a rule or everything after it: unbalanced braces and parentheses,
+// unterminated strings and comments, declarations without a colon, and
+// top-level statements that are neither a rule nor an at-rule.
+func CSS(file string, content []byte) []refcheck.Finding {
+	var (
+		findings []refcheck.Finding
+		braces   []int // Offsets of the open braces
+		parens   []int // Offsets of the open parentheses
+		segStart = -1  // Offset of the first non-space byte since the last `{`, `}` or `;`
+	)
+
+	report := func(at int, reason string) {
+		findings = append(findings, newFinding(file, content, at, "css", CategoryInvalidCSS, reason))
+	}
+
+	// endSegment checks the statement that ends at offset. Inside a block it
+	// is a declaration or a nested at-rule, at the top level only an at-rule.
+	endSegment := func(end int) {
+		if segStart < 0 {
+			return
+		}
+		segment := bytes.TrimSpace(content[segStart:end])
+		switch {
+		case len(segment) == 0, segment[0] == '@':
+		case len(braces) == 0:
+			report(segStart, fmt.Sprintf("%q is neither a rule nor an at-rule", shorten(segment)))
+		case !bytes.ContainsRune(segment, ':'):
+			report(segStart, fmt.Sprintf("declaration %q has no colon", shorten(segment)))
+		}
+		segStart = -1
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				report(i, "comment is never closed")
+				return findings
+			}
+			i += 2 + end + 1
+			continue
+
+		case c == '\\':
+			i++
+
+		case c == '"' || c == '\'':
+			if segStart < 0 {
+				segStart = i
+			}
+			end := stringEnd(content, i)
+			if end < 0 {
+				report(i, "string is never closed")
+				for i < len(content) && content[i] != '\n' { // A browser recovers at the end of the line
+					i++
+				}
+				continue
+			}
+			i = end - 1
+
+		case c == '(':
+			parens = append(parens, i)
+
+		case c == ')':
+			if len(parens) == 0 {
+				report(i, "closing parenthesis without an opening one")
+			} else {
+				parens = parens[:len(parens)-1]
+			}
+
+		case len(parens) > 0:
+			// Inside parentheses, braces and semicolons are part of the value
+
+		case c == '{':
+			segStart = -1 // A selector or at-rule prelude, which is not checked
+			braces = append(braces, i)
+
+		case c == '}':
+			endSegment(i)
+			if len(braces) == 0 {
+				report(i, "closing brace without an opening one")
+			} else {
+				braces = braces[:len(braces)-1]
+			}
+
+		case c == ';':
+			endSegment(i)
+
+		default:
+			if segStart < 0 && !isSpace(c) {
+				segStart = i
+			}
+		}
+	}
+
+	for _, open := range parens {
+		report(open, "parenthesis is never closed")
+	}
+	for _, open := range braces {
+		report(open, "brace is never closed")
+	}
+	return findings
+}
+
+// stringEnd returns the offset after the string starting at i, or -1 if a line
+// break or the end of content comes first.
+func stringEnd(content []byte, i int) int {
+	quote := content[i]
+	for j := i + 1; j < len(content); j++ {
+		switch content[j] {
+		case '\\':
+			j++
+		case '\n':
+			return -1
+		case quote:
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// shorten returns the start of segment, for quoting in a reason.
+func shorten(segment []byte) string {
+	const limit = 40
+	s := strings.Join(strings.Fields(string(segment)), " ")
+	if len(s) > limit {
+		return s[:limit] + "..."
+	}
+	return s
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/thetillhoff/temingo/internal/refcheck"
+)
+
+func TestCSS(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantLines []int
+	}{
+		{
+			name:    "valid stylesheet",
+			content: "@import url(a.css);\n@charset \"utf-8\";\na { color: red; background: url(data:x;y) }\n@media (min-width: 1px) { a:hover { b: c } }\n.a { content: \"}\"; }",
+		},
+		{
+			name:    "nested rules",
+			content: ".a { color: red; &:hover { color: blue } .b { c: d } }",
+		},
+		{
+			name:      "unclosed brace",
+			content:   "a { color: red;\n\nb { c: d }",
+			wantLines: []int{1},
+		},
+		{
+			name:      "stray closing brace",
+			content:   "a { b: c }\n}",
+			wantLines: []int{2},
+		},
+		{
+			name:      "declaration without colon",
+			content:   "a {\n  color red;\n}",
+			wantLines: []int{2},
+		},
+		{
+			name:      "top-level statement",
+			content:   "color: red;\na { b: c }",
+			wantLines: []int{1},
+		},
+		{
+			name:      "unterminated string",
+			content:   "a {\n  content: \"x;\n}",
+			wantLines: []int{2},
+		},
+		{
+			name:      "unterminated comment",
+			content:   "a { b: c }\n/* open",
+			wantLines: []int{2},
+		},
+		{
+			name:      "unclosed parenthesis",
+			content:   "a { width: calc(1px + 2px; }",
+			wantLines: []int{1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := CSS("style.css", []byte(tt.content))
+			if len(findings) != len(tt.wantLines) {
+				t.Fatalf("got %d findings %v, want lines %v", len(findings), findings, tt.wantLines)
+			}
+			for i, f := range findings {
+				if f.Category != CategoryInvalidCSS || f.Ref.Line != tt.wantLines[i] {
+					t.Errorf("finding %d = %s, want %s on line %d", i, f, CategoryInvalidCSS, tt.wantLines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFile_DispatchesOnExtension(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    refcheck.Category
+	}{
+		{"a.html", "<div>", CategoryMalformedHTML},
+		{"a.css", "a {", CategoryInvalidCSS},
+		{"a.json", "{\"a\": }", CategoryInvalidJSON},
+		{"a.xml", "<a><b></a>", CategoryInvalidXML},
+		{"a.svg", "<svg>&nbsp;</svg>", CategoryInvalidXML},
+		{"a.yaml", "a: [", CategoryInvalidYAML},
+		{"a.yml", "a: b\n c: d", CategoryInvalidYAML},
+		{"a.txt", "<div>", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			findings := File(tt.file, []byte(tt.content))
+			if tt.want == "" {
+				if len(findings) != 0 {
+					t.Errorf("got findings %v, want none", findings)
+				}
+				return
+			}
+			if len(findings) == 0 || findings[0].Category != tt.want {
+				t.Errorf("got findings %v, want %s", findings, tt.want)
+			}
+		})
+	}
+}
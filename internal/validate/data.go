@@ -0,0 +1,81 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thetillhoff/temingo/internal/refcheck"
+)
+
+// yamlErrorRe matches the line yaml puts in front of syntax errors.
+var yamlErrorRe = regexp.MustCompile(`(?s)^yaml: line (\d+): (.*)$`)
+
+// JSON reports content that is not valid JSON, at the offset the parser
+// stopped at.
+func JSON(file string, content []byte) []refcheck.Finding {
+	var value any
+	err := json.Unmarshal(content, &value)
+	if err == nil {
+		return nil
+	}
+	offset := len(content)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = int(syntaxErr.Offset) - 1
+	}
+	return []refcheck.Finding{newFinding(file, content, offset, "json", CategoryInvalidJSON, err.Error())}
+}
+
+// XML reports content that is not well-formed XML, like an SVG image with an
+// unclosed element or an HTML entity XML does not know.
+func XML(file string, content []byte) []refcheck.Finding {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			continue
+		}
+		// The decoder knows the line, but not the column
+		finding := newFinding(file, content, int(decoder.InputOffset()), "xml", CategoryInvalidXML, err.Error())
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			finding.Reason = syntaxErr.Msg
+			finding.Ref.Line, finding.Ref.Col = syntaxErr.Line, 0
+		}
+		return []refcheck.Finding{finding}
+	}
+	return nil
+}
+
+// YAML reports content that is not valid YAML, at the line the parser reports
+// if it reports one.
+func YAML(file string, content []byte) []refcheck.Finding {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for { // A YAML file can hold several documents
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			continue
+		}
+		finding := newFinding(file, content, 0, "yaml", CategoryInvalidYAML, err.Error())
+		finding.Ref.Line, finding.Ref.Col = 0, 0
+		if match := yamlErrorRe.FindStringSubmatch(err.Error()); match != nil {
+			finding.Ref.Line, _ = strconv.Atoi(match[1])
+			finding.Reason = match[2]
+		}
+		return []refcheck.Finding{finding}
+	}
+}
@@ -0,0 +1,36 @@
+package validate
+
+import "testing"
+
+func TestJSON(t *testing.T) {
+	if findings := JSON("a.json", []byte("{\n  \"a\": [1, 2]\n}\n")); len(findings) != 0 {
+		t.Errorf("valid JSON: got findings %v", findings)
+	}
+
+	findings := JSON("a.json", []byte("{\n  \"a\": 1,\n}\n"))
+	if len(findings) != 1 || findings[0].Ref.Line != 3 {
+		t.Errorf("trailing comma: got findings %v, want one on line 3", findings)
+	}
+}
+
+func TestXML(t *testing.T) {
+	if findings := XML("a.svg", []byte("<?xml version=\"1.0\"?>\n<svg xmlns=\"http://www.w3.org/2000/svg\"><path d=\"M0 0\"/><![CDATA[ <x> ]]></svg>")); len(findings) != 0 {
+		t.Errorf("valid XML: got findings %v", findings)
+	}
+
+	findings := XML("a.xml", []byte("<a>\n  <b>\n</a>\n"))
+	if len(findings) != 1 || findings[0].Ref.Line != 3 {
+		t.Errorf("mismatched tag: got findings %v, want one on line 3", findings)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	if findings := YAML("a.yaml", []byte("a: 1\n---\nb: [1, 2]\n")); len(findings) != 0 {
+		t.Errorf("valid YAML: got findings %v", findings)
+	}
+
+	findings := YAML("a.yaml", []byte("a: 1\nb:\n  c: 2\n d: 3\n"))
+	if len(findings) != 1 || findings[0].Ref.Line != 3 {
+		t.Errorf("bad indentation: got findings %v, want one on line 3", findings)
+	}
+}
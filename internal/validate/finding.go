@@ -0,0 +1,75 @@
+// Package validate checks rendered output for syntax and structure errors a
+// browser or parser would silently work around, or choke on: malformed or
+// badly nested HTML, duplicate ids, images without alternative text, and CSS,
+// JSON, XML and YAML that does not parse.
+//
+// Findings share their shape with reference findings, so they are reported,
+// allowlisted and made fatal the same way. The URL of a validation finding is
+// the site-absolute path of the file it is in, like /blog/index.html, which is
+// what an allowlist entry matches against.
+package validate
+
+import (
+	"path"
+
+	"github.com/thetillhoff/temingo/internal/refcheck"
+)
+
+const (
+	// CategoryMalformedHTML is an element that is never closed, or a closing
+	// tag that closes nothing.
+	CategoryMalformedHTML refcheck.Category = "malformed-html"
+	// CategoryInvalidNesting is an element inside a parent that may not
+	// contain it, which the browser restructures.
+	CategoryInvalidNesting refcheck.Category = "invalid-nesting"
+	// CategoryDuplicateID is an id already used by an earlier element.
+	CategoryDuplicateID refcheck.Category = "duplicate-id"
+	// CategoryMissingAlt is an image without an alt attribute.
+	CategoryMissingAlt refcheck.Category = "missing-alt"
+	// CategoryInvalidCSS is a stylesheet that does not parse.
+	CategoryInvalidCSS refcheck.Category = "invalid-css"
+	// CategoryInvalidJSON is a JSON file that does not parse.
+	CategoryInvalidJSON refcheck.Category = "invalid-json"
+	// CategoryInvalidXML is an XML or SVG file that is not well-formed.
+	CategoryInvalidXML refcheck.Category = "invalid-xml"
+	// CategoryInvalidYAML is a YAML file that does not parse.
+	CategoryInvalidYAML refcheck.Category = "invalid-yaml"
+)
+
+// Extensions are the file extensions File validates.
+var Extensions = []string{".html", ".css", ".json", ".xml", ".svg", ".yaml", ".yml"}
+
+// File validates the output file at the output-relative path file,
+// dispatching on its extension. Other files yield no findings.
+func File(file string, content []byte) []refcheck.Finding {
+	switch path.Ext(file) {
+	case ".html":
+		return HTML(file, content)
+	case ".css":
+		return CSS(file, content)
+	case ".json":
+		return JSON(file, content)
+	case ".xml", ".svg":
+		return XML(file, content)
+	case ".yaml", ".yml":
+		return YAML(file, content)
+	}
+	return nil
+}
+
+// newFinding returns a finding at the byte offset in content, the file file.
+// Role names what the finding is about, like an element or the file type.
+func newFinding(file string, content []byte, offset int, role string, category refcheck.Category, reason string) refcheck.Finding {
+	line, col := refcheck.LineCol(content, min(max(offset, 0), len(content)))
+	return refcheck.Finding{
+		Ref: refcheck.Reference{
+			File: file,
+			Line: line,
+			Col:  col,
+			URL:  "/" + file,
+			Role: role,
+		},
+		Category: category,
+		Reason:   reason,
+	}
+}
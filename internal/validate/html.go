@@ -0,0 +1,272 @@
+package validate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/thetillhoff/temingo/internal/refcheck"
+)
+
+// voidElements never have content or a closing tag.
+var voidElements = setOf("area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "param", "source", "track", "wbr")
+
+// optionalEndTags are the elements whose closing tag may be left out. They are
+// closed by the closing tag of their parent, or by a start tag impliedEnds
+// lists for them.
+var optionalEndTags = setOf("html", "head", "body", "p", "li", "dt", "dd", "option", "optgroup", "tr", "td", "th", "thead", "tbody", "tfoot", "colgroup", "caption", "rt", "rp")
+
+// impliedEnds maps an element with an optional closing tag to the start tags
+// that close it.
+var impliedEnds = map[string]map[string]bool{
+	"p":        setOf("p"),
+	"li":       setOf("li"),
+	"dt":       setOf("dt", "dd"),
+	"dd":       setOf("dt", "dd"),
+	"option":   setOf("option", "optgroup"),
+	"optgroup": setOf("optgroup"),
+	"tr":       setOf("tr", "tbody", "thead", "tfoot"),
+	"td":       setOf("td", "th", "tr", "tbody", "thead", "tfoot"),
+	"th":       setOf("td", "th", "tr", "tbody", "thead", "tfoot"),
+	"thead":    setOf("tbody", "tfoot"),
+	"tbody":    setOf("tbody", "tfoot"),
+	"rt":       setOf("rt", "rp"),
+	"rp":       setOf("rt", "rp"),
+	"head":     setOf("body"),
+}
+
+// flowElements are the elements only allowed where flow content is: not inside
+// a paragraph, a heading or phrasing elements like span.
+var flowElements = setOf("address", "article", "aside", "blockquote", "details", "dialog", "div", "dl", "fieldset", "figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "main", "menu", "nav", "ol", "p", "pre", "section", "table", "ul")
+
+// phrasingOnlyParents are the elements that may only contain phrasing content.
+var phrasingOnlyParents = setOf("p", "h1", "h2", "h3", "h4", "h5", "h6", "span", "b", "i", "em", "strong", "small", "code", "label", "abbr", "cite", "q", "sub", "sup", "u", "s", "mark", "time", "var", "kbd", "samp", "dfn", "bdi", "bdo", "button", "pre")
+
+// interactiveElements may not be nested in one another.
+var interactiveElements = setOf("a", "button")
+
+// requiredParents maps an element to the parents it must have.
+var requiredParents = map[string]map[string]bool{
+	"li":       setOf("ul", "ol", "menu"),
+	"dt":       setOf("dl", "div"),
+	"dd":       setOf("dl", "div"),
+	"tr":       setOf("table", "thead", "tbody", "tfoot"),
+	"td":       setOf("tr"),
+	"th":       setOf("tr"),
+	"thead":    setOf("table"),
+	"tbody":    setOf("table"),
+	"tfoot":    setOf("table"),
+	"caption":  setOf("table"),
+	"colgroup": setOf("table"),
+	"option":   setOf("select", "datalist", "optgroup"),
+	"optgroup": setOf("select"),
+	"summary":  setOf("details"),
+}
+
+// openElement is an element the HTML validator has seen the start tag of.
+type openElement struct {
+	name   string
+	offset int
+}
+
+// HTML reports elements in content that are never closed or close nothing,
+// elements nested where they may not be, ids used more than once, and images
+// without an alt attribute.
+//
+// It reads the markup as written rather than parsing it into a document, since
+// a parser repairs exactly the mistakes it is meant to find.
+func HTML(file string, content []byte) []refcheck.Finding {
+	var (
+		findings  []refcheck.Finding
+		tokenizer = html.NewTokenizer(bytes.NewReader(content))
+		stack     []openElement
+		ids       = map[string]int{} // The offset of the element each id was first used at
+		offset    int
+	)
+
+	report := func(at int, role string, category refcheck.Category, reason string) {
+		findings = append(findings, newFinding(file, content, at, role, category, reason))
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		start := offset
+		offset += len(tokenizer.Raw())
+
+		if tokenType != html.StartTagToken && tokenType != html.EndTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+		token := tokenizer.Token()
+		name := token.Data
+		if !isElement(token, stack) {
+			continue
+		}
+
+		if tokenType == html.EndTagToken {
+			if voidElements[name] {
+				report(start, name, CategoryMalformedHTML, fmt.Sprintf("</%s> closes a void element, which has no closing tag", name))
+				continue
+			}
+			index := lastIndexOf(stack, name)
+			if index < 0 {
+				report(start, name, CategoryMalformedHTML, fmt.Sprintf("</%s> closes no open element", name))
+				continue
+			}
+			for _, unclosed := range stack[index+1:] {
+				if !optionalEndTags[unclosed.name] {
+					report(unclosed.offset, unclosed.name, CategoryMalformedHTML, fmt.Sprintf("<%s> is never closed; </%s> closes it implicitly", unclosed.name, name))
+				}
+			}
+			stack = stack[:index]
+			continue
+		}
+
+		// A start tag closes the open elements whose closing tag it implies
+		for len(stack) > 0 && impliedEnds[stack[len(stack)-1].name][name] {
+			stack = stack[:len(stack)-1]
+		}
+
+		if id, ok := attribute(token, "id"); ok && id != "" {
+			if first, ok := ids[id]; ok {
+				line, _ := refcheck.LineCol(content, first)
+				report(start, name, CategoryDuplicateID, fmt.Sprintf("id %q is already used on line %d", id, line))
+			} else {
+				ids[id] = start
+			}
+		}
+
+		if _, ok := attribute(token, "alt"); !ok && needsAlt(token) {
+			report(start, name, CategoryMissingAlt, fmt.Sprintf("<%s> has no alt attribute; use alt=\"\" for a decorative image", name))
+		}
+
+		if reason := nestingError(stack, name); reason != "" {
+			report(start, name, CategoryInvalidNesting, reason)
+		}
+
+		if tokenType == html.StartTagToken && !voidElements[name] {
+			stack = append(stack, openElement{name: name, offset: start})
+		}
+	}
+
+	for _, unclosed := range stack {
+		if !optionalEndTags[unclosed.name] {
+			report(unclosed.offset, unclosed.name, CategoryMalformedHTML, fmt.Sprintf("<%s> is never closed", unclosed.name))
+		}
+	}
+
+	return findings
+}
+
+// isElement reports whether token names an element, rather than being text
+// that happens to look like a tag, such as a `<nil>` printed by a template. An
+// element is a known HTML one, a custom element, or anything inside svg or
+// math, which have elements of their own.
+func isElement(token html.Token, stack []openElement) bool {
+	if token.DataAtom != 0 || strings.Contains(token.Data, "-") {
+		return true
+	}
+	for _, open := range stack {
+		if open.name == "svg" || open.name == "math" {
+			return true
+		}
+	}
+	return false
+}
+
+// nestingError returns why an element name may not be opened inside the open
+// elements stack, or "" if it may.
+func nestingError(stack []openElement, name string) string {
+	var parent string
+	if len(stack) > 0 {
+		parent = stack[len(stack)-1].name
+	}
+
+	if parents, ok := requiredParents[name]; ok && parent != "" && !parents[parent] && parent != "template" {
+		return fmt.Sprintf("<%s> is not allowed directly inside <%s>", name, parent)
+	}
+	if flowElements[name] {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if phrasingOnlyParents[stack[i].name] {
+				return fmt.Sprintf("<%s> is not allowed inside <%s>, which may only contain phrasing content", name, stack[i].name)
+			}
+			if !transparent(stack[i].name) {
+				break
+			}
+		}
+	}
+	if interactiveElements[name] {
+		for _, open := range stack {
+			if interactiveElements[open.name] {
+				return fmt.Sprintf("<%s> is not allowed inside <%s>", name, open.name)
+			}
+		}
+	}
+	if name == "form" {
+		for _, open := range stack {
+			if open.name == "form" {
+				return "<form> is not allowed inside another <form>"
+			}
+		}
+	}
+	return ""
+}
+
+// transparent reports whether the element name takes on the content model of
+// its parent, so what it may contain depends on the elements around it.
+func transparent(name string) bool {
+	switch name {
+	case "a", "ins", "del", "map", "object", "video", "audio", "canvas", "noscript", "slot":
+		return true
+	}
+	return false
+}
+
+// needsAlt reports whether the element needs alternative text.
+func needsAlt(token html.Token) bool {
+	switch token.Data {
+	case "img":
+		return true
+	case "area":
+		_, ok := attribute(token, "href")
+		return ok
+	case "input":
+		kind, _ := attribute(token, "type")
+		return strings.EqualFold(kind, "image")
+	}
+	return false
+}
+
+// attribute returns the value of the attribute key of token, and whether it
+// has one.
+func attribute(token html.Token, key string) (string, bool) {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// lastIndexOf returns the index of the innermost open element called name, or
+// -1 if none is open.
+func lastIndexOf(stack []openElement, name string) int {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func setOf(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
@@ -0,0 +1,122 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/thetillhoff/temingo/internal/refcheck"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantCats []refcheck.Category
+	}{
+		{
+			name:    "valid document",
+			content: "<!DOCTYPE html>\n<html><head><title>x</title></head><body><div id=\"a\"><p>x<br>y</p><img src=\"a.png\" alt=\"\"></div></body></html>",
+		},
+		{
+			name:    "optional closing tags",
+			content: "<ul><li>a<li>b</ul><dl><dt>a<dd>b</dl><table><tr><td>a<td>b<tr><td>c</table><p>a<p>b",
+		},
+		{
+			name:     "unclosed element",
+			content:  "<main><div>a</main>",
+			wantCats: []refcheck.Category{CategoryMalformedHTML},
+		},
+		{
+			name:     "element unclosed at the end",
+			content:  "<section><h1>a</h1>",
+			wantCats: []refcheck.Category{CategoryMalformedHTML},
+		},
+		{
+			name:     "stray closing tag",
+			content:  "<div>a</div></span>",
+			wantCats: []refcheck.Category{CategoryMalformedHTML},
+		},
+		{
+			name:     "closing a void element",
+			content:  "<p>a<br></br></p>",
+			wantCats: []refcheck.Category{CategoryMalformedHTML},
+		},
+		{
+			name:     "duplicate id",
+			content:  "<div id=\"a\"></div><span id=\"a\"></span>",
+			wantCats: []refcheck.Category{CategoryDuplicateID},
+		},
+		{
+			name:     "image without alt",
+			content:  "<img src=\"a.png\"><input type=\"image\" src=\"b.png\"><area href=\"/\">",
+			wantCats: []refcheck.Category{CategoryMissingAlt, CategoryMissingAlt, CategoryMissingAlt},
+		},
+		{
+			name:     "block inside paragraph",
+			content:  "<p>a<div>b</div></p>",
+			wantCats: []refcheck.Category{CategoryInvalidNesting},
+		},
+		{
+			name:     "block inside span through a link",
+			content:  "<span><a href=\"/\"><div>b</div></a></span>",
+			wantCats: []refcheck.Category{CategoryInvalidNesting},
+		},
+		{
+			name:    "block inside a link",
+			content: "<a href=\"/\"><div>b</div></a>",
+		},
+		{
+			name:     "link inside link",
+			content:  "<a href=\"/a\"><span><a href=\"/b\">x</a></span></a>",
+			wantCats: []refcheck.Category{CategoryInvalidNesting},
+		},
+		{
+			name:     "list item outside a list",
+			content:  "<div><li>a</li></div>",
+			wantCats: []refcheck.Category{CategoryInvalidNesting},
+		},
+		{
+			name:    "markup in scripts is not read",
+			content: "<script>document.write('<div>')</script><textarea></p></textarea>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := HTML("index.html", []byte(tt.content))
+			if len(findings) != len(tt.wantCats) {
+				t.Fatalf("got %d findings %v, want %v", len(findings), findings, tt.wantCats)
+			}
+			for i, f := range findings {
+				if f.Category != tt.wantCats[i] {
+					t.Errorf("finding %d: got %s, want %s (%s)", i, f.Category, tt.wantCats[i], f)
+				}
+			}
+		})
+	}
+}
+
+func TestHTML_Positions(t *testing.T) {
+	findings := HTML("blog/index.html", []byte("<div>\n  <div id=\"a\"></div>\n  <p id=\"a\">x</p>\n"))
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings %v, want 2", len(findings), findings)
+	}
+
+	duplicate := findings[0]
+	if duplicate.Category != CategoryDuplicateID || duplicate.Ref.Line != 3 || duplicate.Ref.Col != 3 {
+		t.Errorf("duplicate id finding = %s, want it at 3:3", duplicate)
+	}
+	if duplicate.Ref.URL != "/blog/index.html" || duplicate.Ref.Role != "p" {
+		t.Errorf("duplicate id finding has url %q and role %q, want /blog/index.html and p", duplicate.Ref.URL, duplicate.Ref.Role)
+	}
+
+	unclosed := findings[1]
+	if unclosed.Category != CategoryMalformedHTML || unclosed.Ref.Line != 1 || unclosed.Ref.Col != 1 {
+		t.Errorf("unclosed finding = %s, want it at 1:1", unclosed)
+	}
+}
+
+func TestHTML_IgnoresTextThatLooksLikeTags(t *testing.T) {
+	findings := HTML("index.html", []byte("<p>value: <no value>, <nil></p><my-widget><svg><g></g></svg></my-widget>"))
+	if len(findings) != 0 {
+		t.Errorf("got findings %v, want none", findings)
+	}
+}
@@ -0,0 +1,126 @@
+// Package encodewebp encodes images as lossy WebP in pure Go.
+//
+// The encoder writes a single VP8 key frame, the format of RFC 6386, with the
+// simplest tools the format has: every macroblock is predicted as a whole,
+// from the mode that fits it best, and coded with the default probabilities
+// under one quantizer, without a loop filter. That gives up some compression
+// against libwebp, but still makes photos a fraction of their JPEG size at the
+// widths pages show them at. Transparency is kept in an uncompressed alpha
+// channel.
+package encodewebp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// maxDimension is the largest width and height a VP8 frame can have.
+const maxDimension = 1<<14 - 1
+
+// Encode writes img to w as a lossy WebP image. quality ranges from 0, the
+// smallest file, to 100, the closest to img.
+func Encode(w io.Writer, img image.Image, quality int) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > maxDimension || height > maxDimension {
+		return fmt.Errorf("cannot encode a %dx%d image as webp, which allows 1 to %d pixels per side", width, height, maxDimension)
+	}
+	if quality < 0 || quality > 100 {
+		return fmt.Errorf("quality must be between 0 and 100, got %d", quality)
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	frame, err := encodeFrame(nrgba, quality)
+	if err != nil {
+		return err
+	}
+
+	var chunks []byte
+	if alpha := alphaChannel(nrgba); alpha != nil {
+		extended := make([]byte, 10)
+		extended[0] = 1 << 4 // Has alpha
+		putUint24(extended[4:], uint32(width-1))
+		putUint24(extended[7:], uint32(height-1))
+		chunks = appendChunk(chunks, "VP8X", extended)
+		chunks = appendChunk(chunks, "ALPH", append([]byte{0}, alpha...)) // Neither filtered nor compressed
+	}
+	chunks = appendChunk(chunks, "VP8 ", frame)
+
+	header := make([]byte, 12)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+len(chunks)))
+	copy(header[8:], "WEBP")
+	if _, err = w.Write(header); err != nil {
+		return err
+	}
+	_, err = w.Write(chunks)
+	return err
+}
+
+// alphaChannel returns the alpha values of img row by row, or nil if it is
+// opaque.
+func alphaChannel(img *image.NRGBA) []byte {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	alpha := make([]byte, 0, width*height)
+	opaque := true
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			a := img.Pix[y*img.Stride+4*x+3]
+			opaque = opaque && a == 255
+			alpha = append(alpha, a)
+		}
+	}
+	if opaque {
+		return nil
+	}
+	return alpha
+}
+
+// appendChunk appends a RIFF chunk with data to chunks, padded to an even
+// length.
+func appendChunk(chunks []byte, fourCC string, data []byte) []byte {
+	chunks = append(chunks, fourCC...)
+	chunks = binary.LittleEndian.AppendUint32(chunks, uint32(len(data)))
+	chunks = append(chunks, data...)
+	if len(data)%2 == 1 {
+		chunks = append(chunks, 0)
+	}
+	return chunks
+}
+
+// putUint24 writes v as three little-endian bytes.
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// encodeFrame returns img as a VP8 key frame.
+func encodeFrame(img *image.NRGBA, quality int) ([]byte, error) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	e := newEncoder(img, quantizerIndex(quality))
+	e.encodeMacroblocks()
+
+	firstPartition := e.firstPartition()
+	if len(firstPartition) >= 1<<19 {
+		return nil, errors.New("image has too many macroblocks for a webp frame")
+	}
+
+	frame := make([]byte, 10, 10+len(firstPartition)+len(e.tokens.out))
+	putUint24(frame, uint32(len(firstPartition))<<5|1<<4) // A key frame of version 0, shown
+	frame[3], frame[4], frame[5] = 0x9d, 0x01, 0x2a       // Start code
+	binary.LittleEndian.PutUint16(frame[6:], uint16(width))
+	binary.LittleEndian.PutUint16(frame[8:], uint16(height))
+	frame = append(frame, firstPartition...)
+	return append(frame, e.tokens.bytes()...), nil
+}
+
+// quantizerIndex maps quality to the index of the quantizer step sizes, from
+// 127 for the coarsest to 0 for the finest.
+func quantizerIndex(quality int) int {
+	return (100 - quality) * 127 / 100
+}
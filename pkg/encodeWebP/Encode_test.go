@@ -0,0 +1,133 @@
+package encodewebp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// testImage returns a w x h image with smooth gradients, hard edges and, if
+// noisy, some noise, so every prediction mode and token gets used.
+func testImage(w, h int, noisy bool, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	rng := rand.New(rand.NewSource(1))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{uint8(x * 255 / w), uint8(y * 255 / h), uint8((x + y) * 4), alpha}
+			if (x/10+y/7)%3 == 0 {
+				c.R, c.G = 255-c.R, 40
+			}
+			if noisy {
+				c.B = uint8(rng.Intn(256))
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		img      *image.NRGBA
+		quality  int
+		wantPSNR float64 // Of the luma the decoder shows against the source
+	}{
+		{name: "gradients at default quality", img: testImage(64, 48, false, 255), quality: 80, wantPSNR: 34},
+		{name: "noise at full quality", img: testImage(40, 40, true, 255), quality: 100, wantPSNR: 40},
+		{name: "odd size at low quality", img: testImage(37, 21, false, 255), quality: 10, wantPSNR: 22},
+		{name: "single pixel", img: testImage(1, 1, false, 255), quality: 80, wantPSNR: 40},
+		{name: "translucent", img: testImage(20, 30, false, 100), quality: 80, wantPSNR: 34},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, tt.img, tt.quality); err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("decoding the output failed: %v", err)
+			}
+			if decoded.Bounds() != tt.img.Bounds() {
+				t.Fatalf("decoded bounds = %v, want %v", decoded.Bounds(), tt.img.Bounds())
+			}
+
+			var ycbcr *image.YCbCr
+			switch d := decoded.(type) {
+			case *image.YCbCr:
+				ycbcr = d
+			case *image.NYCbCrA:
+				ycbcr = &d.YCbCr
+				if !bytes.Equal(d.A, alphaChannel(tt.img)) {
+					t.Errorf("decoded alpha differs from the source")
+				}
+			default:
+				t.Fatalf("decoded a %T, want YCbCr", decoded)
+			}
+
+			// The decoder must see exactly what the encoder predicted from
+			e := newEncoder(tt.img, quantizerIndex(tt.quality))
+			e.encodeMacroblocks()
+			w, h := tt.img.Rect.Dx(), tt.img.Rect.Dy()
+			var sse float64
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					got := ycbcr.Y[y*ycbcr.YStride+x]
+					if want := e.ry.pix[y*e.ry.stride+x]; got != want {
+						t.Fatalf("decoded luma at %d,%d = %d, want the reconstruction %d", x, y, got, want)
+					}
+					d := float64(got) - float64(e.y.pix[y*e.y.stride+x])
+					sse += d * d
+				}
+			}
+			for y := 0; y < (h+1)/2; y++ {
+				for x := 0; x < (w+1)/2; x++ {
+					if got, want := ycbcr.Cb[y*ycbcr.CStride+x], e.ru.pix[y*e.ru.stride+x]; got != want {
+						t.Fatalf("decoded Cb at %d,%d = %d, want the reconstruction %d", x, y, got, want)
+					}
+					if got, want := ycbcr.Cr[y*ycbcr.CStride+x], e.rv.pix[y*e.rv.stride+x]; got != want {
+						t.Fatalf("decoded Cr at %d,%d = %d, want the reconstruction %d", x, y, got, want)
+					}
+				}
+			}
+
+			psnr := math.Inf(1)
+			if sse > 0 {
+				psnr = 10 * math.Log10(255*255*float64(w*h)/sse)
+			}
+			if psnr < tt.wantPSNR {
+				t.Errorf("luma PSNR = %.1f dB, want at least %.1f dB", psnr, tt.wantPSNR)
+			}
+		})
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		img     image.Image
+		quality int
+		wantErr string
+	}{
+		{name: "empty image", img: image.NewNRGBA(image.Rect(0, 0, 0, 5)), quality: 80, wantErr: "cannot encode a 0x5 image"},
+		{name: "too wide", img: image.NewGray(image.Rect(0, 0, 1<<14, 1)), quality: 80, wantErr: "1 to 16383 pixels"},
+		{name: "quality out of range", img: image.NewNRGBA(image.Rect(0, 0, 1, 1)), quality: 101, wantErr: "quality must be between 0 and 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Encode(&bytes.Buffer{}, tt.img, tt.quality)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Encode() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
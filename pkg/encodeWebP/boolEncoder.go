@@ -0,0 +1,75 @@
+package encodewebp
+
+// boolEncoder is the boolean entropy encoder of section 7 of RFC 6386, which
+// codes every bit of a VP8 partition with the probability that it is 0.
+type boolEncoder struct {
+	out      []byte
+	rng      uint32 // Always in [128, 255] between bits
+	bottom   uint32
+	bitCount int // Bits left before the next byte is shifted out of bottom
+}
+
+func newBoolEncoder() *boolEncoder {
+	return &boolEncoder{rng: 255, bitCount: 24}
+}
+
+// putBit codes bit, which is 0 with probability prob/256.
+func (e *boolEncoder) putBit(bit bool, prob uint8) {
+	split := 1 + ((e.rng-1)*uint32(prob))>>8
+	if bit {
+		e.bottom += split
+		e.rng -= split
+	} else {
+		e.rng = split
+	}
+	for e.rng < 128 {
+		e.rng <<= 1
+		if e.bottom&(1<<31) != 0 {
+			e.carry()
+		}
+		e.bottom <<= 1
+		e.bitCount--
+		if e.bitCount == 0 {
+			e.out = append(e.out, byte(e.bottom>>24))
+			e.bottom &= 1<<24 - 1
+			e.bitCount = 8
+		}
+	}
+}
+
+// putUint codes the n low bits of v, most significant first, with even odds.
+func (e *boolEncoder) putUint(v uint32, n int) {
+	for n > 0 {
+		n--
+		e.putBit(v&(1<<n) != 0, 128)
+	}
+}
+
+// carry adds one to the bytes written so far.
+func (e *boolEncoder) carry() {
+	i := len(e.out) - 1
+	for i >= 0 && e.out[i] == 255 {
+		e.out[i] = 0
+		i--
+	}
+	if i >= 0 { // The encoder never carries out of its first byte
+		e.out[i]++
+	}
+}
+
+// bytes writes out what is left in the encoder and returns the coded bits.
+func (e *boolEncoder) bytes() []byte {
+	v := e.bottom
+	if v&(1<<(32-e.bitCount)) != 0 {
+		e.carry()
+	}
+	v <<= e.bitCount & 7
+	for c := e.bitCount >> 3; c > 0; c-- {
+		v <<= 8
+	}
+	for c := 0; c < 4; c++ {
+		e.out = append(e.out, byte(v>>24))
+		v <<= 8
+	}
+	return e.out
+}
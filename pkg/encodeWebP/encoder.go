@@ -0,0 +1,352 @@
+package encodewebp
+
+import "image"
+
+// The prediction modes of whole macroblocks, numbered as in the decoder.
+const (
+	predDC = iota
+	predTM
+	predVE
+	predHE
+	numModes
+)
+
+// plane is one color plane of an image, padded to whole macroblocks.
+type plane struct {
+	pix    []uint8
+	stride int
+}
+
+// quantizer holds the step sizes of the DC and the AC coefficients of a kind
+// of block.
+type quantizer [2]int32
+
+// encoder encodes the macroblocks of one frame.
+type encoder struct {
+	mbw, mbh int
+
+	y, u, v    plane // The source, in YCbCr 4:2:0
+	ry, ru, rv plane // The frame as the decoder reconstructs it
+
+	y1, y2, uv quantizer
+	qIndex     int
+
+	// The prediction modes and skip flags of the macroblocks, which the first
+	// partition carries, in raster order.
+	lumaModes, chromaModes []uint8
+	skips                  []bool
+
+	tokens *boolEncoder // The partition of the coefficients
+
+	// Whether the blocks left of and above the current macroblock have
+	// non-zero coefficients: 4 luma, 2 + 2 chroma and the Y2 block, which
+	// decides the probabilities their neighbors are coded with.
+	leftNz [9]uint8
+	upNz   [][9]uint8
+}
+
+// newEncoder converts img to padded YCbCr planes, and prepares to encode them
+// with the quantizers at qIndex.
+func newEncoder(img *image.NRGBA, qIndex int) *encoder {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	mbw, mbh := (width+15)/16, (height+15)/16
+	e := &encoder{
+		mbw:    mbw,
+		mbh:    mbh,
+		y:      plane{make([]uint8, 16*mbw*16*mbh), 16 * mbw},
+		u:      plane{make([]uint8, 8*mbw*8*mbh), 8 * mbw},
+		v:      plane{make([]uint8, 8*mbw*8*mbh), 8 * mbw},
+		ry:     plane{make([]uint8, 16*mbw*16*mbh), 16 * mbw},
+		ru:     plane{make([]uint8, 8*mbw*8*mbh), 8 * mbw},
+		rv:     plane{make([]uint8, 8*mbw*8*mbh), 8 * mbw},
+		qIndex: qIndex,
+		tokens: newBoolEncoder(),
+		upNz:   make([][9]uint8, mbw),
+	}
+
+	// Pixels past the edges repeat the last row and column
+	rgb := func(x, y int) (int32, int32, int32) {
+		x, y = min(x, width-1), min(y, height-1)
+		i := y*img.Stride + 4*x
+		return int32(img.Pix[i]), int32(img.Pix[i+1]), int32(img.Pix[i+2])
+	}
+	for y := 0; y < 16*mbh; y++ {
+		for x := 0; x < 16*mbw; x++ {
+			e.y.pix[y*e.y.stride+x] = rgbToY(rgb(x, y))
+		}
+	}
+	for y := 0; y < 8*mbh; y++ {
+		for x := 0; x < 8*mbw; x++ {
+			var r, g, b int32
+			for _, offset := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				pr, pg, pb := rgb(2*x+offset[0], 2*y+offset[1])
+				r, g, b = r+pr, g+pg, b+pb
+			}
+			e.u.pix[y*e.u.stride+x] = rgbToU(r, g, b)
+			e.v.pix[y*e.v.stride+x] = rgbToV(r, g, b)
+		}
+	}
+
+	// The step sizes the decoder derives from the index, as specified in
+	// section 9.6 of RFC 6386
+	e.y1 = quantizer{int32(dcQuantizers[qIndex]), int32(acQuantizers[qIndex])}
+	e.y2 = quantizer{int32(dcQuantizers[qIndex]) * 2, max(int32(acQuantizers[qIndex])*155/100, 8)}
+	e.uv = quantizer{int32(dcQuantizers[min(qIndex, 117)]), int32(acQuantizers[qIndex])}
+
+	return e
+}
+
+// The conversion to the limited range YCbCr of BT.601, which browsers decode
+// WebP with, in 16 bit fixed point. The chroma functions take the sums of the
+// 2x2 pixels they cover.
+func rgbToY(r, g, b int32) uint8 {
+	return uint8((16839*r + 33059*g + 6420*b + 1<<15 + 16<<16) >> 16)
+}
+
+func rgbToU(r, g, b int32) uint8 {
+	return clip8((-9719*r - 19081*g + 28800*b + 1<<17 + 128<<18) >> 18)
+}
+
+func rgbToV(r, g, b int32) uint8 {
+	return clip8((28800*r - 24116*g - 4684*b + 1<<17 + 128<<18) >> 18)
+}
+
+// encodeMacroblocks encodes the macroblocks of the frame in raster order.
+func (e *encoder) encodeMacroblocks() {
+	for mby := 0; mby < e.mbh; mby++ {
+		e.leftNz = [9]uint8{} // Nothing is left of the first macroblock of a row
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+}
+
+// encodeMacroblock predicts the macroblock at mbx, mby, codes its residual and
+// reconstructs it as the decoder will.
+func (e *encoder) encodeMacroblock(mbx, mby int) {
+	var (
+		lumaLevels   [16][16]int32 // By block, in natural order; the DCs are in the Y2 block
+		y2Levels     [16]int32
+		chromaLevels [8][16]int32 // 4 U blocks, then 4 V blocks
+		dcs          [16]int32
+	)
+
+	// Luma, predicted as a whole, with the DCs of its blocks transformed again
+	lumaMode, lumaPrediction := bestPrediction(16, mbx, mby, [][2]*plane{{&e.y, &e.ry}})
+	for block := 0; block < 16; block++ {
+		x, y := 16*mbx+4*(block%4), 16*mby+4*(block/4)
+		coeffs := forwardDCT(residual(&e.y, lumaPrediction[0], 16, x, y, 16*mbx, 16*mby))
+		dcs[block] = coeffs[0]
+		for i := 1; i < 16; i++ {
+			lumaLevels[block][i] = quantize(coeffs[i], e.y1[1], false)
+		}
+	}
+	whtCoeffs := forwardWHT(&dcs)
+	for i := range whtCoeffs {
+		y2Levels[i] = quantize(whtCoeffs[i], e.y2[min(i, 1)], i == 0)
+	}
+
+	// Chroma, with one mode for both planes
+	chromaMode, chromaPrediction := bestPrediction(8, mbx, mby, [][2]*plane{{&e.u, &e.ru}, {&e.v, &e.rv}})
+	for i, source := range []*plane{&e.u, &e.v} {
+		for block := 0; block < 4; block++ {
+			x, y := 8*mbx+4*(block%2), 8*mby+4*(block/2)
+			coeffs := forwardDCT(residual(source, chromaPrediction[i], 8, x, y, 8*mbx, 8*mby))
+			for j := range coeffs {
+				chromaLevels[4*i+block][j] = quantize(coeffs[j], e.uv[min(j, 1)], j == 0)
+			}
+		}
+	}
+
+	// Reconstruct from the quantized levels
+	var y2Coeffs [16]int16
+	for i, level := range y2Levels {
+		y2Coeffs[i] = int16(level * e.y2[min(i, 1)])
+	}
+	reconstructedDCs := inverseWHT(&y2Coeffs)
+	writePrediction(&e.ry, lumaPrediction[0], 16*mbx, 16*mby, 16)
+	for block := 0; block < 16; block++ {
+		var coeffs [16]int16
+		coeffs[0] = reconstructedDCs[block]
+		for i := 1; i < 16; i++ {
+			coeffs[i] = int16(lumaLevels[block][i] * e.y1[1])
+		}
+		x, y := 16*mbx+4*(block%4), 16*mby+4*(block/4)
+		inverseDCT(&coeffs, e.ry.pix[y*e.ry.stride+x:], e.ry.stride)
+	}
+	for i, reconstructed := range []*plane{&e.ru, &e.rv} {
+		writePrediction(reconstructed, chromaPrediction[i], 8*mbx, 8*mby, 8)
+		for block := 0; block < 4; block++ {
+			var coeffs [16]int16
+			for j, level := range chromaLevels[4*i+block] {
+				coeffs[j] = int16(level * e.uv[min(j, 1)])
+			}
+			x, y := 8*mbx+4*(block%2), 8*mby+4*(block/2)
+			inverseDCT(&coeffs, reconstructed.pix[y*reconstructed.stride+x:], reconstructed.stride)
+		}
+	}
+
+	e.lumaModes = append(e.lumaModes, lumaMode)
+	e.chromaModes = append(e.chromaModes, chromaMode)
+	skip := allZero(y2Levels[:]) && allZero(flatten(lumaLevels[:])) && allZero(flatten(chromaLevels[:]))
+	e.skips = append(e.skips, skip)
+	if skip { // No coefficients are coded, and the neighbors see none
+		e.leftNz, e.upNz[mbx] = [9]uint8{}, [9]uint8{}
+		return
+	}
+	e.writeCoefficients(mbx, &y2Levels, &lumaLevels, &chromaLevels)
+}
+
+// bestPrediction returns the mode, and the prediction by it, that predicts the
+// size x size block of the macroblock at mbx, mby in the source planes best
+// from their reconstruction. Each of planes is a source and its
+// reconstruction.
+func bestPrediction(size, mbx, mby int, planes [][2]*plane) (uint8, [][]uint8) {
+	var (
+		bestMode       uint8
+		bestPrediction [][]uint8
+		bestError      = int64(-1)
+	)
+	for mode := uint8(0); mode < numModes; mode++ {
+		var (
+			predictions [][]uint8
+			sse         int64
+		)
+		for _, p := range planes {
+			source, reconstructed := p[0], p[1]
+			prediction := predict(reconstructed, mode, size, mbx, mby)
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					d := int64(source.pix[(size*mby+y)*source.stride+size*mbx+x]) - int64(prediction[y*size+x])
+					sse += d * d
+				}
+			}
+			predictions = append(predictions, prediction)
+		}
+		if bestError < 0 || sse < bestError {
+			bestMode, bestPrediction, bestError = mode, predictions, sse
+		}
+	}
+	return bestMode, bestPrediction
+}
+
+// predict returns the prediction by mode of the size x size block of the
+// macroblock at mbx, mby, from the pixels of p above and left of it. Outside
+// the frame, the row above is 127 and the column to the left is 129, as
+// specified in section 12.2 of RFC 6386.
+func predict(p *plane, mode uint8, size, mbx, mby int) []uint8 {
+	x0, y0 := size*mbx, size*mby
+	top, left := make([]int32, size), make([]int32, size)
+	topLeft := int32(127)
+	for i := 0; i < size; i++ {
+		top[i], left[i] = 127, 129
+		if mby > 0 {
+			top[i] = int32(p.pix[(y0-1)*p.stride+x0+i])
+		}
+		if mbx > 0 {
+			left[i] = int32(p.pix[(y0+i)*p.stride+x0-1])
+		}
+	}
+	if mby > 0 && mbx == 0 {
+		topLeft = 129
+	} else if mby > 0 {
+		topLeft = int32(p.pix[(y0-1)*p.stride+x0-1])
+	}
+
+	prediction := make([]uint8, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			switch mode {
+			case predTM:
+				prediction[y*size+x] = clip8(left[y] + top[x] - topLeft)
+			case predVE:
+				prediction[y*size+x] = uint8(top[x])
+			case predHE:
+				prediction[y*size+x] = uint8(left[y])
+			}
+		}
+	}
+	if mode == predDC { // Averages the edges inside the frame only
+		var sum, count int32
+		if mby > 0 {
+			for _, v := range top {
+				sum += v
+			}
+			count += int32(size)
+		}
+		if mbx > 0 {
+			for _, v := range left {
+				sum += v
+			}
+			count += int32(size)
+		}
+		dc := uint8(128)
+		if count > 0 {
+			dc = uint8((sum + count/2) / count)
+		}
+		for i := range prediction {
+			prediction[i] = dc
+		}
+	}
+	return prediction
+}
+
+// residual returns the difference between the 4x4 block at x, y of source and
+// its prediction, the size x size prediction of the macroblock starting at
+// mbX, mbY.
+func residual(source *plane, prediction []uint8, size, x, y, mbX, mbY int) *[16]int32 {
+	var r [16]int32
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			r[4*j+i] = int32(source.pix[(y+j)*source.stride+x+i]) - int32(prediction[(y-mbY+j)*size+x-mbX+i])
+		}
+	}
+	return &r
+}
+
+// writePrediction copies the size x size prediction into p at x, y.
+func writePrediction(p *plane, prediction []uint8, x, y, size int) {
+	for j := 0; j < size; j++ {
+		copy(p.pix[(y+j)*p.stride+x:], prediction[j*size:(j+1)*size])
+	}
+}
+
+// quantize returns the level of coefficient c under step size q. AC levels
+// are rounded towards zero a little more than DC levels, since small ones are
+// the most costly to code for what they add.
+func quantize(c int32, q int32, dc bool) int32 {
+	bias := q / 3
+	if dc {
+		bias = q / 2
+	}
+	level := (abs(c) + bias) / q
+	level = min(level, 2048) // The largest level the tokens can code
+	if c < 0 {
+		return -level
+	}
+	return level
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func allZero(levels []int32) bool {
+	for _, level := range levels {
+		if level != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func flatten(blocks [][16]int32) []int32 {
+	flat := make([]int32, 0, 16*len(blocks))
+	for _, block := range blocks {
+		flat = append(flat, block[:]...)
+	}
+	return flat
+}
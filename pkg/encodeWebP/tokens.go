@@ -0,0 +1,199 @@
+package encodewebp
+
+// writeCoefficients codes the quantized levels of the macroblock at mbx, in
+// the order and with the contexts of section 13 of RFC 6386: the Y2 block,
+// then the 16 luma blocks and the 4 + 4 chroma blocks, each in raster order.
+func (e *encoder) writeCoefficients(mbx int, y2Levels *[16]int32, lumaLevels *[16][16]int32, chromaLevels *[8][16]int32) {
+	up := &e.upNz[mbx]
+
+	nz := e.writeBlock(planeY2, e.leftNz[8]+up[8], y2Levels, 0)
+	e.leftNz[8], up[8] = nz, nz
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			nz := e.writeBlock(planeY1WithY2, e.leftNz[y]+up[x], &lumaLevels[4*y+x], 1)
+			e.leftNz[y], up[x] = nz, nz
+		}
+	}
+
+	for c := 0; c < 4; c += 2 {
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 2; x++ {
+				nz := e.writeBlock(planeUV, e.leftNz[4+c+y]+up[4+c+x], &chromaLevels[2*c+2*y+x], 0)
+				e.leftNz[4+c+y], up[4+c+x] = nz, nz
+			}
+		}
+	}
+}
+
+// writeBlock codes the levels of a 4x4 block, in natural order, from the
+// coefficient at first in zigzag order on, and returns 1 if any of them is not
+// zero, which is the context of the blocks right of and below it.
+func (e *encoder) writeBlock(plane int, context uint8, levels *[16]int32, first int) uint8 {
+	last := -1
+	for n := first; n < 16; n++ {
+		if levels[zigzag[n]] != 0 {
+			last = n
+		}
+	}
+
+	p := &defaultTokenProbs[plane][bands[first]][context]
+	if last < 0 {
+		e.tokens.putBit(false, p[0]) // End of block
+		return 0
+	}
+	e.tokens.putBit(true, p[0])
+
+	for n := first; n < 16; n++ {
+		level := levels[zigzag[n]]
+		if level == 0 {
+			e.tokens.putBit(false, p[1])
+			p = &defaultTokenProbs[plane][bands[n+1]][0]
+			continue
+		}
+		e.tokens.putBit(true, p[1])
+
+		v := abs(level)
+		e.writeTokenValue(p, v)
+		if v == 1 {
+			p = &defaultTokenProbs[plane][bands[n+1]][1]
+		} else {
+			p = &defaultTokenProbs[plane][bands[n+1]][2]
+		}
+		e.tokens.putBit(level < 0, 128)
+
+		if n == 15 { // The block is full, and has no end to mark
+			break
+		}
+		e.tokens.putBit(n < last, p[0])
+		if n == last {
+			break
+		}
+	}
+	return 1
+}
+
+// writeTokenValue codes v, a level of at least 1, along the token tree of
+// section 13.2, whose large values have extra bits of their own.
+func (e *encoder) writeTokenValue(p *[numProbs]uint8, v int32) {
+	if v == 1 {
+		e.tokens.putBit(false, p[2])
+		return
+	}
+	e.tokens.putBit(true, p[2])
+
+	switch {
+	case v <= 4:
+		e.tokens.putBit(false, p[3])
+		if v == 2 {
+			e.tokens.putBit(false, p[4])
+			return
+		}
+		e.tokens.putBit(true, p[4])
+		e.tokens.putBit(v == 4, p[5])
+	case v <= 10:
+		e.tokens.putBit(true, p[3])
+		e.tokens.putBit(false, p[6])
+		if v <= 6 { // Category 1
+			e.tokens.putBit(false, p[7])
+			e.tokens.putBit(v == 6, 159)
+			return
+		}
+		e.tokens.putBit(true, p[7]) // Category 2
+		extra := v - 7
+		e.tokens.putBit(extra&2 != 0, 165)
+		e.tokens.putBit(extra&1 != 0, 145)
+	default:
+		e.tokens.putBit(true, p[3])
+		e.tokens.putBit(true, p[6])
+		cat := 0 // Categories 3 to 6, which start at 11, 19, 35 and 67
+		for cat < 3 && v >= 3+(8<<(cat+1)) {
+			cat++
+		}
+		e.tokens.putBit(cat >= 2, p[8])
+		e.tokens.putBit(cat&1 != 0, p[9+(cat>>1)])
+		extra := v - (3 + (8 << cat))
+		probs := extraBitsProbs[cat]
+		for i, prob := range probs {
+			e.tokens.putBit(extra&(1<<(len(probs)-1-i)) != 0, prob)
+		}
+	}
+}
+
+// firstPartition returns the first partition of the frame, which holds the
+// frame header and the prediction modes of the macroblocks, as specified in
+// sections 9 and 19.2 of RFC 6386.
+func (e *encoder) firstPartition() []byte {
+	fp := newBoolEncoder()
+	fp.putBit(false, 128) // Color space
+	fp.putBit(false, 128) // Clamping required
+	fp.putBit(false, 128) // No segmentation
+	fp.putBit(false, 128) // Normal loop filter
+	fp.putUint(0, 6)      // Filter level 0, which turns the loop filter off
+	fp.putUint(0, 3)      // Sharpness
+	fp.putBit(false, 128) // No loop filter deltas
+	fp.putUint(0, 2)      // One partition of coefficients
+	fp.putUint(uint32(e.qIndex), 7)
+	for i := 0; i < 5; i++ {
+		fp.putBit(false, 128) // No quantizer deltas
+	}
+	fp.putBit(false, 128) // Refresh entropy probs, which only matters to video
+
+	// The default token probabilities are kept
+	for i := range tokenUpdateProbs {
+		for j := range tokenUpdateProbs[i] {
+			for k := range tokenUpdateProbs[i][j] {
+				for _, prob := range tokenUpdateProbs[i][j][k] {
+					fp.putBit(false, prob)
+				}
+			}
+		}
+	}
+
+	// Macroblocks without coefficients are flagged, with the probability
+	// that fits how many there are
+	skipped := 0
+	for _, skip := range e.skips {
+		if skip {
+			skipped++
+		}
+	}
+	skipProb := uint8(min(max((len(e.skips)-skipped)*256/len(e.skips), 1), 255))
+	fp.putBit(true, 128)
+	fp.putUint(uint32(skipProb), 8)
+
+	for i := range e.skips {
+		fp.putBit(e.skips[i], skipProb)
+		fp.putBit(true, 145) // Predicted as a whole
+		switch e.lumaModes[i] {
+		case predDC:
+			fp.putBit(false, 156)
+			fp.putBit(false, 163)
+		case predVE:
+			fp.putBit(false, 156)
+			fp.putBit(true, 163)
+		case predHE:
+			fp.putBit(true, 156)
+			fp.putBit(false, 128)
+		case predTM:
+			fp.putBit(true, 156)
+			fp.putBit(true, 128)
+		}
+		switch e.chromaModes[i] {
+		case predDC:
+			fp.putBit(false, 142)
+		case predVE:
+			fp.putBit(true, 142)
+			fp.putBit(false, 114)
+		case predHE:
+			fp.putBit(true, 142)
+			fp.putBit(true, 114)
+			fp.putBit(false, 183)
+		case predTM:
+			fp.putBit(true, 142)
+			fp.putBit(true, 114)
+			fp.putBit(true, 183)
+		}
+	}
+	return fp.bytes()
+}
@@ -0,0 +1,125 @@
+package encodewebp
+
+// The forward transforms are the integer approximations libwebp pairs with the
+// inverse transforms of section 14 of RFC 6386. The inverse transforms are
+// those of the decoder, which the encoder runs as well, so it predicts from
+// exactly the pixels the decoder will see.
+//
+// Coefficients of a 4x4 block are indexed by row, the vertical frequency, and
+// then by column: c[4*v+h].
+
+// forwardDCT returns the coefficients of the residual of a 4x4 block.
+func forwardDCT(residual *[16]int32) (coeffs [16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		d0, d1, d2, d3 := residual[4*i], residual[4*i+1], residual[4*i+2], residual[4*i+3]
+		a0, a1, a2, a3 := d0+d3, d1+d2, d1-d2, d0-d3
+		tmp[4*i] = (a0 + a1) * 8
+		tmp[4*i+1] = (a2*2217 + a3*5352 + 1812) >> 9
+		tmp[4*i+2] = (a0 - a1) * 8
+		tmp[4*i+3] = (a3*2217 - a2*5352 + 937) >> 9
+	}
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[i]+tmp[12+i], tmp[4+i]+tmp[8+i]
+		a2, a3 := tmp[4+i]-tmp[8+i], tmp[i]-tmp[12+i]
+		coeffs[i] = (a0 + a1 + 7) >> 4
+		coeffs[4+i] = (a2*2217 + a3*5352 + 12000) >> 16
+		if a3 != 0 {
+			coeffs[4+i]++
+		}
+		coeffs[8+i] = (a0 - a1 + 7) >> 4
+		coeffs[12+i] = (a3*2217 - a2*5352 + 51000) >> 16
+	}
+	return coeffs
+}
+
+// inverseDCT adds the residual the coefficients stand for to the predicted 4x4
+// block at the start of pixels, whose rows are stride apart.
+func inverseDCT(coeffs *[16]int16, pixels []uint8, stride int) {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := int32(coeffs[i]) + int32(coeffs[8+i])
+		b := int32(coeffs[i]) - int32(coeffs[8+i])
+		c := (int32(coeffs[4+i])*c2)>>16 - (int32(coeffs[12+i])*c1)>>16
+		d := (int32(coeffs[4+i])*c1)>>16 + (int32(coeffs[12+i])*c2)>>16
+		m[i][0], m[i][1], m[i][2], m[i][3] = a+d, b+c, b-c, a-d
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		row := pixels[j*stride : j*stride+4]
+		row[0] = clip8(int32(row[0]) + (a+d)>>3)
+		row[1] = clip8(int32(row[1]) + (b+c)>>3)
+		row[2] = clip8(int32(row[2]) + (b-c)>>3)
+		row[3] = clip8(int32(row[3]) + (a-d)>>3)
+	}
+}
+
+// forwardWHT returns the Walsh-Hadamard transform of the DC coefficients of
+// the 16 luma blocks of a macroblock, in raster order.
+func forwardWHT(dcs *[16]int32) (coeffs [16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		a0, a1 := dcs[4*i]+dcs[4*i+2], dcs[4*i+1]+dcs[4*i+3]
+		a2, a3 := dcs[4*i+1]-dcs[4*i+3], dcs[4*i]-dcs[4*i+2]
+		tmp[4*i] = a0 + a1
+		tmp[4*i+1] = a3 + a2
+		tmp[4*i+2] = a3 - a2
+		tmp[4*i+3] = a0 - a1
+	}
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[i]+tmp[8+i], tmp[4+i]+tmp[12+i]
+		a2, a3 := tmp[4+i]-tmp[12+i], tmp[i]-tmp[8+i]
+		coeffs[i] = (a0 + a1) >> 1
+		coeffs[4+i] = (a3 + a2) >> 1
+		coeffs[8+i] = (a3 - a2) >> 1
+		coeffs[12+i] = (a0 - a1) >> 1
+	}
+	return coeffs
+}
+
+// inverseWHT returns the DC coefficients of the 16 luma blocks of a
+// macroblock, in raster order, from their transform.
+func inverseWHT(coeffs *[16]int16) (dcs [16]int16) {
+	var m [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := int32(coeffs[i]) + int32(coeffs[12+i])
+		a1 := int32(coeffs[4+i]) + int32(coeffs[8+i])
+		a2 := int32(coeffs[4+i]) - int32(coeffs[8+i])
+		a3 := int32(coeffs[i]) - int32(coeffs[12+i])
+		m[i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[4*i] + 3
+		a0 := dc + m[4*i+3]
+		a1 := m[4*i+1] + m[4*i+2]
+		a2 := m[4*i+1] - m[4*i+2]
+		a3 := dc - m[4*i+3]
+		dcs[4*i] = int16((a0 + a1) >> 3)
+		dcs[4*i+1] = int16((a3 + a2) >> 3)
+		dcs[4*i+2] = int16((a0 - a1) >> 3)
+		dcs[4*i+3] = int16((a3 - a2) >> 3)
+	}
+	return dcs
+}
+
+// clip8 clamps v to the range of a pixel.
+func clip8(v int32) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
@@ -1,31 +1,7 @@
 package markdown2html
 
-import (
-	"bytes"
-
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
-	"github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer/html"
-)
-
+// Convert converts markdown to html with the default options: GitHub Flavored
+// Markdown, heading ids and hard wraps.
 func Convert(markdown []byte) ([]byte, error) {
-	var (
-		err error
-		buf bytes.Buffer
-	)
-
-	converter := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(),
-		),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(),
-		),
-	)
-
-	err = converter.Convert([]byte(markdown), &buf)
-
-	return buf.Bytes(), err
+	return Options{}.Convert(markdown)
 }
@@ -0,0 +1,97 @@
+package markdown2html
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// moreMarker separates the summary of a document from the rest of it.
+const moreMarker = "<!--more-->"
+
+// wordsPerMinute is the reading speed the reading time is estimated at.
+const wordsPerMinute = 200
+
+// Document is markdown converted to html, along with what templates need to
+// know about its structure.
+type Document struct {
+	HTML []byte
+	// TOC lists the top-level headings, with the headings below each as its
+	// Children.
+	TOC []Heading
+	// Summary is the html of the text before the <!--more--> marker, or of the
+	// first paragraph without one.
+	Summary     string
+	WordCount   int
+	ReadingTime int // In minutes, rounded up
+}
+
+// Heading is a heading of a document, with the id it is linked to by.
+type Heading struct {
+	Level    int
+	ID       string
+	Text     string
+	Children []Heading
+}
+
+// ConvertDocument converts markdown to html, and reads its headings, summary
+// and length from the parsed document. The <!--more--> marker is left out of
+// the html.
+func (options Options) ConvertDocument(markdown []byte) (Document, error) {
+	var document Document
+
+	converter, err := options.newMarkdown()
+	if err != nil {
+		return document, err
+	}
+
+	before, after, hasMore := bytes.Cut(markdown, []byte(moreMarker))
+	source := markdown
+	if hasMore {
+		source = append(bytes.Clone(before), after...)
+	}
+
+	root := converter.Parser().Parse(text.NewReader(source))
+	var buf bytes.Buffer
+	if err = converter.Renderer().Render(&buf, source, root); err != nil {
+		return document, err
+	}
+	document.HTML = buf.Bytes()
+	document.TOC = headingTree(collectHeadings(root, source))
+	document.WordCount = countWords(root, source)
+	document.ReadingTime = (document.WordCount + wordsPerMinute - 1) / wordsPerMinute
+
+	var summary bytes.Buffer
+	if hasMore {
+		if err = converter.Convert(before, &summary); err != nil {
+			return document, err
+		}
+	} else {
+		for node := root.FirstChild(); node != nil; node = node.NextSibling() {
+			if node.Kind() == ast.KindParagraph {
+				if err = converter.Renderer().Render(&summary, source, node); err != nil {
+					return document, err
+				}
+				break
+			}
+		}
+	}
+	document.Summary = summary.String()
+
+	return document, nil
+}
+
+// countWords returns the number of words in the text of the document, leaving
+// out code blocks and raw html.
+func countWords(root ast.Node, source []byte) int {
+	count := 0
+	_ = ast.Walk(root, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if textNode, ok := node.(*ast.Text); ok && entering {
+			count += len(strings.Fields(string(textNode.Segment.Value(source))))
+		}
+		return ast.WalkContinue, nil
+	})
+	return count
+}
@@ -0,0 +1,73 @@
+package markdown2html
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvertDocument(t *testing.T) {
+	tests := []struct {
+		testcase        string
+		markdown        string
+		wantTOC         []Heading
+		wantSummary     string
+		wantWordCount   int
+		wantReadingTime int
+	}{
+		{
+			testcase: "Headings nest below the closest lower level",
+			markdown: "# Intro\n\nFirst paragraph.\n\nSecond paragraph.\n\n## Setup *fast*\n\n### Details\n\n## Usage\n\n# End\n",
+			wantTOC: []Heading{
+				{Level: 1, ID: "intro", Text: "Intro", Children: []Heading{
+					{Level: 2, ID: "setup-fast", Text: "Setup fast", Children: []Heading{
+						{Level: 3, ID: "details", Text: "Details"},
+					}},
+					{Level: 2, ID: "usage", Text: "Usage"},
+				}},
+				{Level: 1, ID: "end", Text: "End"},
+			},
+			wantSummary:     "<p>First paragraph.</p>\n",
+			wantWordCount:   10,
+			wantReadingTime: 1,
+		},
+		{
+			testcase:        "Summary before the more marker",
+			markdown:        "One.\n\nTwo.\n\n<!--more-->\n\nThree.\n",
+			wantSummary:     "<p>One.</p>\n<p>Two.</p>\n",
+			wantWordCount:   3,
+			wantReadingTime: 1,
+		},
+		{
+			testcase:        "Code blocks are not counted",
+			markdown:        strings.Repeat("word ", 401) + "\n\n```\nnot counted\n```\n",
+			wantSummary:     "<p>" + strings.Repeat("word ", 400) + "word</p>\n",
+			wantWordCount:   401,
+			wantReadingTime: 3,
+		},
+		{
+			testcase: "Empty document",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testcase, func(t *testing.T) {
+			document, err := Options{}.ConvertDocument([]byte(test.markdown))
+			if err != nil {
+				t.Fatalf("ConvertDocument() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(document.TOC, test.wantTOC) {
+				t.Errorf("ConvertDocument() TOC = %+v, want %+v", document.TOC, test.wantTOC)
+			}
+			if document.Summary != test.wantSummary {
+				t.Errorf("ConvertDocument() Summary = %q, want %q", document.Summary, test.wantSummary)
+			}
+			if document.WordCount != test.wantWordCount || document.ReadingTime != test.wantReadingTime {
+				t.Errorf("ConvertDocument() WordCount, ReadingTime = %d, %d, want %d, %d", document.WordCount, document.ReadingTime, test.wantWordCount, test.wantReadingTime)
+			}
+			if strings.Contains(string(document.HTML), "more") {
+				t.Errorf("ConvertDocument() HTML = %q, want the more marker left out", document.HTML)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package markdown2html
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// Options configures the markdown pipeline. GitHub Flavored Markdown and heading
+// ids are always enabled; the zero value adds hard wraps and nothing else.
+type Options struct {
+	Footnotes       bool // [^1] references and their footnotes
+	DefinitionLists bool // A term line followed by ": definition" lines
+	Typographer     bool // Smart quotes, dashes and ellipses
+	TableOfContents bool // A paragraph of just [TOC] becomes a list of links to the headings
+	Attributes      bool // {#id .class key=value} after a heading sets its attributes
+	Unsafe          bool // Raw html is passed through instead of being left out
+	NoHardWraps     bool // A newline in a paragraph stays a space instead of becoming <br>
+
+	// HighlightStyle is the chroma style fenced code blocks are highlighted
+	// with, as css classes styled by the Stylesheet. Empty leaves code blocks
+	// unhighlighted.
+	HighlightStyle string
+}
+
+// Validate checks that the options name things that exist.
+func (options Options) Validate() error {
+	if options.HighlightStyle != "" {
+		if _, ok := styles.Registry[options.HighlightStyle]; !ok {
+			return fmt.Errorf("unknown highlight style %q, available are %v", options.HighlightStyle, styles.Names())
+		}
+	}
+	return nil
+}
+
+// Convert converts markdown to html.
+func (options Options) Convert(markdown []byte) ([]byte, error) {
+	document, err := options.ConvertDocument(markdown)
+	if err != nil {
+		return nil, err
+	}
+	return document.HTML, nil
+}
+
+// newMarkdown returns the goldmark converter for the options.
+func (options Options) newMarkdown() (goldmark.Markdown, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	extensions := []goldmark.Extender{extension.GFM}
+	if options.Footnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if options.DefinitionLists {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if options.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+
+	parserOptions := []parser.Option{parser.WithAutoHeadingID()}
+	if options.Attributes {
+		parserOptions = append(parserOptions, parser.WithAttribute())
+	}
+	if options.TableOfContents {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(util.Prioritized(tableOfContents{}, 100)))
+	}
+
+	var rendererOptions []renderer.Option
+	if !options.NoHardWraps {
+		rendererOptions = append(rendererOptions, html.WithHardWraps())
+	}
+	if options.Unsafe {
+		rendererOptions = append(rendererOptions, html.WithUnsafe())
+	}
+	if options.HighlightStyle != "" {
+		rendererOptions = append(rendererOptions, renderer.WithNodeRenderers(util.Prioritized(newHighlighter(options.HighlightStyle), 100)))
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(rendererOptions...),
+	), nil
+}
@@ -0,0 +1,116 @@
+package markdown2html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptionsConvert(t *testing.T) {
+	tests := []struct {
+		testcase string
+		options  Options
+		markdown string
+		contains []string
+		excludes []string
+	}{
+		{
+			testcase: "Defaults keep hard wraps and leave raw html out",
+			markdown: "one\ntwo\n\n<b>raw</b>\n",
+			contains: []string{"one<br>\ntwo", "<!-- raw HTML omitted -->"},
+		},
+		{
+			testcase: "Hard wraps off and raw html passed through",
+			options:  Options{NoHardWraps: true, Unsafe: true},
+			markdown: "one\ntwo\n\n<b>raw</b>\n",
+			contains: []string{"one\ntwo", "<b>raw</b>"},
+			excludes: []string{"<br>"},
+		},
+		{
+			testcase: "Footnotes",
+			options:  Options{Footnotes: true},
+			markdown: "Text[^1]\n\n[^1]: Note\n",
+			contains: []string{`<sup id="fnref:1">`, `<div class="footnotes" role="doc-endnotes">`},
+		},
+		{
+			testcase: "Definition lists",
+			options:  Options{DefinitionLists: true},
+			markdown: "Term\n: Definition\n",
+			contains: []string{"<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>"},
+		},
+		{
+			testcase: "Typographer",
+			options:  Options{Typographer: true},
+			markdown: "\"quoted\" -- dash...\n",
+			contains: []string{"&ldquo;quoted&rdquo; &ndash; dash&hellip;"},
+		},
+		{
+			testcase: "Attributes",
+			options:  Options{Attributes: true},
+			markdown: "# Title {#custom .big}\n",
+			contains: []string{`<h1 id="custom" class="big">Title</h1>`},
+		},
+		{
+			testcase: "Table of contents",
+			options:  Options{TableOfContents: true},
+			markdown: "[TOC]\n\n# Intro\n\n## Setup *fast*\n\n## Usage\n\n# End\n",
+			contains: []string{`<ul>
+<li><a href="#intro">Intro</a>
+<ul>
+<li><a href="#setup-fast">Setup fast</a></li>
+<li><a href="#usage">Usage</a></li>
+</ul>
+</li>
+<li><a href="#end">End</a></li>
+</ul>`},
+			excludes: []string{"[TOC]"},
+		},
+		{
+			testcase: "Table of contents marker without the option",
+			markdown: "[TOC]\n\n# Intro\n",
+			contains: []string{"<p>[TOC]</p>"},
+		},
+		{
+			testcase: "Highlighted code blocks",
+			options:  Options{HighlightStyle: "github"},
+			markdown: "```go\nfunc main() {}\n```\n\n```\nplain <text>\n```\n",
+			contains: []string{`<pre class="chroma"><code><span class="line"><span class="cl"><span class="kd">func</span>`, "plain &lt;text&gt;"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testcase, func(t *testing.T) {
+			html, err := test.options.Convert([]byte(test.markdown))
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+			for _, want := range test.contains {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("Convert() = %q, want it to contain %q", html, want)
+				}
+			}
+			for _, unwanted := range test.excludes {
+				if strings.Contains(string(html), unwanted) {
+					t.Errorf("Convert() = %q, want it not to contain %q", html, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestOptionsStylesheet(t *testing.T) {
+	css, err := Options{HighlightStyle: "monokai"}.Stylesheet()
+	if err != nil {
+		t.Fatalf("Stylesheet() unexpected error: %v", err)
+	}
+	if !strings.Contains(string(css), ".chroma .kd {") {
+		t.Errorf("Stylesheet() = %q, want the rules for the highlight classes", css)
+	}
+
+	if css, err := (Options{}).Stylesheet(); err != nil || css != nil {
+		t.Errorf("Stylesheet() without a style = %q, %v, want nothing", css, err)
+	}
+
+	if _, err := (Options{HighlightStyle: "nope"}).Stylesheet(); err == nil || !strings.Contains(err.Error(), `unknown highlight style "nope"`) {
+		t.Errorf("Stylesheet() error = %v, want an unknown style", err)
+	}
+}
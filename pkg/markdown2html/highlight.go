@@ -0,0 +1,73 @@
+package markdown2html
+
+import (
+	"bytes"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// highlighter renders fenced code blocks highlighted by chroma, with css
+// classes instead of inline styles.
+type highlighter struct {
+	style     *chroma.Style
+	formatter *chromahtml.Formatter
+}
+
+func newHighlighter(styleName string) *highlighter {
+	return &highlighter{
+		style:     styles.Get(styleName),
+		formatter: chromahtml.New(chromahtml.WithClasses(true)),
+	}
+}
+
+func (h *highlighter) RegisterFuncs(registerer renderer.NodeRendererFuncRegisterer) {
+	registerer.Register(ast.KindFencedCodeBlock, h.renderFencedCodeBlock)
+}
+
+func (h *highlighter) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	block := node.(*ast.FencedCodeBlock)
+
+	var code bytes.Buffer
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	lexer := lexers.Get(string(block.Language(source)))
+	if lexer == nil { // No or an unknown language, so the code is only wrapped like highlighted code
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code.String())
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	if err = h.formatter.Format(w, h.style, iterator); err != nil {
+		return ast.WalkStop, err
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// Stylesheet returns the css for the classes of code highlighted in the
+// HighlightStyle, or nothing if highlighting is disabled.
+func (options Options) Stylesheet() ([]byte, error) {
+	if options.HighlightStyle == "" {
+		return nil, nil
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&buf, styles.Get(options.HighlightStyle))
+	return buf.Bytes(), err
+}
@@ -0,0 +1,109 @@
+package markdown2html
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// tocMarker is the paragraph that is replaced by the table of contents.
+const tocMarker = "[TOC]"
+
+// tableOfContents replaces every [TOC] paragraph with a nested list of links
+// to the headings of the document.
+type tableOfContents struct{}
+
+func (tableOfContents) Transform(document *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var markers []ast.Node
+	for node := document.FirstChild(); node != nil; node = node.NextSibling() {
+		if paragraph, ok := node.(*ast.Paragraph); ok && string(bytes.TrimSpace(paragraph.Lines().Value(source))) == tocMarker {
+			markers = append(markers, paragraph)
+		}
+	}
+	if len(markers) == 0 {
+		return
+	}
+
+	headings := collectHeadings(document, source)
+	for _, marker := range markers {
+		if len(headings) > 0 {
+			document.InsertBefore(document, marker, headingList(headingTree(headings)))
+		}
+		document.RemoveChild(document, marker)
+	}
+}
+
+// collectHeadings returns the headings of document, in order.
+func collectHeadings(document ast.Node, source []byte) []Heading {
+	var headings []Heading
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		headings = append(headings, Heading{Level: heading.Level, ID: string(idBytes), Text: string(headingText(heading, source))})
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// headingText returns the plain text of a heading, without its markup.
+func headingText(node ast.Node, source []byte) []byte {
+	var buf bytes.Buffer
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child := child.(type) {
+		case *ast.Text:
+			buf.Write(child.Segment.Value(source))
+		case *ast.String:
+			buf.Write(child.Value)
+		default:
+			buf.Write(headingText(child, source))
+		}
+	}
+	return buf.Bytes()
+}
+
+// headingTree nests each heading under the closest heading before it with a
+// lower level. Headings without one are at the top.
+func headingTree(headings []Heading) []Heading {
+	var tree []Heading
+	for len(headings) > 0 {
+		heading := headings[0]
+		end := 1
+		for end < len(headings) && headings[end].Level > heading.Level {
+			end++
+		}
+		heading.Children = headingTree(headings[1:end])
+		tree = append(tree, heading)
+		headings = headings[end:]
+	}
+	return tree
+}
+
+// headingList returns a heading tree as a nested list of links.
+func headingList(headings []Heading) *ast.List {
+	list := ast.NewList('-')
+	list.IsTight = true
+
+	for _, heading := range headings {
+		link := ast.NewLink()
+		link.Destination = []byte("#" + heading.ID)
+		link.AppendChild(link, ast.NewString([]byte(heading.Text)))
+		textBlock := ast.NewTextBlock() // Like the items of a tight list
+		textBlock.AppendChild(textBlock, link)
+		item := ast.NewListItem(0)
+		item.AppendChild(item, textBlock)
+		if len(heading.Children) > 0 {
+			item.AppendChild(item, headingList(heading.Children))
+		}
+		list.AppendChild(list, item)
+	}
+
+	return list
+}
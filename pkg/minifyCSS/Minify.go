@@ -0,0 +1,125 @@
+package minifycss
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Minify returns s with comments removed and whitespace collapsed to what the
+// stylesheet needs. Strings and unquoted url() arguments are kept verbatim,
+// and a comment starting with /*! is kept, as is the convention for licenses.
+//
+// Whitespace is only removed where it can never be significant: around
+// braces, semicolons, commas and the child and sibling combinators, and after
+// a colon or an opening parenthesis. A space before a colon is kept, since
+// `a :hover` and `a:hover` are different selectors, and so are the spaces
+// around + and -, which calc() requires.
+func Minify(s string) string {
+	var (
+		out          = make([]byte, 0, len(s))
+		pendingSpace bool
+	)
+
+	// flush writes the pending space before next, unless it cannot be
+	// significant between the last written byte and next, or follows a comment.
+	flush := func(next byte) {
+		if !pendingSpace {
+			return
+		}
+		pendingSpace = false
+		if len(out) == 0 || strings.IndexByte("{};,>~:(", out[len(out)-1]) >= 0 || strings.IndexByte("{};,>~)", next) >= 0 || bytes.HasSuffix(out, []byte("*/")) {
+			return
+		}
+		out = append(out, ' ')
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case strings.HasPrefix(s[i:], "/*"):
+			next := len(s) // Unterminated, so it runs to the end
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				next = i + 2 + end + 2
+			}
+			if strings.HasPrefix(s[i:], "/*!") {
+				flush('/')
+				out = append(out, s[i:next]...)
+			} else {
+				pendingSpace = len(out) > 0 // A comment separates tokens like whitespace does
+			}
+			i = next
+
+		case isSpace(c):
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			pendingSpace = len(out) > 0
+
+		case c == '"' || c == '\'':
+			end := stringEnd(s, i)
+			flush(c)
+			out = append(out, s[i:end]...)
+			i = end
+
+		case hasPrefixFold(s[i:], "url(") && (len(out) == 0 || !isIdent(out[len(out)-1])):
+			end := urlEnd(s, i+len("url("))
+			flush(c)
+			out = append(out, s[i:end]...)
+			i = end
+
+		default:
+			if c == '}' && len(out) > 0 && out[len(out)-1] == ';' { // The last declaration needs no semicolon
+				out = out[:len(out)-1]
+			}
+			flush(c)
+			out = append(out, c)
+			i++
+		}
+	}
+
+	return string(out)
+}
+
+// stringEnd returns the index after the string literal starting at i.
+func stringEnd(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case quote, '\n':
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// urlEnd returns the index after the closing parenthesis of a url( whose
+// argument starts at i. A quoted argument is skipped as a string, so a
+// parenthesis inside it does not end the url.
+func urlEnd(s string, i int) int {
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '"', '\'':
+			j = stringEnd(s, j) - 1
+		case '\\':
+			j++
+		case ')':
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isIdent(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func hasPrefixFold(s string, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,54 @@
+package minifycss
+
+import "testing"
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "whitespace and last semicolon are dropped",
+			in:   "body {\n  color: red;\n  margin: 0 auto;\n}\n",
+			want: "body{color:red;margin:0 auto}",
+		},
+		{
+			name: "comments are removed, license comments kept",
+			in:   "/*! MIT */\n/* layout */\na { b: c }",
+			want: "/*! MIT */a{b:c}",
+		},
+		{
+			name: "combinators",
+			in:   ".a > .b ~ .c , .d .e { x: y }",
+			want: ".a>.b~.c,.d .e{x:y}",
+		},
+		{
+			name: "space before a pseudo-class is kept",
+			in:   "a :hover, a:focus { x: y }",
+			want: "a :hover,a:focus{x:y}",
+		},
+		{
+			name: "calc keeps its operator spaces",
+			in:   "a { width: calc( 100% - 2px ) }",
+			want: "a{width:calc(100% - 2px)}",
+		},
+		{
+			name: "strings and urls are kept",
+			in:   "a { content: \"a  ;  b\"; background: url( data:x;y  z ) }",
+			want: "a{content:\"a  ;  b\";background:url( data:x;y  z )}",
+		},
+		{
+			name: "at-rules",
+			in:   "@media (min-width: 10px) {\n  a { b: c; }\n}",
+			want: "@media (min-width:10px){a{b:c}}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Minify(tt.in); got != tt.want {
+				t.Errorf("Minify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package minifyhtml
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	minifycss "github.com/thetillhoff/temingo/pkg/minifyCSS"
+	minifyjs "github.com/thetillhoff/temingo/pkg/minifyJS"
+)
+
+// Minify returns the HTML s with comments removed and whitespace collapsed.
+// Runs of whitespace in text become a single space, and whitespace next to a
+// block-level tag is dropped, since it does not render. The content of pre and
+// textarea elements is kept verbatim, inline stylesheets and scripts are
+// minified as CSS and JavaScript, and inline JSON is compacted. A script of any
+// other type is kept verbatim, and so are conditional comments.
+//
+// Unlike the beautifier, it works on the token stream rather than the parsed
+// document, so it never adds the html, head and body elements a parser would.
+func Minify(s string) string {
+	var (
+		buf         bytes.Buffer
+		tokenizer   = html.NewTokenizer(strings.NewReader(s))
+		pendingText []byte // Collapsed text, written once the next token is known
+		afterBlock  = true // The last token written was a block-level tag, or none was
+		rawDepth    int    // Inside a pre or textarea element
+	)
+
+	flushText := func(beforeBlock bool) {
+		text := pendingText
+		pendingText = nil
+		if afterBlock {
+			text = bytes.TrimLeft(text, " ")
+		}
+		if beforeBlock {
+			text = bytes.TrimRight(text, " ")
+		}
+		buf.Write(text)
+		if len(text) > 0 {
+			afterBlock = false
+		}
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			if tokenizer.Err() != io.EOF {
+				return s // Keep what cannot be read as it is
+			}
+			flushText(true)
+			return buf.String()
+		}
+		raw := tokenizer.Raw()
+
+		switch tokenType {
+		case html.TextToken:
+			if rawDepth > 0 {
+				buf.Write(raw)
+				afterBlock = false
+				continue
+			}
+			pendingText = append(pendingText, collapseSpace(raw, len(pendingText) > 0 && pendingText[len(pendingText)-1] == ' ')...)
+
+		case html.CommentToken:
+			if bytes.HasPrefix(raw, []byte("<!--[if")) || bytes.HasPrefix(raw, []byte("<![endif]")) {
+				flushText(false)
+				buf.Write(raw)
+				afterBlock = false
+			}
+
+		case html.DoctypeToken:
+			flushText(true)
+			buf.Write(raw)
+			afterBlock = true
+
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			block := isBlock(token.DataAtom)
+			if rawDepth > 0 && !isRaw(token.DataAtom) {
+				buf.Write(raw)
+				continue
+			}
+			flushText(block)
+			writeTag(&buf, token)
+			afterBlock = block
+
+			switch {
+			case tokenType == html.StartTagToken && isRaw(token.DataAtom):
+				rawDepth++
+			case tokenType == html.EndTagToken && isRaw(token.DataAtom):
+				rawDepth = max(rawDepth-1, 0)
+			}
+
+			if tokenType == html.StartTagToken && (token.DataAtom == atom.Script || token.DataAtom == atom.Style) {
+				scriptType := attribute(token, "type")
+				if token.DataAtom == atom.Style {
+					scriptType = "text/css"
+				}
+				if tokenizer.Next() == html.TextToken { // Script and style content is a single raw text token
+					buf.WriteString(minifyInline(string(tokenizer.Text()), scriptType))
+				} else if tokenizer.Err() == nil {
+					end := tokenizer.Token()
+					writeTag(&buf, end)
+				}
+			}
+		}
+	}
+}
+
+// minifyInline minifies the content of a script or style element by its type.
+func minifyInline(content string, scriptType string) string {
+	switch strings.ToLower(strings.TrimSpace(scriptType)) {
+	case "text/css":
+		return minifycss.Minify(content)
+	case "", "text/javascript", "application/javascript", "module":
+		return minifyjs.Minify(content)
+	case "application/json", "application/ld+json", "importmap", "speculationrules":
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, []byte(content)); err == nil {
+			return compacted.String()
+		}
+	}
+	return content
+}
+
+// writeTag writes a start, end or self-closing tag with its attributes. Empty
+// values are left out, as HTML allows.
+func writeTag(buf *bytes.Buffer, token html.Token) {
+	buf.WriteByte('<')
+	if token.Type == html.EndTagToken {
+		buf.WriteByte('/')
+	}
+	buf.WriteString(token.Data)
+	for _, a := range token.Attr {
+		buf.WriteByte(' ')
+		if a.Namespace != "" {
+			buf.WriteString(a.Namespace + ":")
+		}
+		buf.WriteString(a.Key)
+		if a.Val == "" {
+			continue
+		}
+		buf.WriteString(`="` + strings.ReplaceAll(strings.ReplaceAll(a.Val, "&", "&amp;"), `"`, "&#34;") + `"`)
+	}
+	if token.Type == html.SelfClosingTagToken {
+		buf.WriteString("/")
+	}
+	buf.WriteByte('>')
+}
+
+// attribute returns the value of the attribute key of token, or "".
+func attribute(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// collapseSpace collapses every run of whitespace in text to a single space.
+// afterSpace drops a leading space, for text continuing after one.
+func collapseSpace(text []byte, afterSpace bool) []byte {
+	out := make([]byte, 0, len(text))
+	space := afterSpace
+	for _, c := range text {
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' {
+			if !space {
+				out = append(out, ' ')
+				space = true
+			}
+			continue
+		}
+		out = append(out, c)
+		space = false
+	}
+	return out
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.Address, atom.Article, atom.Aside, atom.Blockquote,
+		atom.Body, atom.Canvas, atom.Dd, atom.Details, atom.Dialog,
+		atom.Div, atom.Dl, atom.Dt, atom.Fieldset, atom.Figcaption,
+		atom.Figure, atom.Footer, atom.Form,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Head, atom.Header, atom.Hr, atom.Html,
+		atom.Legend, atom.Li, atom.Main, atom.Menu, atom.Nav, atom.Ol,
+		atom.P, atom.Pre, atom.Section,
+		atom.Summary, atom.Table, atom.Tbody, atom.Td, atom.Tfoot,
+		atom.Th, atom.Thead, atom.Title, atom.Tr, atom.Ul:
+		return true
+	}
+	return false
+}
+
+func isRaw(a atom.Atom) bool {
+	switch a {
+	case atom.Pre, atom.Textarea:
+		return true
+	}
+	return false
+}
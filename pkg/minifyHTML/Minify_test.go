@@ -0,0 +1,84 @@
+package minifyhtml
+
+import "testing"
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "whitespace between blocks is dropped",
+			in:   "<html>\n<head>\n  <title> Test </title>\n</head>\n<body>\n  <h1>Hello</h1>\n</body>\n</html>\n",
+			want: "<html><head><title>Test</title></head><body><h1>Hello</h1></body></html>",
+		},
+		{
+			name: "whitespace between inline elements collapses to a space",
+			in:   "<p>Hello\n   <b>big</b>   <i>world</i> </p>",
+			want: "<p>Hello <b>big</b> <i>world</i></p>",
+		},
+		{
+			name: "whitespace around inline scripts collapses to a space",
+			in:   "<p>foo\n  <script>x()</script>\n  bar <noscript>no js</noscript> baz</p>",
+			want: "<p>foo <script>x()</script> bar <noscript>no js</noscript> baz</p>",
+		},
+		{
+			name: "comments are removed, conditional comments kept",
+			in:   "<div><!-- note --><!--[if IE]><p>old</p><![endif]--></div>",
+			want: "<div><!--[if IE]><p>old</p><![endif]--></div>",
+		},
+		{
+			name: "pre and textarea keep their whitespace",
+			in:   "<div>\n  <pre>\n  a  <b> b </b>\n</pre>\n  <textarea>  x\n  y</textarea>\n</div>",
+			want: "<div><pre>\n  a  <b> b </b>\n</pre><textarea>  x\n  y</textarea></div>",
+		},
+		{
+			name: "entities stay escaped",
+			in:   "<p>&lt;div&gt; &amp;amp;</p>",
+			want: "<p>&lt;div&gt; &amp;amp;</p>",
+		},
+		{
+			name: "attributes are kept and requoted",
+			in:   "<a  href='/a?b=1&amp;c=2'\n   title=\"say &quot;hi&quot;\"  hidden>x</a>",
+			want: "<a href=\"/a?b=1&amp;c=2\" title=\"say &#34;hi&#34;\" hidden>x</a>",
+		},
+		{
+			name: "inline style is minified",
+			in:   "<style>\n  .a > .b {\n    color: red;\n  }\n</style>",
+			want: "<style>.a>.b{color:red}</style>",
+		},
+		{
+			name: "inline script is minified",
+			in:   "<script>\n  // greet\n  if (a < b) {\n    alert(\"x  y\");\n  }\n</script>",
+			want: "<script>if(a<b){alert(\"x  y\");}</script>",
+		},
+		{
+			name: "inline json is compacted",
+			in:   "<script type=\"application/ld+json\">\n{ \"@type\": \"Person\" }\n</script>",
+			want: "<script type=\"application/ld+json\">{\"@type\":\"Person\"}</script>",
+		},
+		{
+			name: "scripts of other types are kept",
+			in:   "<script type=\"text/template\">\n  <p> {{ x }} </p>\n</script>",
+			want: "<script type=\"text/template\">\n  <p> {{ x }} </p>\n</script>",
+		},
+		{
+			name: "empty script",
+			in:   "<script src=\"/a.js\"></script>",
+			want: "<script src=\"/a.js\"></script>",
+		},
+		{
+			name: "doctype is kept",
+			in:   "<!DOCTYPE html>\n<html></html>",
+			want: "<!DOCTYPE html><html></html>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Minify(tt.in); got != tt.want {
+				t.Errorf("Minify(%q) =\n%q\nwant\n%q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package minifyjs
+
+import (
+	"strings"
+)
+
+// Minify returns s with comments removed and whitespace collapsed, without
+// renaming or rewriting anything. String, template and regular expression
+// literals are kept verbatim, and a comment starting with /*! is kept, as is
+// the convention for licenses.
+//
+// A line break is kept wherever automatic semicolon insertion could depend on
+// it - only after an opening bracket, comma or semicolon, or before a closing
+// bracket, comma or semicolon, is it removed. Other whitespace becomes a single
+// space where the tokens on either side would otherwise merge, like two
+// identifiers or `a - -b`.
+func Minify(s string) string {
+	var (
+		out            = make([]byte, 0, len(s))
+		pendingSpace   bool
+		pendingNewline bool
+		lastToken      string // The last identifier, keyword or punctuator written
+	)
+
+	// flush writes the pending whitespace before next, if it is significant
+	// between the last written byte and next.
+	flush := func(next byte) {
+		if len(out) == 0 {
+			pendingSpace, pendingNewline = false, false
+			return
+		}
+		last := out[len(out)-1]
+		switch {
+		case pendingNewline && strings.IndexByte("{([,;", last) < 0 && strings.IndexByte("})],;", next) < 0:
+			out = append(out, '\n')
+		case (pendingSpace || pendingNewline) && mergesWith(last, next):
+			out = append(out, ' ')
+		}
+		pendingSpace, pendingNewline = false, false
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case strings.HasPrefix(s[i:], "//"):
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end // The line break itself is kept as whitespace
+			}
+
+		case strings.HasPrefix(s[i:], "/*"):
+			next := len(s) // Unterminated, so it runs to the end
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				next = i + 2 + end + 2
+			}
+			if strings.HasPrefix(s[i:], "/*!") {
+				flush('/')
+				out = append(out, s[i:next]...)
+			} else if strings.Contains(s[i:next], "\n") {
+				pendingNewline = true // A multi-line comment counts as a line break for semicolon insertion
+			} else {
+				pendingSpace = true
+			}
+			i = next
+
+		case c == '\n' || c == '\r':
+			pendingNewline = true
+			i++
+
+		case c == ' ' || c == '\t' || c == '\v' || c == '\f':
+			pendingSpace = true
+			i++
+
+		case c == '"' || c == '\'':
+			end := stringEnd(s, i)
+			flush(c)
+			out = append(out, s[i:end]...)
+			lastToken = "string"
+			i = end
+
+		case c == '`':
+			end := templateEnd(s, i)
+			flush(c)
+			out = append(out, s[i:end]...)
+			lastToken = "string"
+			i = end
+
+		case c == '/' && regexAllowedAfter(lastToken):
+			end := regexEnd(s, i)
+			flush(c)
+			out = append(out, s[i:end]...)
+			lastToken = "regex"
+			i = end
+
+		case isIdent(c):
+			start := i
+			for i < len(s) && isIdent(s[i]) {
+				i++
+			}
+			flush(c)
+			out = append(out, s[start:i]...)
+			lastToken = s[start:i]
+
+		default:
+			flush(c)
+			if (c == '+' || c == '-') && lastToken == string(c) && out[len(out)-1] == c {
+				lastToken += string(c) // An increment or decrement, after which a slash divides
+			} else {
+				lastToken = string(c)
+			}
+			out = append(out, c)
+			i++
+		}
+	}
+
+	return string(out)
+}
+
+// mergesWith reports whether next, written right after last, would merge with
+// it into a different token.
+func mergesWith(last byte, next byte) bool {
+	switch {
+	case isIdent(last) && isIdent(next):
+		return true
+	case last == '+' && next == '+', last == '-' && next == '-':
+		return true
+	case last == '/' && (next == '/' || next == '*'): // Would start a comment
+		return true
+	case last >= '0' && last <= '9' && next == '.': // Would become a decimal point, as in `1 .toString()`
+		return true
+	case isIdent(last) && next == '\\', last == '\\' && isIdent(next): // Unicode escapes in identifiers
+		return true
+	}
+	return false
+}
+
+// regexAllowedAfter reports whether a slash after token starts a regular
+// expression rather than a division.
+func regexAllowedAfter(token string) bool {
+	switch token {
+	case "", "(", ",", "=", ":", "[", "!", "&", "|", "?", "{", "}", ";", "~", "+", "-", "*", "%", "<", ">", "^",
+		"return", "typeof", "case", "do", "else", "in", "instanceof", "new", "delete", "void", "throw", "yield", "await":
+		return true
+	}
+	return false
+}
+
+// stringEnd returns the index after the string literal starting at i.
+func stringEnd(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case quote, '\n':
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// templateEnd returns the index after the template literal starting at i,
+// skipping over the code of its substitutions.
+func templateEnd(s string, i int) int {
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '`':
+			return j + 1
+		case strings.HasPrefix(s[j:], "${"):
+			j = substitutionEnd(s, j+2) - 1
+		}
+	}
+	return len(s)
+}
+
+// substitutionEnd returns the index after the brace closing a template
+// substitution whose code starts at i.
+func substitutionEnd(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '"', '\'':
+			j = stringEnd(s, j) - 1
+		case '`':
+			j = templateEnd(s, j) - 1
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return j + 1
+			}
+			depth--
+		}
+	}
+	return len(s)
+}
+
+// regexEnd returns the index after the regular expression literal starting at
+// i, including its flags. A slash inside a character class does not end it.
+func regexEnd(s string, i int) int {
+	inClass := false
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return j // Not a regular expression after all; keep the rest as it is
+		case '/':
+			if inClass {
+				continue
+			}
+			j++
+			for j < len(s) && isIdent(s[j]) {
+				j++
+			}
+			return j
+		}
+	}
+	return len(s)
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,59 @@
+package minifyjs
+
+import "testing"
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "whitespace around punctuation is dropped",
+			in:   "function add ( a, b ) {\n  return a + b;\n}\n",
+			want: "function add(a,b){return a+b;}",
+		},
+		{
+			name: "line breaks that may end a statement are kept",
+			in:   "let a = 1\nlet b = 2\n",
+			want: "let a=1\nlet b=2",
+		},
+		{
+			name: "comments are removed, license comments kept",
+			in:   "/*! MIT */\n// note\nx = 1; /* inline */ y = 2;",
+			want: "/*! MIT */\nx=1;y=2;",
+		},
+		{
+			name: "a multi-line comment counts as a line break",
+			in:   "a = b /*\n*/ c()",
+			want: "a=b\nc()",
+		},
+		{
+			name: "tokens that would merge keep a space",
+			in:   "a - -b; c + +d; x = 1 .toString(); return  typeof y",
+			want: "a- -b;c+ +d;x=1 .toString();return typeof y",
+		},
+		{
+			name: "strings and template literals are kept",
+			in:   "s = 'a  //  b' + \"c /* d */\" + `e  ${ f( \"}\" ) }  g`",
+			want: "s='a  //  b'+\"c /* d */\"+`e  ${ f( \"}\" ) }  g`",
+		},
+		{
+			name: "regular expressions are kept",
+			in:   "r = / a[/]  b /g.test( s )",
+			want: "r=/ a[/]  b /g.test(s)",
+		},
+		{
+			name: "division is not a regular expression",
+			in:   "x = a / b / c; y = i++ / 2",
+			want: "x=a/b/c;y=i++/2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Minify(tt.in); got != tt.want {
+				t.Errorf("Minify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
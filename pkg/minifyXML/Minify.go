@@ -0,0 +1,140 @@
+package minifyxml
+
+import (
+	"regexp"
+	"strings"
+)
+
+// spacePreserveRe matches an xml:space attribute asking for whitespace to be
+// preserved.
+var spacePreserveRe = regexp.MustCompile(`\bxml:space\s*=\s*["']preserve["']`)
+
+// Minify returns the XML or SVG document s with comments removed, the
+// whitespace-only text between elements dropped and the whitespace inside tags
+// collapsed. Text with content, CDATA sections, processing instructions and
+// attribute values are kept verbatim, and so is everything inside an element
+// with xml:space="preserve".
+func Minify(s string) string {
+	var (
+		out      = make([]byte, 0, len(s))
+		preserve []bool // One per open element, whether it preserves whitespace
+	)
+
+	preserving := func() bool {
+		return len(preserve) > 0 && preserve[len(preserve)-1]
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			end := indexFrom(s, i+4, "-->", 3)
+			if preserving() {
+				out = append(out, s[i:end]...)
+			}
+			i = end
+
+		case strings.HasPrefix(s[i:], "<![CDATA["):
+			end := indexFrom(s, i, "]]>", 3)
+			out = append(out, s[i:end]...)
+			i = end
+
+		case strings.HasPrefix(s[i:], "<?"), strings.HasPrefix(s[i:], "<!"):
+			end := indexFrom(s, i, ">", 1)
+			if s[i+1] == '!' && strings.Contains(s[i:end], "[") { // A doctype with an internal subset
+				end = indexFrom(s, i, "]>", 2)
+			}
+			out = append(out, s[i:end]...)
+			i = end
+
+		case s[i] == '<':
+			end := tagEnd(s, i)
+			tag := s[i:end]
+			out = append(out, collapseTag(tag)...)
+			switch {
+			case strings.HasPrefix(tag, "</"):
+				if len(preserve) > 0 {
+					preserve = preserve[:len(preserve)-1]
+				}
+			case !strings.HasSuffix(tag, "/>"):
+				preserve = append(preserve, preserving() || spacePreserveRe.MatchString(tag))
+			}
+			i = end
+
+		default:
+			end := strings.IndexByte(s[i:], '<')
+			if end < 0 {
+				end = len(s)
+			} else {
+				end += i
+			}
+			if text := s[i:end]; preserving() || strings.TrimSpace(text) != "" {
+				out = append(out, text...)
+			}
+			i = end
+		}
+	}
+
+	return string(out)
+}
+
+// indexFrom returns the index after the first end after from, or len(s) if
+// there is none.
+func indexFrom(s string, from int, end string, length int) int {
+	if index := strings.Index(s[from:], end); index >= 0 {
+		return from + index + length
+	}
+	return len(s)
+}
+
+// tagEnd returns the index after the tag starting at i. A > inside a quoted
+// attribute value does not end it.
+func tagEnd(s string, i int) int {
+	var quote byte
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case quote != 0:
+			if s[j] == quote {
+				quote = 0
+			}
+		case s[j] == '"' || s[j] == '\'':
+			quote = s[j]
+		case s[j] == '>':
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// collapseTag collapses the whitespace between the attributes of a tag to a
+// single space, and drops it before the end of the tag.
+func collapseTag(tag string) string {
+	var (
+		out   = make([]byte, 0, len(tag))
+		quote byte
+		space bool
+	)
+
+	for j := 0; j < len(tag); j++ {
+		c := tag[j]
+		switch {
+		case quote != 0:
+			out = append(out, c)
+			if c == quote {
+				quote = 0
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+		default:
+			if space && c != '>' && c != '/' && c != '=' && out[len(out)-1] != '=' {
+				out = append(out, ' ')
+			}
+			space = false
+			if c == '"' || c == '\'' {
+				quote = c
+			}
+			out = append(out, c)
+		}
+	}
+
+	return string(out)
+}
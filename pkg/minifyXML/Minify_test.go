@@ -0,0 +1,44 @@
+package minifyxml
+
+import "testing"
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "whitespace between elements is dropped",
+			in:   "<?xml version=\"1.0\"?>\n<root>\n  <a>text</a>\n  <b/>\n</root>\n",
+			want: "<?xml version=\"1.0\"?><root><a>text</a><b/></root>",
+		},
+		{
+			name: "whitespace inside tags collapses",
+			in:   "<svg  xmlns=\"http://www.w3.org/2000/svg\"\n     viewBox=\"0 0  10 10\" >\n  <path d=\"M0  0\" />\n</svg>",
+			want: "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0  10 10\"><path d=\"M0  0\"/></svg>",
+		},
+		{
+			name: "comments are removed, cdata kept",
+			in:   "<a><!-- note --><![CDATA[ x  <y> ]]></a>",
+			want: "<a><![CDATA[ x  <y> ]]></a>",
+		},
+		{
+			name: "text with content is kept",
+			in:   "<text> Hello  world </text>",
+			want: "<text> Hello  world </text>",
+		},
+		{
+			name: "xml:space preserve keeps everything",
+			in:   "<a><b xml:space=\"preserve\">\n  <c/> <!-- x -->\n</b>\n</a>",
+			want: "<a><b xml:space=\"preserve\">\n  <c/> <!-- x -->\n</b></a>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Minify(tt.in); got != tt.want {
+				t.Errorf("Minify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package prettifycss
+
+import (
+	"errors"
+	"strings"
+)
+
+var errUnbalanced = errors.New("unbalanced stylesheet")
+
+// Format returns the stylesheet s with one declaration per line, consistent
+// 2-space indentation and a blank line between top-level rules.
+// Returns s unchanged on parse error or if s is empty.
+//
+// Strings, url() arguments and comments are kept verbatim. Whitespace is
+// collapsed to a single space, a space is put after each colon of a
+// declaration and after each comma, and the child and sibling combinators get
+// a space on either side.
+func Format(s string) string {
+	return FormatWithPrefix(s, "")
+}
+
+// FormatWithPrefix is Format, with prefix in front of every line, as for a
+// stylesheet inside an indented <style> element. The second and later lines of
+// a comment are left as they are.
+func FormatWithPrefix(s string, prefix string) string {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	formatted, err := format(s, prefix)
+	if err != nil {
+		return s
+	}
+	return formatted
+}
+
+func format(s string, prefix string) (string, error) {
+	var (
+		out   strings.Builder
+		seg   segment // The selector, declaration or at-rule being read
+		depth int
+		blank bool // The next top-level line is preceded by a blank line
+	)
+
+	// writeLine writes line at the current depth. A top-level line after a
+	// rule is preceded by a blank line, while a comment sticks to what follows.
+	writeLine := func(line string, comment bool) {
+		if depth == 0 {
+			if blank {
+				out.WriteByte('\n')
+			}
+			blank = !comment
+		}
+		out.WriteString(prefix + pad(depth) + line + "\n")
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return "", errors.New("unterminated comment")
+			}
+			comment := s[i : i+2+end+2]
+			if seg.empty() {
+				writeLine(comment, true)
+			} else {
+				seg.writeVerbatim(comment)
+			}
+			i += 2 + end + 2
+
+		case c == '"' || c == '\'':
+			end, ok := stringEnd(s, i)
+			if !ok {
+				return "", errors.New("unterminated string")
+			}
+			seg.writeVerbatim(s[i:end])
+			i = end
+
+		case hasPrefixFold(s[i:], "url(") && (i == 0 || !isIdent(s[i-1])):
+			end, ok := urlEnd(s, i+len("url("))
+			if !ok {
+				return "", errors.New("unterminated url")
+			}
+			seg.writeVerbatim(s[i:end])
+			i = end
+
+		case c == '{':
+			writeLine(seg.selector()+" {", false)
+			seg = segment{}
+			depth++
+			i++
+
+		case c == ';':
+			if !seg.empty() {
+				writeLine(seg.declaration(depth)+";", false)
+			}
+			seg = segment{}
+			i++
+
+		case c == '}':
+			if !seg.empty() {
+				writeLine(seg.declaration(depth)+";", false)
+			}
+			seg = segment{}
+			depth--
+			if depth < 0 {
+				return "", errUnbalanced
+			}
+			if written := out.String(); strings.HasSuffix(written, " {\n") { // An empty block stays on one line
+				out.Reset()
+				out.WriteString(written[:len(written)-1] + "}\n")
+			} else {
+				out.WriteString(prefix + pad(depth) + "}\n")
+			}
+			i++
+
+		default:
+			seg.writeByte(c)
+			i++
+		}
+	}
+
+	if depth != 0 {
+		return "", errUnbalanced
+	}
+	if !seg.empty() { // Trailing text without a semicolon, like a lone at-rule
+		writeLine(seg.declaration(depth), false)
+	}
+	return out.String(), nil
+}
+
+// segment collects the text between two of `{`, `}` and `;`, with its
+// whitespace collapsed.
+type segment struct {
+	text       strings.Builder
+	space      bool // Whitespace is pending
+	firstColon int  // Index after the first colon outside parentheses and brackets, or 0 for none
+	nesting    int  // Parentheses and brackets open
+}
+
+func (seg *segment) empty() bool {
+	return seg.text.Len() == 0
+}
+
+func (seg *segment) writeVerbatim(piece string) {
+	seg.flushSpace()
+	seg.text.WriteString(piece)
+}
+
+func (seg *segment) writeByte(c byte) {
+	if isSpace(c) {
+		seg.space = !seg.empty()
+		return
+	}
+	switch c {
+	case '(', '[':
+		seg.nesting++
+	case ')', ']':
+		seg.nesting = max(seg.nesting-1, 0)
+	case ':':
+		if seg.nesting == 0 && seg.firstColon == 0 {
+			seg.flushSpace()
+			seg.firstColon = seg.text.Len() + 1
+			seg.text.WriteByte(c)
+			return
+		}
+	}
+	seg.flushSpace()
+	seg.text.WriteByte(c)
+}
+
+func (seg *segment) flushSpace() {
+	if seg.space {
+		seg.text.WriteByte(' ')
+		seg.space = false
+	}
+}
+
+// selector returns the segment as the prelude of a block: a selector list, or
+// an at-rule like @media.
+func (seg *segment) selector() string {
+	text := seg.text.String()
+	if hasPrefixFold(text, "@media") || hasPrefixFold(text, "@container") {
+		return spaceAfterColons(spaceAfterCommas(text))
+	}
+	if strings.HasPrefix(text, "@") {
+		return spaceAfterCommas(text)
+	}
+	return spaceCombinators(spaceAfterCommas(text))
+}
+
+// declaration returns the segment as a declaration, with a single space after
+// its colon. At the top level, where there are no declarations, it is an
+// at-rule like @import and only its commas are spaced.
+func (seg *segment) declaration(depth int) string {
+	text := seg.text.String()
+	if depth == 0 || strings.HasPrefix(text, "@") || seg.firstColon == 0 {
+		return spaceAfterCommas(text)
+	}
+	property := strings.TrimSpace(text[:seg.firstColon-1])
+	value := strings.TrimSpace(text[seg.firstColon:])
+	return property + ": " + spaceAfterCommas(value)
+}
+
+// spaceAfterCommas puts a single space after every comma outside strings and
+// url() arguments.
+func spaceAfterCommas(text string) string {
+	return rewriteOutsideLiterals(text, func(c byte, next byte) string {
+		if c == ',' && next != ' ' && next != 0 {
+			return ", "
+		}
+		return string(c)
+	})
+}
+
+// spaceAfterColons puts a single space after every colon inside parentheses,
+// for the media and container features of @media and @container.
+func spaceAfterColons(text string) string {
+	nesting := 0
+	return rewriteOutsideLiterals(text, func(c byte, next byte) string {
+		switch c {
+		case '(':
+			nesting++
+		case ')':
+			nesting = max(nesting-1, 0)
+		case ':':
+			if nesting > 0 && next != ' ' && next != 0 {
+				return ": "
+			}
+		}
+		return string(c)
+	})
+}
+
+// spaceCombinators puts a single space on either side of the child and
+// sibling combinators of a selector, outside attribute selectors and
+// parentheses.
+func spaceCombinators(text string) string {
+	var (
+		out     strings.Builder
+		nesting int
+	)
+	rewritten := rewriteOutsideLiterals(text, func(c byte, next byte) string {
+		switch c {
+		case '[', '(':
+			nesting++
+		case ']', ')':
+			nesting = max(nesting-1, 0)
+		case '>', '+', '~':
+			if nesting == 0 && next != '=' {
+				return " " + string(c) + " "
+			}
+		}
+		return string(c)
+	})
+	// Collapse the doubled spaces left where a combinator was spaced already
+	space := false
+	for i := 0; i < len(rewritten); i++ {
+		if rewritten[i] == ' ' {
+			if !space {
+				out.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		out.WriteByte(rewritten[i])
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// rewriteOutsideLiterals replaces every byte of text outside strings, url()
+// arguments and escapes with the result of rewrite, which also gets the byte
+// after it, or 0 at the end.
+func rewriteOutsideLiterals(text string, rewrite func(c byte, next byte) string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\':
+			end := min(i+2, len(text))
+			out.WriteString(text[i:end])
+			i = end
+		case c == '"' || c == '\'':
+			end, _ := stringEnd(text, i)
+			out.WriteString(text[i:end])
+			i = end
+		case hasPrefixFold(text[i:], "url(") && (i == 0 || !isIdent(text[i-1])):
+			end, _ := urlEnd(text, i+len("url("))
+			out.WriteString(text[i:end])
+			i = end
+		default:
+			var next byte
+			if i+1 < len(text) {
+				next = text[i+1]
+			}
+			out.WriteString(rewrite(c, next))
+			i++
+		}
+	}
+	return out.String()
+}
+
+// stringEnd returns the index after the string literal starting at i, and
+// whether it is terminated.
+func stringEnd(s string, i int) (int, bool) {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '\n':
+			return j, false
+		case quote:
+			return j + 1, true
+		}
+	}
+	return len(s), false
+}
+
+// urlEnd returns the index after the closing parenthesis of a url( whose
+// argument starts at i, and whether there is one.
+func urlEnd(s string, i int) (int, bool) {
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '"', '\'':
+			end, ok := stringEnd(s, j)
+			if !ok {
+				return len(s), false
+			}
+			j = end - 1
+		case '\\':
+			j++
+		case ')':
+			return j + 1, true
+		}
+	}
+	return len(s), false
+}
+
+func pad(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isIdent(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func hasPrefixFold(s string, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,77 @@
+package prettifycss
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "one declaration per line",
+			in:   "body{color:red;margin:0 auto}",
+			want: "body {\n  color: red;\n  margin: 0 auto;\n}\n",
+		},
+		{
+			name: "blank line between top-level rules, comments stick to the next",
+			in:   "a{b:c}\n\n\n/* links */\np{d:e}",
+			want: "a {\n  b: c;\n}\n\n/* links */\np {\n  d: e;\n}\n",
+		},
+		{
+			name: "nested blocks are indented",
+			in:   "@media (min-width:10px){a{b:c}p{d:e}}",
+			want: "@media (min-width: 10px) {\n  a {\n    b: c;\n  }\n  p {\n    d: e;\n  }\n}\n",
+		},
+		{
+			name: "selectors get spaced commas and combinators",
+			in:   "a:hover,a>b~c,[x~=y]+d,li:nth-child(2n+1){x:y}",
+			want: "a:hover, a > b ~ c, [x~=y] + d, li:nth-child(2n+1) {\n  x: y;\n}\n",
+		},
+		{
+			name: "strings and urls are kept",
+			in:   ".a{content:\"a;{b\";background:url(data:x;y,z)}",
+			want: ".a {\n  content: \"a;{b\";\n  background: url(data:x;y,z);\n}\n",
+		},
+		{
+			name: "at-rules without a block",
+			in:   "@import url(a.css);@page :first{margin:0}",
+			want: "@import url(a.css);\n\n@page :first {\n  margin: 0;\n}\n",
+		},
+		{
+			name: "empty block",
+			in:   ".a { }",
+			want: ".a {}\n",
+		},
+		{
+			name: "unbalanced braces are left unchanged",
+			in:   "a { b: c; ",
+			want: "a { b: c; ",
+		},
+		{
+			name: "unterminated comment is left unchanged",
+			in:   "a { b: c } /* open",
+			want: "a { b: c } /* open",
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.in); got != tt.want {
+				t.Errorf("Format(%q) =\n%s\nwant\n%s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatWithPrefix(t *testing.T) {
+	got := FormatWithPrefix("a{b:c}", "    ")
+	want := "    a {\n      b: c;\n    }\n"
+	if got != want {
+		t.Errorf("FormatWithPrefix() = %q, want %q", got, want)
+	}
+}
@@ -6,6 +6,9 @@ import (
 
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
+
+	prettifycss "github.com/thetillhoff/temingo/pkg/prettifyCSS"
+	prettifyjs "github.com/thetillhoff/temingo/pkg/prettifyJS"
 )
 
 // Format parses s as HTML and returns it with consistent 2-space indentation.
@@ -67,6 +70,12 @@ func renderNode(buf *bytes.Buffer, n *html.Node, depth int) {
 			return
 		}
 		buf.WriteByte('>')
+		if content, ok := formatInline(n, depth+1); ok {
+			// Formatted stylesheets and scripts go on lines of their own,
+			// indented one level deeper than their element.
+			buf.WriteString("\n" + content + pad(depth) + "</" + n.Data + ">\n")
+			return
+		}
 		if isRaw(n.DataAtom) {
 			// Content is verbatim: no indentation, and text nodes are written
 			// unescaped so CSS/JS operators survive. Whitespace is significant
@@ -102,6 +111,43 @@ func renderNode(buf *bytes.Buffer, n *html.Node, depth int) {
 	}
 }
 
+// formatInline formats the content of a <style> element, or of a <script>
+// element holding JavaScript, with every line indented to depth. It reports
+// false for any other element, and for content that is empty or cannot be
+// formatted, which is then kept verbatim.
+func formatInline(n *html.Node, depth int) (string, bool) {
+	if n.FirstChild == nil || n.FirstChild != n.LastChild || n.FirstChild.Type != html.TextNode {
+		return "", false
+	}
+	content := n.FirstChild.Data
+
+	var formatted string
+	switch {
+	case n.DataAtom == atom.Style:
+		formatted = prettifycss.FormatWithPrefix(content, pad(depth))
+	case n.DataAtom == atom.Script && isJavaScript(n):
+		formatted = prettifyjs.FormatWithPrefix(content, pad(depth))
+	default:
+		return "", false
+	}
+	return formatted, formatted != content
+}
+
+// isJavaScript reports whether the script element n holds JavaScript, rather
+// than data like JSON or a template.
+func isJavaScript(n *html.Node) bool {
+	for _, a := range n.Attr {
+		if a.Key == "type" {
+			switch strings.ToLower(strings.TrimSpace(a.Val)) {
+			case "", "text/javascript", "application/javascript", "module":
+				return true
+			}
+			return false
+		}
+	}
+	return true
+}
+
 func pad(depth int) string {
 	return strings.Repeat("  ", depth)
 }
@@ -48,3 +48,44 @@ func TestFormatPreservesTextEntities(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatInlineStyleAndScript(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "style is formatted one level deeper",
+			in:   "<html><head><style>a{color:red}</style></head></html>",
+			want: "    <style>\n      a {\n        color: red;\n      }\n    </style>\n",
+		},
+		{
+			name: "script is formatted one level deeper",
+			in:   "<html><body><script>if (a) {f()}</script></body></html>",
+			want: "    <script>\n      if (a) {\n        f()\n      }\n    </script>\n",
+		},
+		{
+			name: "template literal lines are not indented",
+			in:   "<html><body><script>s=`a\nb`</script></body></html>",
+			want: "      s=`a\nb`\n",
+		},
+		{
+			name: "json script is kept verbatim",
+			in:   "<html><body><script type=\"application/ld+json\">{\"a\":1}</script></body></html>",
+			want: "<script type=\"application/ld+json\">{\"a\":1}</script>",
+		},
+		{
+			name: "unparseable script is kept verbatim",
+			in:   "<html><body><script>f(</script></body></html>",
+			want: "<script>f(</script>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out := Format(tt.in); !strings.Contains(out, tt.want) {
+				t.Errorf("Format(%q) =\n%s\nwant it to contain\n%s", tt.in, out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package prettifyjs
+
+import (
+	"errors"
+	"strings"
+)
+
+// Format returns the JavaScript s with consistent 2-space indentation.
+// Returns s unchanged on parse error or if s is empty.
+//
+// Every line is indented by the brackets open at its start. A line break is
+// added after each `{` and `;` that ends a statement, and before each `}`, but
+// never anywhere else, so automatic semicolon insertion sees the same line
+// breaks it would before. Runs of whitespace on a line become a single space,
+// but no space is added where there was none, and runs of blank lines become a
+// single blank line. Comments, strings, template
+// literals and regular expressions are kept verbatim.
+func Format(s string) string {
+	return FormatWithPrefix(s, "")
+}
+
+// FormatWithPrefix is Format, with prefix in front of every line, as for a
+// script inside an indented <script> element. The second and later lines of a
+// template literal or comment are left as they are, so a template literal
+// keeps its value.
+func FormatWithPrefix(s string, prefix string) string {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	formatted, err := format(s, prefix)
+	if err != nil {
+		return s
+	}
+	return formatted
+}
+
+func format(s string, prefix string) (string, error) {
+	var (
+		out       strings.Builder
+		brackets  []byte // The brackets open, innermost last
+		opened    []int  // The output line each of the brackets was opened on
+		line      int    // The current output line
+		closers   int    // The brackets the next token starts to close on its line
+		newlines  int    // Line breaks in s pending before the next token, at most 2
+		lineBreak bool   // A line break is added before the next token
+		space     bool   // Whitespace is pending before the next token
+		lineStart = true // Nothing was written on the current output line yet
+		lastToken string // The last identifier, keyword or punctuator written
+	)
+
+	// write writes token, preceded by the pending whitespace. A line is
+	// indented once for every earlier line that opened brackets still open,
+	// not counting the ones it starts by closing. A blank line is kept, unless
+	// it would follow a `{` or precede a `}`.
+	write := func(token string) {
+		if !lineStart && (newlines > 0 || lineBreak) {
+			out.WriteByte('\n')
+			if newlines > 1 && lastToken != "{" && token != "}" {
+				out.WriteByte('\n')
+			}
+			lineStart = true
+			line++
+		}
+		if lineStart {
+			depth := 0
+			for j, openedOn := range opened[:len(opened)-closers] {
+				if j == 0 || openedOn != opened[j-1] {
+					depth++
+				}
+			}
+			out.WriteString(prefix + strings.Repeat("  ", depth))
+		} else if space {
+			out.WriteByte(' ')
+		}
+		out.WriteString(token)
+		newlines, lineBreak, space, lineStart = 0, false, false, false
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\n':
+			newlines = min(newlines+1, 2)
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\v' || c == '\f':
+			space = true
+			i++
+
+		case strings.HasPrefix(s[i:], "//"):
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				end = len(s) - i
+			}
+			write(strings.TrimRight(s[i:i+end], " \t\r"))
+			i += end
+
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return "", errors.New("unterminated comment")
+			}
+			write(s[i : i+2+end+2])
+			i += 2 + end + 2
+
+		case c == '"' || c == '\'':
+			end, ok := stringEnd(s, i)
+			if !ok {
+				return "", errors.New("unterminated string")
+			}
+			write(s[i:end])
+			lastToken = "string"
+			i = end
+
+		case c == '`':
+			end, ok := templateEnd(s, i)
+			if !ok {
+				return "", errors.New("unterminated template literal")
+			}
+			write(s[i:end])
+			lastToken = "string"
+			i = end
+
+		case c == '/' && regexAllowedAfter(lastToken):
+			end, ok := regexEnd(s, i)
+			if !ok {
+				return "", errors.New("unterminated regular expression")
+			}
+			write(s[i:end])
+			lastToken = "regex"
+			i = end
+
+		case isIdent(c):
+			start := i
+			for i < len(s) && isIdent(s[i]) {
+				i++
+			}
+			write(s[start:i])
+			lastToken = s[start:i]
+
+		case c == '{' || c == '(' || c == '[':
+			write(string(c))
+			brackets = append(brackets, c)
+			opened = append(opened, line)
+			lastToken = string(c)
+			i++
+			if c == '{' {
+				lineBreak = nextToken(s, i) != '}'
+			}
+
+		case c == '}' || c == ')' || c == ']':
+			if len(brackets) == 0 || brackets[len(brackets)-1] != map[byte]byte{'}': '{', ')': '(', ']': '['}[c] {
+				return "", errors.New("unbalanced brackets")
+			}
+			if c == '}' && lastToken != "{" {
+				lineBreak = true
+			}
+			closers = leadingClosers(s[i:], brackets)
+			write(string(c))
+			closers = 0
+			brackets = brackets[:len(brackets)-1]
+			opened = opened[:len(opened)-1]
+			lastToken = string(c)
+			i++
+			lineBreak = c == '}' && !continuesAfterBlock(s, i)
+
+		case c == ';':
+			write(";")
+			lastToken = ";"
+			i++
+			lineBreak = len(brackets) == 0 || brackets[len(brackets)-1] == '{' // Not inside for (;;)
+
+		default:
+			adjacent := !space && newlines == 0
+			write(string(c))
+			if (c == '+' || c == '-') && lastToken == string(c) && adjacent {
+				lastToken += string(c) // An increment or decrement, after which a slash divides
+			} else {
+				lastToken = string(c)
+			}
+			i++
+		}
+	}
+
+	if len(brackets) > 0 {
+		return "", errors.New("unbalanced brackets")
+	}
+	return out.String() + "\n", nil
+}
+
+// leadingClosers returns how many of the open brackets the closing brackets at
+// the start of s close on the output line they start, with only spaces between
+// them. A `}` after the first starts a line of its own.
+func leadingClosers(s string, brackets []byte) int {
+	count := 0
+	for i := 0; i < len(s) && count < len(brackets); i++ {
+		switch s[i] {
+		case ' ', '\t':
+		case '}', ')', ']':
+			if count > 0 && s[i] == '}' {
+				return count
+			}
+			if brackets[len(brackets)-1-count] != map[byte]byte{'}': '{', ')': '(', ']': '['}[s[i]] {
+				return count
+			}
+			count++
+		default:
+			return count
+		}
+	}
+	return count
+}
+
+// nextToken returns the first byte after i that is not whitespace, or 0.
+func nextToken(s string, i int) byte {
+	for ; i < len(s); i++ {
+		if strings.IndexByte(" \t\r\n\v\f", s[i]) < 0 {
+			return s[i]
+		}
+	}
+	return 0
+}
+
+// continuesAfterBlock reports whether what follows a `}` at i belongs on the
+// same line, as in `} else {`, `});` or `}, {`.
+func continuesAfterBlock(s string, i int) bool {
+	rest := strings.TrimLeft(s[i:], " \t\r\v\f")
+	if rest == "" || rest[0] == '\n' {
+		return false
+	}
+	if strings.IndexByte(")],;.?:", rest[0]) >= 0 {
+		return true
+	}
+	for _, keyword := range []string{"else", "catch", "finally", "while"} {
+		if strings.HasPrefix(rest, keyword) && (len(rest) == len(keyword) || !isIdent(rest[len(keyword)])) {
+			return true
+		}
+	}
+	return false
+}
+
+// regexAllowedAfter reports whether a slash after token starts a regular
+// expression rather than a division.
+func regexAllowedAfter(token string) bool {
+	switch token {
+	case "", "(", ",", "=", ":", "[", "!", "&", "|", "?", "{", "}", ";", "~", "+", "-", "*", "%", "<", ">", "^",
+		"return", "typeof", "case", "do", "else", "in", "instanceof", "new", "delete", "void", "throw", "yield", "await":
+		return true
+	}
+	return false
+}
+
+// stringEnd returns the index after the string literal starting at i, and
+// whether it is terminated.
+func stringEnd(s string, i int) (int, bool) {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '\n':
+			return j, false
+		case quote:
+			return j + 1, true
+		}
+	}
+	return len(s), false
+}
+
+// templateEnd returns the index after the template literal starting at i,
+// skipping over the code of its substitutions, and whether it is terminated.
+func templateEnd(s string, i int) (int, bool) {
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '`':
+			return j + 1, true
+		case strings.HasPrefix(s[j:], "${"):
+			end, ok := substitutionEnd(s, j+2)
+			if !ok {
+				return len(s), false
+			}
+			j = end - 1
+		}
+	}
+	return len(s), false
+}
+
+// substitutionEnd returns the index after the brace closing a template
+// substitution whose code starts at i, and whether there is one.
+func substitutionEnd(s string, i int) (int, bool) {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		var (
+			end = j + 1
+			ok  = true
+		)
+		switch s[j] {
+		case '"', '\'':
+			end, ok = stringEnd(s, j)
+		case '`':
+			end, ok = templateEnd(s, j)
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return j + 1, true
+			}
+			depth--
+		}
+		if !ok {
+			return len(s), false
+		}
+		j = end - 1
+	}
+	return len(s), false
+}
+
+// regexEnd returns the index after the regular expression literal starting at
+// i, including its flags, and whether it is terminated. A slash inside a
+// character class does not end it.
+func regexEnd(s string, i int) (int, bool) {
+	inClass := false
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return j, false
+		case '/':
+			if inClass {
+				continue
+			}
+			j++
+			for j < len(s) && isIdent(s[j]) {
+				j++
+			}
+			return j, true
+		}
+	}
+	return len(s), false
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
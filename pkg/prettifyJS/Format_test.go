@@ -0,0 +1,97 @@
+package prettifyjs
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "braces and statements get lines of their own",
+			in:   "function add(a, b) {let c = a + b; return c}",
+			want: "function add(a, b) {\n  let c = a + b;\n  return c\n}\n",
+		},
+		{
+			name: "existing indentation is replaced",
+			in:   "    if (a) {\n            f();\n    } else {\n  g();\n }\n",
+			want: "if (a) {\n  f();\n} else {\n  g();\n}\n",
+		},
+		{
+			name: "brackets opened on one line indent once",
+			in:   "document.addEventListener('load', () => {\ninit();\n});",
+			want: "document.addEventListener('load', () => {\n  init();\n});\n",
+		},
+		{
+			name: "line breaks are never removed, runs of blank lines collapse",
+			in:   "let a = 1\n\n\n\nlet b = [1,\n2]",
+			want: "let a = 1\n\nlet b = [1,\n  2]\n",
+		},
+		{
+			name: "nested blocks close on lines of their own",
+			in:   "function f() {if (a) {return 1} else {return 2}}",
+			want: "function f() {\n  if (a) {\n    return 1\n  } else {\n    return 2\n  }\n}\n",
+		},
+		{
+			name: "nested object literals",
+			in:   "var x = {a: 1, b: {c: 2}};",
+			want: "var x = {\n  a: 1, b: {\n    c: 2\n  }\n};\n",
+		},
+		{
+			name: "closers of brackets opened on one line stay together",
+			in:   "f(function () {if (a) {g()}})",
+			want: "f(function () {\n  if (a) {\n    g()\n  }\n})\n",
+		},
+		{
+			name: "spacing is kept as written",
+			in:   "if(a){f()}else{g()}",
+			want: "if(a){\n  f()\n}else{\n  g()\n}\n",
+		},
+		{
+			name: "for loops keep their semicolons on one line",
+			in:   "for (let i = 0; i < 3; i++) {x++}",
+			want: "for (let i = 0; i < 3; i++) {\n  x++\n}\n",
+		},
+		{
+			name: "strings, templates and regular expressions are kept",
+			in:   "s = '{;}' + `a {\n  ${ {b: 1}.b }`; r = /[}]/g; y = i++ / 2",
+			want: "s = '{;}' + `a {\n  ${ {b: 1}.b }`;\nr = /[}]/g;\ny = i++ / 2\n",
+		},
+		{
+			name: "empty blocks stay on one line",
+			in:   "try {f()} catch (e) {}",
+			want: "try {\n  f()\n} catch (e) {}\n",
+		},
+		{
+			name: "unbalanced brackets are left unchanged",
+			in:   "if (a) { f(",
+			want: "if (a) { f(",
+		},
+		{
+			name: "unterminated string is left unchanged",
+			in:   "let a = 'b",
+			want: "let a = 'b",
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.in); got != tt.want {
+				t.Errorf("Format(%q) =\n%s\nwant\n%s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatWithPrefix_KeepsTemplateLiterals(t *testing.T) {
+	got := FormatWithPrefix("if (a) {s = `x\ny`}", "  ")
+	want := "  if (a) {\n    s = `x\ny`\n  }\n"
+	if got != want {
+		t.Errorf("FormatWithPrefix() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,12 @@
+package temingo
+
+import "slices"
+
+// ChangedOutputs returns the paths, relative to the outputDir, of the files the
+// last Render or RenderChanged wrote or deleted. Files whose content did not
+// change are not included, and neither is anything during a dry run.
+func (engine *Engine) ChangedOutputs() []string {
+	changed := slices.Clone(engine.changedOutputs)
+	slices.Sort(changed)
+	return slices.Compact(changed)
+}
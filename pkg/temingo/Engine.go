@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/thetillhoff/temingo/internal/refcheck"
+	"github.com/thetillhoff/temingo/pkg/markdown2html"
 )
 
 type Engine struct {
@@ -14,9 +15,11 @@ type Engine struct {
 	TemplateExtension       string
 	MetaTemplateExtension   string
 	PartialExtension        string
+	ComponentExtension      string
 	MetaFilename            string
 	MarkdownContentFilename string
-	Values                  map[string]string
+	ValuesFilename          string
+	Values                  map[string]interface{}
 	ValuesFilePaths         []string
 	NoDeleteOutputDir       bool
 	Verbose                 bool
@@ -25,11 +28,68 @@ type Engine struct {
 	Minify                  bool
 	Logger                  *slog.Logger
 
-	// Strict makes any reference finding exit non-zero. It draws no distinction
-	// between a definite failure and an indeterminate one: a timeout is as fatal
-	// as a 404, and the remedy is to run again.
+	// NoAutoIndent leaves the output of a {{ template }} action as it is,
+	// instead of indenting each line after its first by the indentation of the
+	// line the action is on.
+	NoAutoIndent bool
+
+	// Markdown configures the conversion of markdown content and pages: its
+	// extensions, hard wraps, raw html and code highlighting.
+	Markdown markdown2html.Options
+	// HighlightStylesheet is the output path of the generated stylesheet for
+	// highlighted code, written when Markdown has a HighlightStyle.
+	HighlightStylesheet string
+
+	// FlatMarkdownPages renders a markdown page like docs/intro.md to
+	// docs/intro.html, instead of docs/intro/index.html.
+	FlatMarkdownPages bool
+	// MarkdownTemplates executes markdown content files and markdown pages as
+	// templates, with the meta object of their page, before they are converted.
+	// Shortcodes in them call components.
+	MarkdownTemplates bool
+
+	// Images configures the variants the image template function generates.
+	Images ImageOptions
+
+	// Taxonomies are the meta keys whose values are collected across every
+	// page, like tags. A taxonomy with a term template, like
+	// tags/_term.template.html, gets a page for each of its terms.
+	Taxonomies []string
+
+	// CacheDir keeps state between builds, such as the manifest of output
+	// hashes, the vendored libraries and the generated image variants. Empty keeps that state in memory only,
+	// so a fresh process seeds it from the outputDir instead. A relative
+	// CacheDir is resolved against the folder holding the InputDir, and it
+	// cannot lie inside the OutputDir, which is cleared of unwanted files.
+	CacheDir string
+
+	// LibrariesFile declares the partial libraries to vendor from git
+	// repositories. Its lockfile, pinning each library to a commit, is kept
+	// next to it with the extension .lock. A missing file declares none.
+	LibrariesFile string
+
+	// BaseURL is the absolute URL the outputDir is served at, like
+	// https://example.com/. Generated files that need absolute URLs, such as
+	// the sitemap, are built from it.
+	BaseURL string
+	// Sitemap generates a sitemap.xml listing every html page under the
+	// BaseURL.
+	Sitemap bool
+
+	// Validate checks the rendered html, css, json, xml, svg and yaml outputs,
+	// and static files of those types, for syntax and structure errors.
+	// Findings are reported like reference findings, and accepted by the same
+	// Allow list.
+	Validate bool
+
+	// Strict makes any reference or validation finding exit non-zero. It draws
+	// no distinction between a definite failure and an indeterminate one: a
+	// timeout is as fatal as a 404, and the remedy is to run again.
 	Strict bool
-	// Allow accepts reference findings for matching URLs.
+	// StrictValues makes any value or meta key that no template reads exit
+	// non-zero, instead of only warning about it.
+	StrictValues bool
+	// Allow accepts reference and validation findings for matching URLs.
 	Allow refcheck.Allowlist
 	// NoRemoteChecks skips every check that needs a request, leaving the static
 	// and internal ones - which need no network - in place. Rendering stays
@@ -42,6 +102,58 @@ type Engine struct {
 	// linkCache keeps request outcomes for the life of the engine, so watch-mode
 	// rebuilds do not re-request unchanged references.
 	linkCache *refcheck.Cache
+
+	// lastBuild is what the previous Render produced and what each output was
+	// rendered from, so RenderChanged can rebuild only the outputs a change
+	// affects. It is nil until a Render succeeds.
+	lastBuild *buildState
+	// libraryNames are the names of the vendored libraries, and libraryFiles
+	// the vendored file of every library partial no input file overrides.
+	libraryNames map[string]bool
+	libraryFiles map[string]string
+	// components are the components the last read of the partials found, by
+	// the name they are called by.
+	components map[string]*component
+	// frontMatter is the front matter of every template, metatemplate,
+	// markdown content file and markdown page that has one, by input path.
+	frontMatter map[string]map[string]interface{}
+	// markdownPages are the markdown pages the last build rendered, by output
+	// path.
+	markdownPages map[string]markdownPage
+	// imageVariants are the image variants each output asked for, and
+	// imageReferences the images each output shows, both by output path.
+	imageVariants   map[string][]imageVariant
+	imageReferences map[string][]string
+	// imageCache holds the encoded image variants the last build used, by the
+	// hash of their source and options.
+	imageCache map[string][]byte
+	// taxonomies are the terms of every taxonomy by their name, collected from
+	// the meta of every page, and termPages the pages rendered for them by
+	// output path.
+	taxonomies map[string]map[string]*Term
+	termPages  map[string]termPage
+	// site is the index of every page the build renders.
+	site *Site
+	// referencedNames are the field names, function names and strings the
+	// templates, partials and markdown templates of the build refer to, so
+	// what none of them reads is not computed.
+	referencedNames map[string]bool
+	// descendants caches the .descendants of each folder during a build.
+	descendants map[string]map[string]*Descendant
+	// markdownDocuments caches the markdown converted during a build, by its
+	// body, and pageMetas the meta objects reading the site index computed, by
+	// output path, until the page is rendered with them.
+	markdownDocuments map[string]markdown2html.Document
+	pageMetas         map[string]pageMeta
+	// valueReads records the values and meta keys the templates read while a
+	// Render executes them, until the unused ones are checked. It is nil
+	// otherwise, and then nothing is recorded.
+	valueReads *valueReads
+	// manifest holds the content hash of every output file, so only changed
+	// files are written.
+	manifest *outputManifest
+	// changedOutputs lists the output files the last build wrote or deleted.
+	changedOutputs []string
 }
 
 // DefaultEngine returns an engine with default values
@@ -59,9 +171,11 @@ func DefaultEngine() Engine {
 		TemplateExtension:       ".template",
 		MetaTemplateExtension:   ".metatemplate",
 		PartialExtension:        ".partial",
+		ComponentExtension:      ".component",
 		MetaFilename:            "meta.yaml",
 		MarkdownContentFilename: "content.md",
-		Values:                  map[string]string{},
+		ValuesFilename:          "values.yaml",
+		Values:                  map[string]interface{}{},
 		ValuesFilePaths:         []string{},
 		NoDeleteOutputDir:       false,
 		Verbose:                 false,
@@ -69,7 +183,20 @@ func DefaultEngine() Engine {
 		Beautify:                false,
 		Minify:                  false,
 		Logger:                  logger,
+		NoAutoIndent:            false,
+		Markdown:                markdown2html.Options{},
+		HighlightStylesheet:     "highlight.css",
+		FlatMarkdownPages:       false,
+		MarkdownTemplates:       false,
+		Images:                  ImageOptions{Widths: []int{640, 1280, 1920}, Quality: 80},
+		Taxonomies:              []string{"tags", "categories"},
+		CacheDir:                "",
+		LibrariesFile:           "component.yaml",
+		BaseURL:                 "",
+		Sitemap:                 false,
+		Validate:                false,
 		Strict:                  false,
+		StrictValues:            false,
 		Allow:                   nil,
 		NoRemoteChecks:          false,
 		AllowInsecureScheme:     false,
@@ -1,13 +1,16 @@
 package temingo
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path"
-	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/thetillhoff/fileIO"
+	"github.com/thetillhoff/temingo/pkg/markdown2html"
 )
 
 // Renders the templates in the inputDir, writes them to the outputDir and copies the static files
@@ -15,9 +18,8 @@ func (engine *Engine) Render() error {
 	logger := engine.Logger
 
 	var (
-		err         error
-		ignoreLines []string
-		fileList    fileIO.FileList
+		err      error
+		fileList fileIO.FileList
 
 		partialPaths      []string
 		templatePaths     []string
@@ -25,79 +27,70 @@ func (engine *Engine) Render() error {
 		metaPaths         []string
 		staticPaths       []string
 
+		markdownContentPaths []string
+		markdownPagePaths    []string
+
 		content              []byte
 		renderedTemplatePath string
 
-		partialFiles      = map[string]string{}
+		partialFiles      map[string]string
 		renderedTemplates = map[string][]byte{}
+		outputs           = map[string]renderedOutput{}
 		templateContents  []string
 	)
 
+	engine.changedOutputs = nil
+	engine.lastBuild = nil // Set again once this build succeeds, so a failed one is not rebuilt from incrementally
+	engine.valueReads = newValueReads()
+	defer func() { engine.valueReads = nil }()
+	engine.imageVariants = map[string][]imageVariant{}
+	engine.imageReferences = map[string][]string{}
+	if engine.imageCache == nil {
+		engine.imageCache = map[string][]byte{}
+	}
+
 	if err = engine.validateEngine(); err != nil {
 		return err
 	}
 
-	// Parse temingoignore if exists
-	if _, err = os.Stat(engine.TemingoignorePath); os.IsNotExist(err) {
-		// No ignore file
-	} else if err != nil {
-		// ignore file exists, but can't be accessed
+	fileList, err = engine.readFileList()
+	if err != nil {
 		return err
-	} else {
-		// temingoignore exists and can be read
-
-		ignoreLines, err = fileIO.ReadFileLineByLine(engine.TemingoignorePath)
-		if err != nil {
-			return fmt.Errorf("reading temingoignore: %w", err)
-		}
 	}
 
-	// Add values files to ignore lines if specified
-	for _, valuesFilePath := range engine.ValuesFilePaths {
-		ignoreLines = append(ignoreLines, valuesFilePath)
-		logger.Debug("Adding values file to ignore list", "path", valuesFilePath)
+	// Sort retrieved filepaths
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, markdownPagePaths, staticPaths = engine.sortPaths(fileList)
+
+	if err = engine.readFrontMatter(slices.Concat(templatePaths, metaTemplatePaths, markdownContentPaths, markdownPagePaths)); err != nil {
+		return err
 	}
 
-	// Validate directories and get ignore path in one call
-	// This validates directories exist, creates outputDir if needed, and calculates the ignore path
-	outputIgnorePath, err := validateDirectories(engine.InputDir, engine.OutputDir, engine.NoDeleteOutputDir, logger)
+	partialPaths, err = engine.addLibraryPartials(partialPaths, fileList)
 	if err != nil {
 		return err
 	}
-	if outputIgnorePath != "" {
-		logger.Warn("Output directory is inside input directory. Adding to ignore list to prevent processing loops", "path", outputIgnorePath)
-		ignoreLines = append(ignoreLines, outputIgnorePath)
-		logger.Debug("Adding output directory to ignore list", "path", outputIgnorePath)
-	}
 
-	// Read filetree with ignoreLines
-	fileList, err = fileIO.GenerateFileListWithIgnoreLines(engine.InputDir, ignoreLines, engine.Verbose)
+	partialFiles, err = engine.readPartials(partialPaths)
 	if err != nil {
-		return fmt.Errorf("reading input directory %s: %w", engine.InputDir, err)
-	}
-	if len(fileList.Files) == 0 {
-		logger.Warn("No files found in input directory", "path", fileList.Path)
+		return err
 	}
 
-	if err = validateFolderNames(fileList); err != nil {
+	// Markdown files without a layout are copied like any other static file
+	unrenderedPagePaths, err := engine.readMarkdownPages(markdownPagePaths, partialFiles)
+	if err != nil {
 		return err
 	}
+	staticPaths = append(staticPaths, unrenderedPagePaths...)
 
-	// Sort retrieved filepaths
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, _, staticPaths = engine.sortPaths(fileList) // markdown content files are picked up later anyway
-
-	// Read partial files
-	for _, partialPath := range partialPaths {
-		content, err = fileIO.ReadFile(path.Join(engine.InputDir, partialPath))
-		if err != nil {
-			return fmt.Errorf("reading partial %s: %w", partialPath, err)
-		}
-		partialFiles[partialPath] = "{{ define \"" + partialPath + "\" -}}\n" + string(content) + "\n{{- end -}}"
+	if err = engine.readReferencedNames(templatePaths, metaTemplatePaths, partialFiles, fileList); err != nil {
+		return err
 	}
-
-	// Verify partials
-	err = engine.verifyPartials(partialFiles) // Check if the partials are unique
-	if err != nil {
+	engine.descendants = map[string]map[string]*Descendant{}
+	engine.markdownDocuments = map[string]markdown2html.Document{}
+	if err = engine.readTaxonomies(templatePaths, metaTemplatePaths, metaPaths); err != nil {
+		return err
+	}
+	if err = engine.readSite(templatePaths, metaTemplatePaths, fileList, metaPaths, partialFiles); err != nil {
 		return err
 	}
 
@@ -108,18 +101,22 @@ func (engine *Engine) Render() error {
 			return fmt.Errorf("reading template %s: %w", templatePath, err)
 		}
 
-		templateContents = append(templateContents, string(content))
+		body, _ := stripFrontMatter(templatePath, string(content))
+		templateContents = append(templateContents, body)
+		if engine.isTermTemplate(templatePath) { // Rendered once for each term, below
+			continue
+		}
 		renderedTemplatePath = strings.ReplaceAll(templatePath, engine.TemplateExtension, "")
 
-		// Create meta values object
-		meta, err := engine.generateMetaObjectForTemplatePath(renderedTemplatePath, fileList, metaPaths)
+		pages, err := engine.renderOutput(renderedTemplatePath, templatePath, false, string(content), fileList, metaPaths, partialFiles) // By rendering as early as possible, related errors are also thrown very early. In this case, even before any filesystem changes are made.
 		if err != nil {
 			return err
 		}
-
-		renderedTemplates[renderedTemplatePath], err = engine.renderTemplate(meta, templatePath, string(content), partialFiles) // By rendering as early as possible, related errors are also thrown very early. In this case, even before any filesystem changes are made.
-		if err != nil {
-			return fmt.Errorf("rendering template %s: %w", templatePath, err)
+		if err = addPages(renderedTemplates, outputs, renderedTemplatePath, pages, renderedOutput{
+			sourcePath:   templatePath,
+			dependencies: engine.collectDependencies(renderedTemplatePath, templatePath, string(content), fileList, metaPaths, partialFiles),
+		}); err != nil {
+			return err
 		}
 	}
 
@@ -129,138 +126,377 @@ func (engine *Engine) Render() error {
 		if err != nil {
 			return fmt.Errorf("reading metatemplate %s: %w", metaTemplatePath, err)
 		}
-		templateContents = append(templateContents, string(content))
+		body, _ := stripFrontMatter(metaTemplatePath, string(content))
+		templateContents = append(templateContents, body)
 
-		for _, metaFilePath := range fileList.FilterByLevelAtFolderPath(path.Dir(metaTemplatePath), 1).FilterByFilename(engine.MetaFilename).Files { // For each meta yaml in a direct subfolder
-			logger.Debug("Found metatemplate child", "path", metaFilePath)
-
-			renderedTemplatePath = path.Join(path.Dir(metaFilePath), path.Base(metaTemplatePath))             // == Location of meta yaml, minus meta yaml, plus filename of metatemplate
-			renderedTemplatePath = strings.ReplaceAll(renderedTemplatePath, engine.MetaTemplateExtension, "") // Remove template extension from filename
-
-			// Create meta values object
-			meta, err := engine.generateMetaObjectForTemplatePath(renderedTemplatePath, fileList, metaPaths)
+		for _, renderedTemplatePath = range engine.metaTemplateOutputPaths(metaTemplatePath, metaPaths) {
+			if existing, ok := outputs[renderedTemplatePath]; ok && existing.metaTemplate { // Only possible with a recursive one
+				return fmt.Errorf("metatemplates %s and %s both render to %s", existing.sourcePath, metaTemplatePath, renderedTemplatePath)
+			}
+			pages, err := engine.renderOutput(renderedTemplatePath, metaTemplatePath, true, string(content), fileList, metaPaths, partialFiles) // By rendering as early as possible, related errors are also thrown very early. In this case, even before any filesystem changes are made.
 			if err != nil {
 				return err
 			}
-
-			renderedTemplates[renderedTemplatePath], err = engine.renderTemplate(meta, renderedTemplatePath, string(content), partialFiles) // By rendering as early as possible, related errors are also thrown very early. In this case, even before any filesystem changes are made.
-			if err != nil {
-				return fmt.Errorf("rendering metatemplate %s for %s: %w", metaTemplatePath, renderedTemplatePath, err)
+			if err = addPages(renderedTemplates, outputs, renderedTemplatePath, pages, renderedOutput{
+				sourcePath:   metaTemplatePath,
+				metaTemplate: true,
+				dependencies: engine.collectDependencies(renderedTemplatePath, metaTemplatePath, string(content), fileList, metaPaths, partialFiles),
+			}); err != nil {
+				return err
 			}
 		}
 	}
 
-	engine.warnUnusedPartials(partialFiles, templateContents)
+	// Execute the layout of each markdown page, with the page as content
+	for _, renderedTemplatePath = range slices.Sorted(maps.Keys(engine.markdownPages)) {
+		page := engine.markdownPages[renderedTemplatePath]
+		if existing, ok := outputs[renderedTemplatePath]; ok {
+			return fmt.Errorf("%s and markdown page %s both render to %s", existing.sourcePath, page.sourcePath, renderedTemplatePath)
+		}
+		templateContents = append(templateContents, page.templateContent()) // Counts the layout as used
 
-	// Beautify/Minify
+		pages, err := engine.renderOutput(renderedTemplatePath, page.sourcePath, false, page.templateContent(), fileList, metaPaths, partialFiles)
+		if err != nil {
+			return err
+		}
+		if err = addPages(renderedTemplates, outputs, renderedTemplatePath, pages, renderedOutput{
+			sourcePath:   page.sourcePath,
+			dependencies: engine.collectDependencies(renderedTemplatePath, page.sourcePath, page.templateContent(), fileList, metaPaths, partialFiles),
+		}); err != nil {
+			return err
+		}
+	}
 
-	if engine.Beautify {
-		for renderedTemplatePath, content := range renderedTemplates {
-			renderedTemplates[renderedTemplatePath] = engine.beautify(content, path.Ext(renderedTemplatePath))
+	// Execute the term template of each taxonomy once for each of its terms
+	for _, renderedTemplatePath = range slices.Sorted(maps.Keys(engine.termPages)) {
+		termPage := engine.termPages[renderedTemplatePath]
+		if existing, ok := outputs[renderedTemplatePath]; ok {
+			return fmt.Errorf("%s and the page of term %q of %s both render to %s", existing.sourcePath, termPage.term.Name, termPage.templatePath, renderedTemplatePath)
 		}
-	} else if engine.Minify {
-		for renderedTemplatePath, content := range renderedTemplates {
-			renderedTemplates[renderedTemplatePath] = engine.minify(content, path.Ext(renderedTemplatePath))
+		content, err = fileIO.ReadFile(path.Join(engine.InputDir, termPage.templatePath))
+		if err != nil {
+			return fmt.Errorf("reading template %s: %w", termPage.templatePath, err)
 		}
+
+		pages, err := engine.renderOutput(renderedTemplatePath, termPage.templatePath, false, string(content), fileList, metaPaths, partialFiles)
+		if err != nil {
+			return err
+		}
+		if err = addPages(renderedTemplates, outputs, renderedTemplatePath, pages, renderedOutput{
+			sourcePath:   termPage.templatePath,
+			dependencies: engine.collectDependencies(renderedTemplatePath, termPage.templatePath, string(content), fileList, metaPaths, partialFiles),
+		}); err != nil {
+			return err
+		}
+	}
+
+	markdownTemplates, err := engine.markdownTemplateContents(fileList)
+	if err != nil {
+		return err
+	}
+	templateContents = append(templateContents, markdownTemplates...) // The partials markdown calls as a template count as used
+
+	engine.warnUnusedPartials(partialFiles, templateContents)
+
+	// Under StrictValues this fails before anything is written, like reference findings under Strict
+	if err = engine.checkUnusedValues(fileList, metaPaths); err != nil {
+		return err
 	}
+	engine.valueReads = nil // The files generated below read no more values
+
+	// Validation looks at the output as rendered, since beautifying would repair
+	// broken markup. Its findings are reported now, but under Strict only fail
+	// the build after the reference check reported its own as well.
+	validationErr := engine.validateOutputs(renderedTemplates, staticPaths, outputs)
+
+	// Beautify/Minify
+	for renderedTemplatePath, content := range renderedTemplates {
+		renderedTemplates[renderedTemplatePath] = engine.postProcess(content, path.Ext(renderedTemplatePath))
+	}
+
+	// Generated files are added before the reference check, so links to them resolve
+	pageFiles, err := engine.generatePageFiles(renderedTemplates, staticPaths, fileList, metaPaths, partialFiles)
+	if err != nil {
+		return err
+	}
+	generated, err := engine.withImageVariants(pageFiles, renderedTemplates, staticPaths)
+	if err != nil {
+		return err
+	}
+	maps.Copy(renderedTemplates, generated)
 
 	// Check every reference in the rendered output. Findings are reported and the
 	// write below proceeds; under Strict this returns after reporting them, so no
 	// output is written and the output directory keeps the previous build.
-	if err = engine.checkReferences(renderedTemplates, staticPaths); err != nil {
+	if err = errors.Join(validationErr, engine.checkReferences(renderedTemplates, staticPaths, outputs)); err != nil {
 		return err
 	}
 
 	// Update output
 	if !engine.DryRun { // Only if dry-run is disabled
-		if !engine.NoDeleteOutputDir {
-			err = os.RemoveAll(engine.OutputDir) // Ensure the outputDir is empty
-			if err != nil {
-				return fmt.Errorf("clearing output directory %s: %w", engine.OutputDir, err)
-			}
-			err = fileIO.CopyFile(engine.InputDir, engine.OutputDir) // Recreate the outputDir with the same permissions as the inputDir
-			if err != nil {
-				return fmt.Errorf("recreating output directory %s: %w", engine.OutputDir, err)
-			}
+		if err = engine.writeOutputs(renderedTemplates, staticPaths); err != nil {
+			return err
+		}
+	} else { // DryRun, so provide information about what would be done instead of doing it
+		logger.Info("Dry run: would write rendered templates", "count", len(renderedTemplates))
+		for templatePath := range renderedTemplates {
+			logger.Debug("Would write rendered template", "path", templatePath)
+		}
 
-			// Ensure output directory permissions match input directory (fileIO.CopyFile may not preserve them)
-			inputDirInfo, err := os.Stat(engine.InputDir)
+		logger.Info("Dry run: would write static files", "count", len(staticPaths))
+		for _, staticPath := range staticPaths {
+			logger.Debug("Would write static file", "path", staticPath)
+		}
+	}
+
+	engine.lastBuild = &buildState{
+		fileList:    fileList,
+		staticPaths: staticPaths,
+		outputs:     outputs,
+		rendered:    renderedTemplates,
+		pageFiles:   pageFiles,
+
+		frontMatter:   engine.frontMatter,
+		markdownPages: engine.markdownPages,
+		taxonomies:    engine.taxonomies,
+		site:          engine.site,
+	}
+
+	return nil
+}
+
+// readFileList lists the input directory, leaving out what the temingoignore,
+// the values files and a nested output directory exclude.
+func (engine *Engine) readFileList() (fileIO.FileList, error) {
+	logger := engine.Logger
+
+	var (
+		err         error
+		ignoreLines []string
+		fileList    fileIO.FileList
+	)
+
+	// Parse temingoignore if exists
+	if _, err = os.Stat(engine.TemingoignorePath); os.IsNotExist(err) {
+		// No ignore file
+	} else if err != nil {
+		// ignore file exists, but can't be accessed
+		return fileList, err
+	} else {
+		// temingoignore exists and can be read
+
+		ignoreLines, err = fileIO.ReadFileLineByLine(engine.TemingoignorePath)
+		if err != nil {
+			return fileList, fmt.Errorf("reading temingoignore: %w", err)
+		}
+	}
+
+	// Add values files to ignore lines if specified
+	for _, valuesFilePath := range engine.ValuesFilePaths {
+		ignoreLines = append(ignoreLines, valuesFilePath)
+		logger.Debug("Adding values file to ignore list", "path", valuesFilePath)
+	}
+
+	// Validate directories and get ignore path in one call
+	// This validates directories exist, creates outputDir if needed, and calculates the ignore path
+	outputIgnorePath, err := validateDirectories(engine.InputDir, engine.OutputDir, engine.NoDeleteOutputDir, logger)
+	if err != nil {
+		return fileList, err
+	}
+	if outputIgnorePath != "" {
+		logger.Warn("Output directory is inside input directory. Adding to ignore list to prevent processing loops", "path", outputIgnorePath)
+		ignoreLines = append(ignoreLines, outputIgnorePath)
+		logger.Debug("Adding output directory to ignore list", "path", outputIgnorePath)
+	}
+
+	// Read filetree with ignoreLines
+	fileList, err = fileIO.GenerateFileListWithIgnoreLines(engine.InputDir, ignoreLines, engine.Verbose)
+	if err != nil {
+		return fileList, fmt.Errorf("reading input directory %s: %w", engine.InputDir, err)
+	}
+	if len(fileList.Files) == 0 {
+		logger.Warn("No files found in input directory", "path", fileList.Path)
+	}
+
+	if err = validateFolderNames(fileList); err != nil {
+		return fileList, err
+	}
+
+	return fileList, nil
+}
+
+// readPartials reads the partial and component files and wraps each in a define
+// named after its path, then checks that no two define the same name. The
+// components are kept on the engine, by the name they are called by.
+func (engine *Engine) readPartials(partialPaths []string) (map[string]string, error) {
+	partialFiles := map[string]string{}
+	components := map[string]*component{}
+
+	for _, partialPath := range partialPaths {
+		content, err := fileIO.ReadFile(engine.inputFilePath(partialPath))
+		if err != nil {
+			return nil, fmt.Errorf("reading partial %s: %w", partialPath, err)
+		}
+		body := string(content)
+		if engine.isComponent(partialPath) {
+			var comp *component
+			comp, body, err = engine.parseComponent(partialPath, body)
 			if err != nil {
-				return fmt.Errorf("error getting input directory info: %w", err)
+				return nil, err
 			}
-			outputDirToCheck := strings.TrimSuffix(engine.OutputDir, string(filepath.Separator))
-			if outputDirToCheck == "" {
-				outputDirToCheck = engine.OutputDir
-			}
-			if err := os.Chmod(outputDirToCheck, inputDirInfo.Mode().Perm()); err != nil {
-				return fmt.Errorf("error setting output directory permissions: %w", err)
-			}
-			logger.Debug("Recreating output directory", "path", engine.OutputDir)
-
-			for _, staticPath := range staticPaths {
-				err = fileIO.CopyFile(path.Join(engine.InputDir, staticPath), path.Join(engine.OutputDir, staticPath))
-				if err != nil {
-					return fmt.Errorf("copying static file %s: %w", staticPath, err)
-				}
-				logger.Debug("Writing static file", "path", path.Join(engine.OutputDir, staticPath))
+			if existing, ok := components[comp.name]; ok {
+				return nil, fmt.Errorf("duplicate component name '%s' found in %s and %s", comp.name, partialPath, existing.path)
 			}
+			components[comp.name] = comp
 		}
+		partialFiles[partialPath] = "{{ define \"" + partialPath + "\" -}}\n" + body + "\n{{- end -}}"
+	}
+	engine.components = components
 
-		for templatePath, renderedTemplate := range renderedTemplates { // includes both templates and metaTemplates
+	// Verify partials
+	if err := engine.verifyPartials(partialFiles); err != nil { // Check if the partials are unique
+		return nil, err
+	}
 
-			if engine.NoDeleteOutputDir {
-				if _, err = os.Stat(path.Join(engine.OutputDir, templatePath)); err == nil {
-					err = os.Remove(path.Join(engine.OutputDir, templatePath))
-					if err != nil {
-						return fmt.Errorf("removing existing output file %s: %w", path.Join(engine.OutputDir, templatePath), err)
-					}
-					logger.Debug("Deleting existing rendered template", "path", path.Join(engine.OutputDir, templatePath))
-				}
-			}
+	return partialFiles, nil
+}
 
-			// Get permissions from input directory (used for both files and parent directories)
-			// For template files, we use input directory permissions as the source of truth
-			inputDirInfo, err := os.Stat(engine.InputDir)
-			if err != nil {
-				return fmt.Errorf("error getting input directory info: %w", err)
-			}
-			fileMode := inputDirInfo.Mode().Perm()
+// metaTemplateOutputPaths returns the output paths a metatemplate renders to:
+// one per direct subfolder that contains a meta yaml, or a markdown content
+// file with front matter. A recursive metatemplate renders to every such
+// folder below its own, at any depth.
+func (engine *Engine) metaTemplateOutputPaths(metaTemplatePath string, metaPaths []string) []string {
+	outputPaths := []string{}
 
-			// Ensure parent directory exists with same permissions as input directory
-			outputFilePath := path.Join(engine.OutputDir, templatePath)
-			outputDirPath := path.Dir(outputFilePath)
-			if err := os.MkdirAll(outputDirPath, fileMode); err != nil {
-				return fmt.Errorf("error creating output directory %s: %w", outputDirPath, err)
-			}
+	childFolders := engine.childPageFolders(path.Dir(metaTemplatePath), metaPaths, false)
+	if engine.isRecursiveMetaTemplate(metaTemplatePath) {
+		childFolders = engine.descendantPageFolders(path.Dir(metaTemplatePath), metaPaths, false)
+	}
+	for _, childFolder := range childFolders { // For each subfolder with a page
+		engine.Logger.Debug("Found metatemplate child", "path", childFolder)
 
-			// Use Chmod to ensure exact permissions (MkdirAll may be affected by umask)
-			if err := os.Chmod(outputDirPath, fileMode); err != nil {
-				return fmt.Errorf("error setting output directory permissions %s: %w", outputDirPath, err)
-			}
+		renderedTemplatePath := path.Join(childFolder, path.Base(metaTemplatePath))                       // == Location of the child folder, plus filename of metatemplate
+		renderedTemplatePath = strings.ReplaceAll(renderedTemplatePath, engine.MetaTemplateExtension, "") // Remove template extension from filename
+		outputPaths = append(outputPaths, renderedTemplatePath)
+	}
 
-			err = fileIO.WriteFile(outputFilePath, renderedTemplate)
-			if err != nil {
-				return fmt.Errorf("writing output file %s: %w", outputFilePath, err)
-			}
+	return outputPaths
+}
 
-			// Set file permissions to match input directory permissions
-			if err := os.Chmod(outputFilePath, fileMode); err != nil {
-				return fmt.Errorf("error setting permissions for %s: %w", outputFilePath, err)
-			}
+// renderOutput renders the output file at outputPath from the template or
+// metatemplate at sourcePath, and when its meta paginates, the further pages
+// of its listing. It returns them by output path.
+func (engine *Engine) renderOutput(outputPath string, sourcePath string, metaTemplate bool, content string, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) (map[string][]byte, error) {
+	pages := map[string][]byte{}
+	for number, total := 1, 1; number <= total; number++ {
+		rendered, paginator, err := engine.renderPage(outputPath, sourcePath, metaTemplate, content, fileList, metaPaths, partialFiles, number)
+		if err != nil {
+			return nil, err
+		}
+		pages[pagePath(outputPath, number)] = rendered
+		if paginator != nil && number == 1 { // The first page decides how many there are
+			total = paginator.Total
+		}
+	}
+	return pages, nil
+}
 
-			logger.Debug("Writing rendered template", "path", outputFilePath)
+// addPages adds the pages of the output at outputPath to rendered and outputs,
+// each rendered from output. A later page and another output may not take the
+// place of each other.
+func addPages(rendered map[string][]byte, outputs map[string]renderedOutput, outputPath string, pages map[string][]byte, output renderedOutput) error {
+	for _, pageOutputPath := range slices.Sorted(maps.Keys(pages)) {
+		page := output
+		if pageOutputPath != outputPath {
+			page.firstPage = outputPath
 		}
-	} else { // DryRun, so provide information about what would be done instead of doing it
-		logger.Info("Dry run: would write rendered templates", "count", len(renderedTemplates))
-		for templatePath := range renderedTemplates {
-			logger.Debug("Would write rendered template", "path", templatePath)
+		if existing, ok := outputs[pageOutputPath]; ok && (existing.firstPage != "" || page.firstPage != "") {
+			return fmt.Errorf("%s and %s both render to %s, as a later page of a paginated listing", existing.sourcePath, output.sourcePath, pageOutputPath)
+		}
+		rendered[pageOutputPath] = pages[pageOutputPath]
+		outputs[pageOutputPath] = page
+	}
+	return nil
+}
+
+// renderPage renders page number of the output at outputPath, and returns its
+// paginator, which is nil unless its meta paginates.
+func (engine *Engine) renderPage(outputPath string, sourcePath string, metaTemplate bool, content string, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string, number int) ([]byte, *Paginator, error) {
+	delete(engine.imageReferences, pagePath(outputPath, number)) // Rendering records them anew
+	delete(engine.imageVariants, pagePath(outputPath, number))
+
+	// Create meta values object, unless reading the site index already did
+	var meta map[string]interface{}
+	if page, ok := engine.pageMetas[outputPath]; ok && number == 1 {
+		delete(engine.pageMetas, outputPath)
+		meta = page.meta
+		meta["site"] = engine.site
+		engine.imageReferences[outputPath], engine.imageVariants[outputPath] = page.imageReferences, page.imageVariants
+	} else {
+		var err error
+		if meta, err = engine.generateMetaObjectForTemplatePath(outputPath, fileList, metaPaths, partialFiles); err != nil {
+			return nil, nil, err
+		}
+	}
+	size, err := pageSize(meta["meta"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("rendering %s: %w", outputPath, err)
+	}
+	var paginator *Paginator
+	if size > 0 {
+		items := meta["childMeta"]
+		if term, ok := meta["term"].(*Term); ok { // The page of a term lists the pages with it
+			items = term.Pages
+		}
+		if paginator, err = newPaginator(outputPath, number, size, items); err != nil {
+			return nil, nil, err
 		}
+		meta["paginator"] = paginator
+		meta["path"] = pagePath(outputPath, number)
+	}
 
-		logger.Info("Dry run: would write static files", "count", len(staticPaths))
-		for _, staticPath := range staticPaths {
-			logger.Debug("Would write static file", "path", staticPath)
+	rendered, err := engine.renderOutputContent(outputPath, sourcePath, metaTemplate, content, meta, partialFiles)
+	return rendered, paginator, err
+}
+
+// renderOutputContent executes the template or metatemplate at sourcePath with
+// meta, for the output at outputPath.
+func (engine *Engine) renderOutputContent(outputPath string, sourcePath string, metaTemplate bool, content string, meta map[string]interface{}, partialFiles map[string]string) ([]byte, error) {
+
+	sources := partialSources(partialFiles)
+	content, frontMatterLines := stripFrontMatter(sourcePath, content)
+
+	if page, ok := engine.markdownPages[outputPath]; ok && page.sourcePath == sourcePath {
+		rendered, err := engine.renderTemplate(meta, sourcePath, content, partialFiles)
+		if err != nil {
+			return nil, engine.locateTemplateError(err, sources, fmt.Sprintf("rendering markdown page %s with layout %s", sourcePath, page.layout))
 		}
+		return rendered, nil
 	}
 
-	return nil
+	if !metaTemplate {
+		sources[sourcePath] = templateSource{path: sourcePath, lineOffset: -frontMatterLines}
+		rendered, err := engine.renderTemplate(meta, sourcePath, content, partialFiles)
+		if err != nil {
+			return nil, engine.locateTemplateError(err, sources, fmt.Sprintf("rendering template %s", sourcePath))
+		}
+		return rendered, nil
+	}
+
+	sources[outputPath] = templateSource{path: sourcePath, lineOffset: -frontMatterLines}
+	rendered, err := engine.renderTemplate(meta, outputPath, content, partialFiles)
+	if err != nil {
+		return nil, engine.locateTemplateError(err, sources, fmt.Sprintf("rendering metatemplate %s for %s", sourcePath, outputPath))
+	}
+	return rendered, nil
+}
+
+// postProcess beautifies or minifies rendered content, as configured.
+func (engine *Engine) postProcess(content []byte, ext string) []byte {
+	if engine.Beautify {
+		return engine.beautify(content, ext)
+	} else if engine.Minify {
+		return engine.minify(content, ext)
+	}
+	return content
 }
@@ -0,0 +1,313 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/thetillhoff/fileIO"
+	"github.com/thetillhoff/temingo/pkg/markdown2html"
+)
+
+// RenderChanged rebuilds after changedPath was modified, rendering and writing
+// only the outputs that depend on it, and checking the references in those.
+// Generated files are made again only when the change can alter them, and
+// deleted when it does away with them.
+//
+// Anything it cannot attribute to a known set of outputs falls back to a full
+// Render: the first build, a dry run, a file being added, removed or renamed,
+// front matter being added to or removed from a file, a markdown page gaining,
+// losing or switching its layout, an image that pages show through the image
+// function, a paginated listing gaining or losing pages, a change to the terms
+// of a taxonomy or to the meta of the pages with them, a change to the site
+// index while a template reads it, and changes outside the inputDir such as the
+// temingoignore or a values file.
+// Values files are read once at startup, so a full rebuild is the most a change
+// to one can trigger.
+//
+// After a failed rebuild, the outputs that depend on the change are stale, so
+// the next change rebuilds everything.
+func (engine *Engine) RenderChanged(changedPath string) error {
+	err := engine.renderChanged(changedPath)
+	if err != nil {
+		engine.lastBuild = nil
+	}
+	return err
+}
+
+// renderChanged is RenderChanged, without forgetting the last build on errors.
+func (engine *Engine) renderChanged(changedPath string) error {
+	logger := engine.Logger
+
+	engine.changedOutputs = nil
+
+	state := engine.lastBuild
+	if state == nil || engine.DryRun {
+		return engine.Render()
+	}
+
+	if err := engine.validateEngine(); err != nil {
+		return err
+	}
+
+	inputPath, ok := engine.inputRelativePath(changedPath)
+	if !ok {
+		logger.Debug("Changed file is outside the input directory, rebuilding everything", "path", changedPath)
+		return engine.Render()
+	}
+
+	fileList, err := engine.readFileList()
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(fileList.Files, state.fileList.Files) {
+		logger.Debug("Input files were added or removed, rebuilding everything", "path", inputPath)
+		return engine.Render()
+	}
+	if !slices.Contains(fileList.Files, inputPath) {
+		logger.Debug("Changed file is ignored", "path", inputPath)
+		return nil
+	}
+
+	if slices.Contains(state.staticPaths, inputPath) && len(state.affectedOutputs(inputPath)) > 0 { // Its variants and their sizes change as well
+		logger.Debug("Changed image is shown by pages, rebuilding everything", "path", inputPath)
+		return engine.Render()
+	}
+	if slices.Contains(state.staticPaths, inputPath) && !engine.isMarkdownPage(inputPath) { // A markdown file may have been given a layout
+		return engine.rebuildStaticFile(inputPath)
+	}
+
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, markdownPagePaths, _ := engine.sortPaths(fileList)
+
+	// Front matter can make a folder a page of its own, which changes the
+	// outputs of metatemplates and the childMeta of its parent
+	if slices.Contains(slices.Concat(templatePaths, metaTemplatePaths, markdownContentPaths, markdownPagePaths), inputPath) {
+		if err = engine.updateFrontMatter(inputPath); err != nil {
+			return err
+		}
+	}
+	if !slices.Equal(slices.Sorted(maps.Keys(state.frontMatter)), slices.Sorted(maps.Keys(engine.frontMatter))) {
+		logger.Debug("Front matter was added or removed, rebuilding everything", "path", inputPath)
+		return engine.Render()
+	}
+
+	// Only meta yamls and front matter make up the meta of pages, which the
+	// taxonomies, the site index, the sitemap and the feeds are read from. The
+	// site index and the feeds take the markdown of pages as well, which calls
+	// partials when it is executed as a template.
+	_, hasFrontMatter := engine.frontMatter[inputPath]
+	changesMeta := hasFrontMatter || slices.Contains(metaPaths, inputPath)
+	changesPages := changesMeta || slices.Contains(markdownContentPaths, inputPath) || slices.Contains(markdownPagePaths, inputPath) || (engine.MarkdownTemplates && slices.Contains(partialPaths, inputPath))
+
+	partialPaths, err = engine.addLibraryPartials(partialPaths, fileList)
+	if err != nil {
+		return err
+	}
+	partialFiles, err := engine.readPartials(partialPaths)
+	if err != nil {
+		return err
+	}
+	if _, err = engine.readMarkdownPages(markdownPagePaths, partialFiles); err != nil {
+		return err
+	}
+	if err = engine.readReferencedNames(templatePaths, metaTemplatePaths, partialFiles, fileList); err != nil {
+		return err
+	}
+	engine.descendants = map[string]map[string]*Descendant{}
+	engine.markdownDocuments = map[string]markdown2html.Document{}
+	if changesMeta {
+		if err = engine.readTaxonomies(templatePaths, metaTemplatePaths, metaPaths); err != nil {
+			return err
+		}
+	}
+	if changesPages || len(state.site.Pages) == 0 || !engine.siteIsRead() { // An index no template read before is empty
+		if err = engine.readSite(templatePaths, metaTemplatePaths, fileList, metaPaths, partialFiles); err != nil {
+			return err
+		}
+	} else {
+		engine.pageMetas = nil
+	}
+
+	if !maps.Equal(state.markdownPages, engine.markdownPages) {
+		logger.Debug("Markdown pages or their layouts changed, rebuilding everything", "path", inputPath)
+		return engine.Render()
+	}
+	if !reflect.DeepEqual(state.taxonomies, engine.taxonomies) {
+		logger.Debug("Terms or the pages with them changed, rebuilding everything", "path", inputPath)
+		return engine.Render()
+	}
+	if !reflect.DeepEqual(state.site, engine.site) && engine.siteIsRead() {
+		logger.Debug("Site index changed, rebuilding everything", "path", inputPath)
+		return engine.Render()
+	}
+	if slices.Contains(state.staticPaths, inputPath) { // Still a markdown file without a layout
+		return engine.rebuildStaticFile(inputPath)
+	}
+
+	affected := state.affectedOutputs(inputPath)
+	if len(affected) == 0 {
+		logger.Debug("No output depends on changed file", "path", inputPath)
+		return nil
+	}
+
+	renderedTemplates := map[string][]byte{}
+	unprocessed := map[string][]byte{} // As rendered, before beautifying, for validation
+	for _, outputPath := range affected {
+		output := state.outputs[outputPath]
+		if output.firstPage != "" { // Rendered with its first page, which depends on the same files
+			continue
+		}
+
+		var content []byte
+		if page, ok := engine.markdownPages[outputPath]; ok {
+			content = []byte(page.templateContent())
+		} else if content, err = fileIO.ReadFile(path.Join(engine.InputDir, output.sourcePath)); err != nil {
+			return fmt.Errorf("reading template %s: %w", output.sourcePath, err)
+		}
+
+		pages, err := engine.renderOutput(outputPath, output.sourcePath, output.metaTemplate, string(content), fileList, metaPaths, partialFiles)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(slices.Sorted(maps.Keys(pages)), state.pagesOf(outputPath)) {
+			logger.Debug("Number of pages changed, rebuilding everything", "path", outputPath)
+			return engine.Render()
+		}
+
+		output.dependencies = engine.collectDependencies(outputPath, output.sourcePath, string(content), fileList, metaPaths, partialFiles)
+		for pageOutputPath, rendered := range pages {
+			unprocessed[pageOutputPath] = rendered
+			renderedTemplates[pageOutputPath] = engine.postProcess(rendered, path.Ext(pageOutputPath))
+
+			page := output
+			if pageOutputPath != outputPath {
+				page.firstPage = outputPath
+			}
+			state.outputs[pageOutputPath] = page
+		}
+	}
+
+	// The untouched outputs are taken from the previous build rather than
+	// re-rendered
+	site := make(map[string][]byte, len(state.rendered))
+	for outputPath, content := range state.rendered {
+		if _, ok := state.outputs[outputPath]; ok { // Rendered from a template, not generated
+			site[outputPath] = content
+		}
+	}
+	maps.Copy(site, renderedTemplates)
+	// The sitemap and feeds are generated from the whole site, since a changed
+	// meta yaml or markdown content can change the entry or feed item of any
+	// page. The image variants are those the outputs ask for.
+	pageFiles := state.pageFiles
+	if changesPages {
+		if pageFiles, err = engine.generatePageFiles(site, state.staticPaths, fileList, metaPaths, partialFiles); err != nil {
+			return err
+		}
+	}
+	generated, err := engine.withImageVariants(pageFiles, site, state.staticPaths)
+	if err != nil {
+		return err
+	}
+	maps.Copy(site, generated)
+	var removed []string // Generated by the previous build, but not by this one
+	for outputPath := range state.rendered {
+		if _, ok := site[outputPath]; !ok {
+			removed = append(removed, outputPath)
+		}
+	}
+	slices.Sort(removed)
+
+	// Only the references in what changed are checked, since those elsewhere
+	// resolve as before. A removed file can break links from any output, so
+	// then all of them are checked.
+	var checked map[string]bool
+	if len(removed) == 0 {
+		checked = map[string]bool{}
+		for outputPath := range renderedTemplates {
+			checked[outputPath] = true
+		}
+		for outputPath, content := range generated {
+			if !bytes.Equal(content, state.rendered[outputPath]) {
+				checked[outputPath] = true
+			}
+		}
+	}
+	validationErr := engine.validateOutputs(unprocessed, nil, state.outputs)
+	if err = errors.Join(validationErr, engine.checkReferencesIn(site, state.staticPaths, state.outputs, checked)); err != nil {
+		return err
+	}
+
+	for _, outputPath := range affected {
+		if err = engine.writeRenderedTemplate(outputPath, renderedTemplates[outputPath]); err != nil {
+			return err
+		}
+	}
+	for outputPath, content := range generated {
+		if err = engine.writeRenderedTemplate(outputPath, content); err != nil {
+			return err
+		}
+	}
+	if err = engine.deleteOutputs(removed); err != nil {
+		return err
+	}
+
+	if err = engine.saveOutputManifest(); err != nil {
+		return err
+	}
+
+	state.fileList = fileList
+	state.rendered = site
+	state.pageFiles = pageFiles
+	state.frontMatter, state.markdownPages, state.taxonomies, state.site = engine.frontMatter, engine.markdownPages, engine.taxonomies, engine.site
+
+	logger.Info("Rebuilt affected outputs", "path", inputPath, "count", len(affected))
+	for _, outputPath := range affected {
+		logger.Debug("Rebuilt output", "path", outputPath)
+	}
+
+	return nil
+}
+
+// rebuildStaticFile copies the changed static file at inputPath to the
+// outputDir.
+func (engine *Engine) rebuildStaticFile(inputPath string) error {
+	if engine.NoDeleteOutputDir { // Render leaves static files alone in that case, too
+		return nil
+	}
+	if err := engine.validateOutputs(nil, []string{inputPath}, nil); err != nil {
+		return err
+	}
+	if err := engine.writeStaticFile(inputPath); err != nil {
+		return err
+	}
+	engine.Logger.Info("Rebuilt static file", "path", inputPath)
+	return engine.saveOutputManifest()
+}
+
+// inputRelativePath returns filePath relative to the inputDir, in the
+// slash-separated form the file list uses. It reports false for paths outside
+// the inputDir.
+func (engine *Engine) inputRelativePath(filePath string) (string, bool) {
+	absInputDir, err := filepath.Abs(engine.InputDir)
+	if err != nil {
+		return "", false
+	}
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(absInputDir, absFilePath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,286 @@
+package temingo
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// writeTestFiles writes files relative to dir, creating parent directories.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		absPath := filepath.Join(dir, relPath)
+		if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", relPath, err)
+		}
+		if err := os.WriteFile(absPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", relPath, err)
+		}
+	}
+}
+
+// setupIncrementalTestEngine renders a small blog once and returns the engine
+// together with its input and output directories.
+func setupIncrementalTestEngine(t *testing.T) (*Engine, string, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	writeTestFiles(t, inputDir, map[string]string{
+		"index.template.html":          `{{ template "header.partial.html" }}home`,
+		"about/index.template.html":    `about`,
+		"header.partial.html":          `<header>v1</header>`,
+		"blog/index.template.html":     `{{ range $k, $v := .childMeta }}{{ $v.title }} {{ end }}`,
+		"blog/index.metatemplate.html": `{{ .meta.title }}: {{ .content }}`,
+		"blog/first/meta.yaml":         `title: First`,
+		"blog/first/content.md":        `first post`,
+		"blog/second/meta.yaml":        `title: Second`,
+		"blog/second/content.md":       `second post`,
+		"static/style.css":             `body { color: red; }`,
+	})
+
+	engine := DefaultEngine()
+	engine.InputDir = inputDir + string(filepath.Separator)
+	engine.OutputDir = outputDir + string(filepath.Separator)
+	engine.TemingoignorePath = filepath.Join(tmpDir, ".temingoignore")
+	engine.NoRemoteChecks = true
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	return &engine, inputDir, outputDir
+}
+
+// markOutputs overwrites the given output files with a sentinel, so a later
+// check can tell whether they were rewritten.
+func markOutputs(t *testing.T, outputDir string, outputPaths ...string) {
+	t.Helper()
+	for _, outputPath := range outputPaths {
+		if err := os.WriteFile(filepath.Join(outputDir, outputPath), []byte("untouched"), 0644); err != nil {
+			t.Fatalf("Failed to mark %s: %v", outputPath, err)
+		}
+	}
+}
+
+func readOutput(t *testing.T, outputDir string, outputPath string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(outputDir, outputPath))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", outputPath, err)
+	}
+	return string(content)
+}
+
+func TestRenderChanged_OnlyRebuildsDependentOutputs(t *testing.T) {
+	tests := []struct {
+		name        string
+		changedFile string
+		newContent  string
+		rebuilt     []string
+		untouched   []string
+		wantContent map[string]string
+	}{
+		{
+			name:        "content.md only rebuilds its own page",
+			changedFile: "blog/first/content.md",
+			newContent:  "edited post",
+			rebuilt:     []string{"blog/first/index.html"},
+			untouched:   []string{"blog/second/index.html", "blog/index.html", "index.html", "about/index.html"},
+			wantContent: map[string]string{"blog/first/index.html": "edited post"},
+		},
+		{
+			name:        "partial rebuilds the pages that include it",
+			changedFile: "header.partial.html",
+			newContent:  "<header>v2</header>",
+			rebuilt:     []string{"index.html"},
+			untouched:   []string{"about/index.html", "blog/first/index.html"},
+			wantContent: map[string]string{"index.html": "<header>v2</header>"},
+		},
+		{
+			name:        "child meta yaml rebuilds the child and the parent listing",
+			changedFile: "blog/second/meta.yaml",
+			newContent:  "title: Renamed",
+			rebuilt:     []string{"blog/second/index.html", "blog/index.html"},
+			untouched:   []string{"blog/first/index.html", "index.html"},
+			wantContent: map[string]string{"blog/second/index.html": "Renamed:", "blog/index.html": "Renamed"},
+		},
+		{
+			name:        "static file is copied without rendering anything",
+			changedFile: "static/style.css",
+			newContent:  "body { color: blue; }",
+			rebuilt:     []string{"static/style.css"},
+			untouched:   []string{"index.html", "about/index.html", "blog/index.html"},
+			wantContent: map[string]string{"static/style.css": "blue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, inputDir, outputDir := setupIncrementalTestEngine(t)
+			markOutputs(t, outputDir, append(tt.rebuilt, tt.untouched...)...)
+
+			writeTestFiles(t, inputDir, map[string]string{tt.changedFile: tt.newContent})
+			if err := engine.RenderChanged(filepath.Join(inputDir, tt.changedFile)); err != nil {
+				t.Fatalf("RenderChanged() unexpected error: %v", err)
+			}
+
+			for _, outputPath := range tt.untouched {
+				if got := readOutput(t, outputDir, outputPath); got != "untouched" {
+					t.Errorf("RenderChanged() rewrote %s, which does not depend on %s", outputPath, tt.changedFile)
+				}
+			}
+			for outputPath, want := range tt.wantContent {
+				if got := readOutput(t, outputDir, outputPath); !strings.Contains(got, want) {
+					t.Errorf("RenderChanged() %s = %q, want it to contain %q", outputPath, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderChanged_AddedFileRebuildsEverything(t *testing.T) {
+	engine, inputDir, outputDir := setupIncrementalTestEngine(t)
+	markOutputs(t, outputDir, "about/index.html")
+
+	writeTestFiles(t, inputDir, map[string]string{
+		"blog/third/meta.yaml": "title: Third",
+	})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "blog/third/meta.yaml")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+
+	if got := readOutput(t, outputDir, "blog/third/index.html"); !strings.Contains(got, "Third") {
+		t.Errorf("RenderChanged() blog/third/index.html = %q, want it rendered for the new child", got)
+	}
+	if got := readOutput(t, outputDir, "about/index.html"); got != "about" {
+		t.Errorf("RenderChanged() about/index.html = %q, want a full rebuild to restore it", got)
+	}
+}
+
+func TestRenderChanged_AfterFailedRebuild(t *testing.T) {
+	engine, inputDir, outputDir := setupIncrementalTestEngine(t)
+	metaTemplatePath := filepath.Join(inputDir, "blog/index.metatemplate.html")
+
+	// The rename fails to build along with the broken metatemplate
+	writeTestFiles(t, inputDir, map[string]string{"blog/index.metatemplate.html": `{{ .meta.title }`})
+	if err := engine.RenderChanged(metaTemplatePath); err == nil {
+		t.Fatalf("RenderChanged() with a broken metatemplate unexpectedly succeeded")
+	}
+	writeTestFiles(t, inputDir, map[string]string{"blog/first/meta.yaml": "title: Renamed"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "blog/first/meta.yaml")); err == nil {
+		t.Fatalf("RenderChanged() with a broken metatemplate unexpectedly succeeded")
+	}
+
+	// Fixing the metatemplate brings every output up to date, not only its own
+	writeTestFiles(t, inputDir, map[string]string{"blog/index.metatemplate.html": `{{ .meta.title }}: {{ .content }}`})
+	if err := engine.RenderChanged(metaTemplatePath); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "blog/index.html"); got != "Renamed Second " {
+		t.Errorf("RenderChanged() blog/index.html = %q, want the renamed post", got)
+	}
+	if got := readOutput(t, outputDir, "blog/first/index.html"); !strings.HasPrefix(got, "Renamed: ") {
+		t.Errorf("RenderChanged() blog/first/index.html = %q, want the renamed post", got)
+	}
+}
+
+func TestRenderChanged_WithoutPreviousBuildRendersEverything(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+	writeTestFiles(t, inputDir, map[string]string{"index.template.html": "home"})
+
+	engine := DefaultEngine()
+	engine.InputDir = inputDir + string(filepath.Separator)
+	engine.OutputDir = outputDir + string(filepath.Separator)
+	engine.TemingoignorePath = filepath.Join(tmpDir, ".temingoignore")
+
+	if err := engine.RenderChanged(filepath.Join(inputDir, "index.template.html")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "index.html"); got != "home" {
+		t.Errorf("RenderChanged() index.html = %q, want %q", got, "home")
+	}
+}
+
+func TestCollectDependencies(t *testing.T) {
+	engine := DefaultEngine()
+	partialFiles := map[string]string{
+		"a.partial.html": `{{ define "a.partial.html" -}}{{ template "b.partial.html" }}{{- end -}}`,
+		"b.partial.html": `{{ define "b.partial.html" -}}b{{- end -}}`,
+		"c.partial.html": `{{ define "c.partial.html" -}}c{{- end -}}`,
+	}
+	metaPaths := []string{"meta.yaml", "blog/meta.yaml", "blog/post/meta.yaml", "other/meta.yaml"}
+	fileList := fileIO.FileList{Files: append([]string{"blog/content.md", "other/content.md"}, metaPaths...)}
+
+	got := engine.collectDependencies("blog/index.html", "blog/index.template.html", `{{ template "a.partial.html" }}`, fileList, metaPaths, partialFiles)
+
+	want := []string{"a.partial.html", "b.partial.html", "blog/content.md", "blog/index.template.html", "blog/meta.yaml", "blog/post/meta.yaml", "meta.yaml"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("collectDependencies() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderChanged_DeletesRemovedGeneratedFiles(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"about/index.template.html":      "about",
+		"blog/meta.yaml":                 "title: Blog\nfeed:\n  formats: [json]\n",
+		"blog/index.template.html":       "{{ .meta.title }}",
+		"blog/first/meta.yaml":           "title: First\ndate: 2024-01-01\n",
+		"blog/first/index.template.html": "{{ .meta.title }}",
+	})
+	engine.BaseURL = "https://example.com/"
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	readOutput(t, outputDir, "blog/feed.json")
+	markOutputs(t, outputDir, "about/index.html")
+
+	writeTestFiles(t, inputDir, map[string]string{"blog/meta.yaml": "title: Blog\n"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "blog/meta.yaml")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+
+	if got := readOutput(t, outputDir, "about/index.html"); got != "untouched" {
+		t.Errorf("RenderChanged() rewrote about/index.html, want an incremental rebuild")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "blog/feed.json")); !os.IsNotExist(err) {
+		t.Errorf("RenderChanged() left blog/feed.json in place after its feed was removed")
+	}
+	if !slices.Contains(engine.ChangedOutputs(), "blog/feed.json") {
+		t.Errorf("ChangedOutputs() = %v, want it to list the deleted feed", engine.ChangedOutputs())
+	}
+}
+
+func TestRenderChanged_ChecksReferencesOfRebuiltOutputs(t *testing.T) {
+	engine, inputDir, _ := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":       `<a href="/missing/">missing</a>`,
+		"about/index.template.html": "about",
+	})
+	var buf bytes.Buffer
+	engine.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "/missing/") {
+		t.Fatalf("Render() log = %q, want a finding for /missing/", buf.String())
+	}
+
+	buf.Reset()
+	writeTestFiles(t, inputDir, map[string]string{"about/index.template.html": `<a href="/gone/">gone</a>`})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "about/index.template.html")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "/gone/") || strings.Contains(out, "/missing/") {
+		t.Errorf("RenderChanged() log = %q, want a finding for the rebuilt page only", out)
+	}
+}
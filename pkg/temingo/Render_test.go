@@ -189,7 +189,7 @@ func TestRender_WithValues(t *testing.T) {
 	engine := DefaultEngine()
 	engine.InputDir = inputDir + string(filepath.Separator)
 	engine.OutputDir = outputDir + string(filepath.Separator)
-	engine.Values = map[string]string{
+	engine.Values = map[string]interface{}{
 		"siteName": "My Website",
 		"version":  "2.0.0",
 	}
@@ -215,6 +215,31 @@ func TestRender_WithValues(t *testing.T) {
 	}
 }
 
+func TestRender_WithStructuredValues(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html": `{{ range .nav }}<a href="{{ .url }}">{{ .title }}</a>{{ end }}` +
+			`{{ .social.github }} {{ if .draft }}draft{{ end }} {{ .postsPerPage }}`,
+	})
+	engine.Values = map[string]interface{}{
+		"nav": []interface{}{
+			map[string]interface{}{"title": "Home", "url": "/"},
+			map[string]interface{}{"title": "Blog", "url": "/blog/"},
+		},
+		"social":       map[string]interface{}{"github": "octocat"},
+		"draft":        true,
+		"postsPerPage": 10,
+	}
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	want := `<a href="/">Home</a><a href="/blog/">Blog</a>octocat draft 10`
+	if got := readOutput(t, outputDir, "index.html"); got != want {
+		t.Errorf("Render() output = %q, want %q", got, want)
+	}
+}
+
 func TestRender_DryRun(t *testing.T) {
 	// Create a new tmpDir for each test case to ensure isolation
 	tmpDir := t.TempDir()
@@ -511,7 +536,7 @@ func TestRender_UsingInitFilesExampleProject(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Use the helper to set up the example project from InitFiles
-	engine, _, outputDir, err := setupTestProjectFromInitFilesWithEngine(tmpDir, "example", map[string]string{
+	engine, _, outputDir, err := setupTestProjectFromInitFilesWithEngine(tmpDir, "example", map[string]interface{}{
 		"siteName": "Test Site",
 	})
 	if err != nil {
@@ -542,3 +567,51 @@ func TestRender_UsingInitFilesExampleProject(t *testing.T) {
 		t.Errorf("Render() should have copied static file %q", staticFile)
 	}
 }
+
+func TestRender_MarkdownOptions(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"post/index.template.html": "{{ .content }}",
+		"post/content.md":          "Text[^1]\n\n```go\nvar x = 1\n```\n\n[^1]: Note\n",
+	})
+	engine.Beautify = false
+	engine.Markdown.Footnotes = true
+	engine.Markdown.HighlightStyle = "github"
+	engine.HighlightStylesheet = "css/highlight.css"
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	page := readOutput(t, outputDir, "post/index.html")
+	for _, want := range []string{`<sup id="fnref:1">`, `<span class="kd">var</span>`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Render() post/index.html = %q, want it to contain %q", page, want)
+		}
+	}
+	if css := readOutput(t, outputDir, "css/highlight.css"); !strings.Contains(css, ".chroma .kd") {
+		t.Errorf("Render() css/highlight.css = %q, want the highlight classes", css)
+	}
+
+	engine.Markdown.HighlightStyle = "nope"
+	if err := engine.Render(); err == nil || !strings.Contains(err.Error(), `unknown highlight style "nope"`) {
+		t.Errorf("Render() error = %v, want an unknown highlight style", err)
+	}
+}
+
+func TestRender_MarkdownStructure(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"post/index.template.html": `{{ range .toc }}[{{ .ID }} {{ .Text }}{{ range .Children }} ({{ .Level }} {{ .Text }}){{ end }}]{{ end }} {{ .summary }}{{ .wordCount }} words, {{ .readingTime }} min`,
+		"post/content.md":          "# Setup\n\nInstall it first.\n\n<!--more-->\n\n## Linux\n\n## macOS\n",
+		"index.template.html":      "{{ with .toc }}toc{{ else }}no toc{{ end }}",
+	})
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	want := "[setup Setup (2 Linux) (2 macOS)] <h1 id=\"setup\">Setup</h1>\n<p>Install it first.</p>\n6 words, 1 min"
+	if got := readOutput(t, outputDir, "post/index.html"); got != want {
+		t.Errorf("Render() post/index.html = %q, want %q", got, want)
+	}
+	if got := readOutput(t, outputDir, "index.html"); got != "no toc" {
+		t.Errorf("Render() index.html = %q, want no toc without markdown content", got)
+	}
+}
@@ -0,0 +1,39 @@
+package temingo
+
+import "strconv"
+
+// Position is a location in an input file. Line and Col are 1-based, and 0 when
+// unknown.
+type Position struct {
+	File string
+	Line int
+	Col  int
+}
+
+// String formats the position as file:line:col, the form editors and terminals
+// jump to, leaving out what is unknown.
+func (p Position) String() string {
+	s := p.File
+	if p.Line > 0 {
+		s += ":" + strconv.Itoa(p.Line)
+		if p.Col > 0 {
+			s += ":" + strconv.Itoa(p.Col)
+		}
+	}
+	return s
+}
+
+// SourceError is an error located in an input file - a template, partial,
+// metatemplate, meta yaml or markdown content file.
+type SourceError struct {
+	Position
+	Err error
+}
+
+func (e *SourceError) Error() string {
+	return e.Position.String() + ": " + e.Err.Error()
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,169 @@
+package temingo
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// The markers markTemplateCalls puts around the output of a {{ template }}
+// action. indentTemplateCalls removes them again, so they never reach an
+// output file.
+const (
+	indentMarkerStart     = "\x1etemingo-indent:"
+	indentMarkerEnd       = "\x1etemingo-end\x1f"
+	indentMarkerDelimiter = '\x1f'
+)
+
+// markTemplateCalls surrounds every {{ template }} action and {{ component }}
+// call in the parsed templates of tmpl with markers carrying the indentation
+// of the line the call is on, so indentTemplateCalls can indent the lines of
+// its output to match. sources holds the content each template was parsed
+// from, by its name.
+//
+// The markers are added to the parse trees as text, not to the content before
+// it is parsed, so whitespace is trimmed exactly as written and errors keep
+// pointing at the right line and column.
+func markTemplateCalls(tmpl *template.Template, sources map[string]string) {
+	marked := map[*parse.Tree]bool{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || marked[t.Tree] {
+			continue
+		}
+		marked[t.Tree] = true
+		if content, ok := sources[t.Tree.ParseName]; ok {
+			markTemplateCallsInList(t.Tree.Root, content)
+		}
+	}
+}
+
+// markTemplateCallsInList marks the template calls in list and the lists
+// nested in it. content is the text list was parsed from.
+func markTemplateCallsInList(list *parse.ListNode, content string) {
+	if list == nil {
+		return
+	}
+
+	nodes := make([]parse.Node, 0, len(list.Nodes))
+	for _, node := range list.Nodes {
+		switch node := node.(type) {
+		case *parse.IfNode:
+			markTemplateCallsInList(node.List, content)
+			markTemplateCallsInList(node.ElseList, content)
+		case *parse.RangeNode:
+			markTemplateCallsInList(node.List, content)
+			markTemplateCallsInList(node.ElseList, content)
+		case *parse.WithNode:
+			markTemplateCallsInList(node.List, content)
+			markTemplateCallsInList(node.ElseList, content)
+		}
+		if !isTemplateCall(node) {
+			nodes = append(nodes, node)
+			continue
+		}
+
+		start := int(node.Position())
+		if open := strings.LastIndex(content[:start], "{{"); open >= 0 { // The position is inside the action
+			start = open
+		}
+		lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+		line := content[lineStart:start]
+		indentation := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		nodes = append(nodes,
+			&parse.TextNode{NodeType: parse.NodeText, Pos: node.Position(), Text: []byte(indentMarkerStart + indentation + string(indentMarkerDelimiter))},
+			node,
+			&parse.TextNode{NodeType: parse.NodeText, Pos: node.Position(), Text: []byte(indentMarkerEnd)},
+		)
+	}
+	list.Nodes = nodes
+}
+
+// isTemplateCall reports whether node is a {{ template }} action, or an action
+// starting with a {{ component }} call.
+func isTemplateCall(node parse.Node) bool {
+	switch node := node.(type) {
+	case *parse.TemplateNode:
+		return true
+	case *parse.ActionNode:
+		if len(node.Pipe.Decl) > 0 || len(node.Pipe.Cmds) == 0 {
+			return false
+		}
+		identifier, ok := node.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+		return ok && identifier.Ident == "component"
+	}
+	return false
+}
+
+// rawTextElements are the elements whose content is whitespace-sensitive, so
+// lines starting inside them are never indented.
+var rawTextElements = []string{"pre", "textarea"}
+
+// indentTemplateCalls indents every line after the first of each marked
+// {{ template }} output by the indentation of the line it was called on, and
+// removes the markers. Nested calls add up. Blank lines stay empty, and lines
+// starting inside a pre or textarea element are left as they are.
+func indentTemplateCalls(output []byte) []byte {
+	if !bytes.Contains(output, []byte(indentMarkerStart)) {
+		return output
+	}
+
+	var (
+		result       = make([]byte, 0, len(output))
+		indentations []string // One per {{ template }} call the position is in
+		pending      bool     // A line started in a call, and is not indented yet
+		rawDepth     int
+	)
+
+	for i := 0; i < len(output); {
+		rest := output[i:]
+
+		if bytes.HasPrefix(rest, []byte(indentMarkerStart)) {
+			end := bytes.IndexByte(rest, indentMarkerDelimiter)
+			indentations = append(indentations, string(rest[len(indentMarkerStart):end]))
+			i += end + 1
+			continue
+		}
+		if bytes.HasPrefix(rest, []byte(indentMarkerEnd)) {
+			indentations = indentations[:len(indentations)-1]
+			i += len(indentMarkerEnd)
+			continue
+		}
+
+		if output[i] == '\n' {
+			result = append(result, '\n')
+			pending = len(indentations) > 0 && rawDepth == 0
+			i++
+			continue
+		}
+
+		if pending {
+			result = append(result, strings.Join(indentations, "")...)
+			pending = false
+		}
+		if output[i] == '<' {
+			rawDepth = max(rawDepth+rawTextElementChange(rest), 0) // A stray closing tag opens nothing
+		}
+		result = append(result, output[i])
+		i++
+	}
+
+	return result
+}
+
+// rawTextElementChange returns 1 if tag opens a raw text element, -1 if it
+// closes one, and 0 otherwise.
+func rawTextElementChange(tag []byte) int {
+	for _, name := range rawTextElements {
+		for prefix, change := range map[string]int{"<" + name: 1, "</" + name: -1} {
+			if len(tag) <= len(prefix) || !strings.EqualFold(string(tag[:len(prefix)]), prefix) {
+				continue
+			}
+			if next := tag[len(prefix)]; next == '>' || next == ' ' || next == '\t' || next == '\n' || next == '/' {
+				return change
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package temingo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate_AutoIndent(t *testing.T) {
+	// Partials are wrapped by Render(), so this does the same
+	wrap := func(name string, content string) string {
+		return "{{ define \"" + name + "\" -}}\n" + content + "\n{{- end -}}"
+	}
+
+	tests := []struct {
+		name         string
+		template     string
+		partials     map[string]string
+		noAutoIndent bool
+		want         string
+	}{
+		{
+			name:     "partial lines follow the call-site indentation",
+			template: "<body>\n    {{ template \"nav.partial.html\" . }}\n</body>",
+			partials: map[string]string{"nav.partial.html": "<nav>\n  <a href=\"/\">Home</a>\n</nav>"},
+			want:     "<body>\n    <nav>\n      <a href=\"/\">Home</a>\n    </nav>\n</body>",
+		},
+		{
+			name:     "indentation is that of the line, wherever the call is on it",
+			template: "\t<div>{{ template \"list.partial.html\" }}</div>",
+			partials: map[string]string{"list.partial.html": "<ul>\n</ul>"},
+			want:     "\t<div><ul>\n\t</ul></div>",
+		},
+		{
+			name:     "nested calls add up",
+			template: "<main>\n  {{ template \"outer.partial.html\" }}\n</main>",
+			partials: map[string]string{
+				"outer.partial.html": "<section>\n  {{ template \"inner.partial.html\" }}\n</section>",
+				"inner.partial.html": "<p>\n  text\n</p>",
+			},
+			want: "<main>\n  <section>\n    <p>\n      text\n    </p>\n  </section>\n</main>",
+		},
+		{
+			name:     "blank lines stay empty",
+			template: "  {{ template \"p.partial.html\" }}",
+			partials: map[string]string{"p.partial.html": "<p>a</p>\n\n<p>b</p>"},
+			want:     "  <p>a</p>\n\n  <p>b</p>",
+		},
+		{
+			name:     "pre and textarea content is left alone",
+			template: "    {{ template \"code.partial.html\" }}",
+			partials: map[string]string{"code.partial.html": "<PRE class=\"x\">line 1\n  line 2\n</PRE>\n<textarea>\na\n</textarea>\n<p>after</p>"},
+			want:     "    <PRE class=\"x\">line 1\n  line 2\n</PRE>\n    <textarea>\na\n</textarea>\n    <p>after</p>",
+		},
+		{
+			name:     "trim markers still trim",
+			template: "<ul>\n  {{- template \"items.partial.html\" -}}\n</ul>",
+			partials: map[string]string{"items.partial.html": "<li>a</li>\n<li>b</li>"},
+			want:     "<ul><li>a</li>\n  <li>b</li></ul>",
+		},
+		{
+			name:         "disabled",
+			template:     "<body>\n    {{ template \"nav.partial.html\" . }}\n</body>",
+			partials:     map[string]string{"nav.partial.html": "<nav>\n</nav>"},
+			noAutoIndent: true,
+			want:         "<body>\n    <nav>\n</nav>\n</body>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := DefaultEngine()
+			engine.NoAutoIndent = tt.noAutoIndent
+
+			partialFiles := map[string]string{}
+			for name, content := range tt.partials {
+				partialFiles[name] = wrap(name, content)
+			}
+
+			rendered, err := engine.renderTemplate(map[string]interface{}{}, "index.template.html", tt.template, partialFiles)
+			if err != nil {
+				t.Fatalf("renderTemplate() unexpected error: %v", err)
+			}
+			if string(rendered) != tt.want {
+				t.Errorf("renderTemplate() = %q, want %q", rendered, tt.want)
+			}
+			if strings.Contains(string(rendered), "\x1e") {
+				t.Errorf("renderTemplate() left an indentation marker in %q", rendered)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate_AutoIndentKeepsPositions(t *testing.T) {
+	var (
+		partialFiles = map[string]string{"a.partial.html": "{{ define \"a.partial.html\" }}a{{ end }}"}
+		meta         = map[string]interface{}{"meta": map[string]interface{}{"title": "Title"}}
+		content      = "<p>\n  {{- template \"a.partial.html\" }} {{ .meta.title.nope }}"
+		errs         []string
+	)
+	for _, noAutoIndent := range []bool{false, true} {
+		engine := DefaultEngine()
+		engine.NoAutoIndent = noAutoIndent
+		_, err := engine.renderTemplate(meta, "index.template.html", content, partialFiles)
+		if err == nil {
+			t.Fatalf("renderTemplate() expected an error")
+		}
+		errs = append(errs, err.Error())
+	}
+
+	// The action after the call is at the same line and column either way
+	if !strings.Contains(errs[0], "index.template.html:2:") || errs[0] != errs[1] {
+		t.Errorf("renderTemplate() error = %q, want the same as without auto indentation, %q", errs[0], errs[1])
+	}
+}
@@ -1,7 +1,9 @@
 package temingo
 
 import (
+	prettifycss "github.com/thetillhoff/temingo/pkg/prettifyCSS"
 	prettifyhtml "github.com/thetillhoff/temingo/pkg/prettifyHTML"
+	prettifyjs "github.com/thetillhoff/temingo/pkg/prettifyJS"
 )
 
 func (engine Engine) beautify(content []byte, ext string) []byte {
@@ -11,6 +13,12 @@ func (engine Engine) beautify(content []byte, ext string) []byte {
 	case ".html":
 		logger.Debug("Beautifying content", "extension", ext)
 		return []byte(prettifyhtml.Format(string(content))) // Meh about the conversions
+	case ".css":
+		logger.Debug("Beautifying content", "extension", ext)
+		return []byte(prettifycss.Format(string(content)))
+	case ".js":
+		logger.Debug("Beautifying content", "extension", ext)
+		return []byte(prettifyjs.Format(string(content)))
 	default:
 		return content
 	}
@@ -37,11 +37,25 @@ func TestBeautify(t *testing.T) {
 			description: "Empty content should return empty string",
 		},
 		{
-			name:        "CSS file - should return unchanged",
-			content:     []byte("body { color: red; }"),
+			name:        "CSS beautification",
+			content:     []byte("body{color:red;margin:0}"),
 			ext:         ".css",
-			expected:    "body { color: red; }",
-			description: "CSS files should be returned unchanged (not yet implemented)",
+			expected:    "body {\n  color: red;\n  margin: 0;\n}\n",
+			description: "CSS should get one declaration per line",
+		},
+		{
+			name:        "JS beautification",
+			content:     []byte("function f() {return 1}"),
+			ext:         ".js",
+			expected:    "function f() {\n  return 1\n}\n",
+			description: "JS should be indented by its braces",
+		},
+		{
+			name:        "Unbalanced CSS - should return unchanged",
+			content:     []byte("body { color: red;"),
+			ext:         ".css",
+			expected:    "body { color: red;",
+			description: "CSS that cannot be parsed should be returned unchanged",
 		},
 	}
 
@@ -76,11 +90,46 @@ func TestMinify(t *testing.T) {
 		description string
 	}{
 		{
-			name:        "HTML minification - not yet implemented",
+			name:        "HTML minification",
 			content:     []byte("<html>\n<head>\n<title>Test</title>\n</head>\n</html>"),
 			ext:         ".html",
-			expected:    "<html>\n<head>\n<title>Test</title>\n</head>\n</html>",
-			description: "Minification is not yet implemented, should return unchanged",
+			expected:    "<html><head><title>Test</title></head></html>",
+			description: "Whitespace between block-level tags should be dropped",
+		},
+		{
+			name:        "CSS minification",
+			content:     []byte("body {\n  color: red;\n}\n"),
+			ext:         ".css",
+			expected:    "body{color:red}",
+			description: "CSS should be minified",
+		},
+		{
+			name:        "JS minification",
+			content:     []byte("// greet\nalert( 'hi' );\n"),
+			ext:         ".js",
+			expected:    "alert('hi');",
+			description: "JS should be minified",
+		},
+		{
+			name:        "SVG minification",
+			content:     []byte("<svg>\n  <path d=\"M0 0\" />\n</svg>\n"),
+			ext:         ".svg",
+			expected:    "<svg><path d=\"M0 0\"/></svg>",
+			description: "SVG should be minified as XML",
+		},
+		{
+			name:        "JSON minification",
+			content:     []byte("{\n  \"a\": [1, 2]\n}\n"),
+			ext:         ".json",
+			expected:    "{\"a\":[1,2]}",
+			description: "JSON should be compacted",
+		},
+		{
+			name:        "Invalid JSON - should return unchanged",
+			content:     []byte("{ a: 1 }"),
+			ext:         ".json",
+			expected:    "{ a: 1 }",
+			description: "Invalid JSON should be kept as it is",
 		},
 		{
 			name:        "Plain text - should return unchanged",
@@ -197,10 +246,68 @@ func TestRender_WithMinify(t *testing.T) {
 		t.Fatalf("Failed to read output file: %v", err)
 	}
 
-	// Note: Minify is not yet implemented, so content should be unchanged
-	// This test documents current behavior
-	contentStr := string(content)
-	if !strings.Contains(contentStr, "<html>") {
-		t.Errorf("Render() with Minify should still produce valid HTML, got: %q", contentStr)
+	expected := "<html><head><title>Test</title></head><body><h1>Hello</h1></body></html>"
+	if string(content) != expected {
+		t.Errorf("Render() with Minify = %q, want %q", string(content), expected)
+	}
+}
+
+func TestRender_WithMinify_StaticFilesAndPre(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.template.html": "<div>\n  <pre>\n  keep  this\n</pre>\n  <textarea>  and\n this</textarea>\n</div>\n",
+		"style.css":           "a {\n  color: red;\n}\n",
+		"script.js":           "// note\nlet a = 1\n",
+		"data.json":           "{\n  \"a\": 1\n}\n",
+		"notes.txt":           "  left   as is  \n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(inputDir, name)), 0755); err != nil {
+			t.Fatalf("Failed to create input directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(inputDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(inputDir, "script.js"), 0600); err != nil {
+		t.Fatalf("Failed to chmod script.js: %v", err)
+	}
+
+	engine := DefaultEngine()
+	engine.InputDir = inputDir + string(filepath.Separator)
+	engine.OutputDir = outputDir + string(filepath.Separator)
+	engine.Minify = true
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() with Minify unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"index.html": "<div><pre>\n  keep  this\n</pre><textarea>  and\n this</textarea></div>",
+		"style.css":  "a{color:red}",
+		"script.js":  "let a=1",
+		"data.json":  "{\"a\":1}",
+		"notes.txt":  "  left   as is  \n",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", name, string(content), want)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(outputDir, "script.js"))
+	if err != nil {
+		t.Fatalf("Failed to stat script.js: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("minified static file mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
 	}
 }
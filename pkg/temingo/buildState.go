@@ -0,0 +1,65 @@
+package temingo
+
+import (
+	"slices"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// buildState is what one Render leaves behind for RenderChanged.
+type buildState struct {
+	fileList    fileIO.FileList
+	staticPaths []string
+
+	// outputs maps each rendered output path to what it was rendered from.
+	outputs map[string]renderedOutput
+	// rendered holds the beautified or minified content of every output, so an
+	// incremental rebuild can check references across the whole site without
+	// re-rendering the outputs it did not touch.
+	rendered map[string][]byte
+	// pageFiles are the generated files made from the pages and their meta and
+	// markdown, which a rebuild keeps unless the change can alter them.
+	pageFiles map[string][]byte
+
+	// The front matter, markdown pages, taxonomies and site index the build
+	// rendered with. A rebuild that reads different ones rebuilds everything.
+	frontMatter   map[string]map[string]interface{}
+	markdownPages map[string]markdownPage
+	taxonomies    map[string]map[string]*Term
+	site          *Site
+}
+
+// renderedOutput records the template or metatemplate an output was rendered
+// from, and every input file its content depends on.
+type renderedOutput struct {
+	sourcePath   string
+	metaTemplate bool
+	dependencies []string
+	firstPage    string // For a later page of a paginated listing, the output path of the first, which renders it
+}
+
+// pagesOf returns the output paths of the first page at outputPath and of the
+// later pages rendered with it, sorted.
+func (state *buildState) pagesOf(outputPath string) []string {
+	pages := []string{outputPath}
+	for pageOutputPath, output := range state.outputs {
+		if output.firstPage == outputPath {
+			pages = append(pages, pageOutputPath)
+		}
+	}
+	slices.Sort(pages)
+	return pages
+}
+
+// affectedOutputs returns the outputs that depend on the input-relative path, in
+// a stable order.
+func (state *buildState) affectedOutputs(inputPath string) []string {
+	affected := []string{}
+	for outputPath, output := range state.outputs {
+		if slices.Contains(output.dependencies, inputPath) {
+			affected = append(affected, outputPath)
+		}
+	}
+	slices.Sort(affected)
+	return affected
+}
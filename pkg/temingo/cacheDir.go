@@ -0,0 +1,14 @@
+package temingo
+
+import "path/filepath"
+
+// cacheDir returns the CacheDir joined with elem. A relative CacheDir belongs
+// to the project, so it is resolved against the folder holding the InputDir
+// rather than the working directory temingo happens to run in.
+func (engine *Engine) cacheDir(elem ...string) string {
+	dir := engine.CacheDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(filepath.Clean(engine.InputDir)), dir)
+	}
+	return filepath.Join(append([]string{dir}, elem...)...)
+}
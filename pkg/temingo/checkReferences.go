@@ -1,7 +1,9 @@
 package temingo
 
 import (
+	"bytes"
 	"fmt"
+	"html"
 	"os"
 	"path"
 
@@ -11,14 +13,17 @@ import (
 // collectFrom returns the references in one output file, dispatching on its
 // extension.
 func (engine *Engine) collectFrom(outputPath string, content []byte) []refcheck.Reference {
+	var refs []refcheck.Reference
 	switch path.Ext(outputPath) {
 	case ".html":
-		return refcheck.CollectHTML(outputPath, content)
+		refs = refcheck.CollectHTML(outputPath, content)
 	case ".css":
-		return refcheck.CollectCSS(outputPath, content)
+		refs = refcheck.CollectCSS(outputPath, content)
 	default:
 		return nil
 	}
+	refcheck.Locate(content, refs)
+	return refs
 }
 
 // checkReferences collects every reference in the rendered output and reports
@@ -26,9 +31,18 @@ func (engine *Engine) collectFrom(outputPath string, content []byte) []refcheck.
 // produced.
 //
 // It runs on the in-memory rendered content and the set of paths the build will
-// write, so it needs no filesystem reads and holds under a dry run. Under
-// Strict, any finding is returned as an error so the process exits non-zero.
-func (engine *Engine) checkReferences(rendered map[string][]byte, staticPaths []string) error {
+// write, so it holds under a dry run. Findings are reported at their position
+// in the input files, which outputs says each rendered file was made from.
+// Under Strict, any finding is returned as an error so the process exits
+// non-zero.
+func (engine *Engine) checkReferences(rendered map[string][]byte, staticPaths []string, outputs map[string]renderedOutput) error {
+	return engine.checkReferencesIn(rendered, staticPaths, outputs, nil)
+}
+
+// checkReferencesIn is checkReferences for the references in the outputs
+// checked lists only, or in every output and static file if checked is nil.
+// They still resolve against everything the build produced.
+func (engine *Engine) checkReferencesIn(rendered map[string][]byte, staticPaths []string, outputs map[string]renderedOutput, checked map[string]bool) error {
 	var refs []refcheck.Reference
 
 	outputPaths := make(map[string]bool, len(rendered)+len(staticPaths))
@@ -40,14 +54,16 @@ func (engine *Engine) checkReferences(rendered map[string][]byte, staticPaths []
 	}
 
 	for p, content := range rendered {
-		refs = append(refs, engine.collectFrom(p, content)...)
+		if checked == nil || checked[p] {
+			refs = append(refs, engine.collectFrom(p, content)...)
+		}
 	}
 
 	// Static files are copied verbatim rather than rendered, so their references
 	// are only visible by reading the source. Skipping them would leave every
 	// hand-written page and stylesheet unchecked - which is most stylesheets.
 	for _, p := range staticPaths {
-		if ext := path.Ext(p); ext != ".html" && ext != ".css" {
+		if ext := path.Ext(p); (ext != ".html" && ext != ".css") || (checked != nil && !checked[p]) {
 			continue
 		}
 		content, err := os.ReadFile(path.Join(engine.InputDir, p))
@@ -86,14 +102,26 @@ func (engine *Engine) checkReferences(rendered map[string][]byte, staticPaths []
 	findings = engine.Allow.Filter(findings)
 	refcheck.SortFindings(findings)
 
+	sourceContents := map[string][]byte{} // Read once per check, however many findings point into a file
 	for _, f := range findings {
-		engine.Logger.Warn("Reference finding",
-			"file", f.Ref.File,
+		outputPosition := Position{File: f.Ref.File, Line: f.Ref.Line, Col: f.Ref.Col}
+		var args []any
+		if output, ok := outputs[f.Ref.File]; ok {
+			args = append(args, "file", engine.referencePosition(f.Ref, output, sourceContents).String(), "output", outputPosition.String())
+		} else if _, ok := rendered[f.Ref.File]; ok { // Rendered, but from unknown sources
+			outputPosition.File = path.Join(engine.OutputDir, f.Ref.File)
+			args = append(args, "file", outputPosition.String())
+		} else { // A static file is its own source
+			outputPosition.File = path.Join(engine.InputDir, f.Ref.File)
+			args = append(args, "file", outputPosition.String())
+		}
+		args = append(args,
 			"url", f.Ref.URL,
 			"role", f.Ref.Role,
 			"category", string(f.Category),
 			"reason", f.Reason,
 		)
+		engine.Logger.Warn("Reference finding", args...)
 	}
 
 	if engine.Strict && len(findings) > 0 {
@@ -102,3 +130,27 @@ func (engine *Engine) checkReferences(rendered map[string][]byte, staticPaths []
 
 	return nil
 }
+
+// referencePosition returns where in the input files ref, found in the
+// rendered output, was written. The URL is searched in the files output was
+// rendered from - its template first, then the partials, meta yamls and
+// markdown content - and if it is in none of them, as when it is assembled from
+// values, the template itself is as close as it gets.
+func (engine *Engine) referencePosition(ref refcheck.Reference, output renderedOutput, sourceContents map[string][]byte) Position {
+	candidates := append([]string{output.sourcePath}, output.dependencies...)
+	for _, candidate := range candidates {
+		content, ok := sourceContents[candidate]
+		if !ok {
+			content, _ = os.ReadFile(engine.inputFilePath(candidate)) // An unreadable file just contains no URL
+			sourceContents[candidate] = content
+		}
+		for _, needle := range []string{ref.URL, html.EscapeString(ref.URL)} {
+			if offset := bytes.Index(content, []byte(needle)); offset >= 0 {
+				line, col := refcheck.LineCol(content, offset)
+				return Position{File: engine.inputFilePath(candidate), Line: line, Col: col}
+			}
+		}
+	}
+
+	return Position{File: path.Join(engine.InputDir, output.sourcePath)}
+}
@@ -30,7 +30,7 @@ func TestCheckReferencesNoRemoteChecks(t *testing.T) {
 		engine := DefaultEngine()
 		engine.Logger = slog.New(slog.NewTextHandler(&buf, nil))
 
-		if err := engine.checkReferences(rendered, nil); err != nil {
+		if err := engine.checkReferences(rendered, nil, nil); err != nil {
 			t.Fatalf("checkReferences() = %v", err)
 		}
 		if out := buf.String(); !strings.Contains(out, "status") {
@@ -48,7 +48,7 @@ func TestCheckReferencesNoRemoteChecks(t *testing.T) {
 		engine.NoRemoteChecks = true
 
 		before := atomic.LoadInt64(&hits)
-		if err := engine.checkReferences(rendered, nil); err != nil {
+		if err := engine.checkReferences(rendered, nil, nil); err != nil {
 			t.Fatalf("checkReferences() = %v", err)
 		}
 		if after := atomic.LoadInt64(&hits); after != before {
@@ -77,7 +77,7 @@ func TestCheckReferencesInsecureScheme(t *testing.T) {
 		engine.Logger = slog.New(slog.NewTextHandler(&buf, nil))
 		engine.NoRemoteChecks = true // isolate: no network needed for this check
 
-		if err := engine.checkReferences(rendered, nil); err != nil {
+		if err := engine.checkReferences(rendered, nil, nil); err != nil {
 			t.Fatalf("checkReferences() = %v", err)
 		}
 		if out := buf.String(); !strings.Contains(out, "insecure-scheme") {
@@ -92,7 +92,7 @@ func TestCheckReferencesInsecureScheme(t *testing.T) {
 		engine.NoRemoteChecks = true
 		engine.AllowInsecureScheme = true
 
-		if err := engine.checkReferences(rendered, nil); err != nil {
+		if err := engine.checkReferences(rendered, nil, nil); err != nil {
 			t.Fatalf("checkReferences() = %v", err)
 		}
 		if out := buf.String(); strings.Contains(out, "insecure-scheme") {
@@ -113,7 +113,7 @@ func TestCheckReferencesAppliesAllowlist(t *testing.T) {
 	engine.Logger = slog.New(slog.NewTextHandler(&buf, nil))
 	engine.NoRemoteChecks = true
 
-	if err := engine.checkReferences(rendered, nil); err != nil {
+	if err := engine.checkReferences(rendered, nil, nil); err != nil {
 		t.Fatalf("checkReferences() = %v", err)
 	}
 	if !strings.Contains(buf.String(), "missing-target") {
@@ -123,7 +123,7 @@ func TestCheckReferencesAppliesAllowlist(t *testing.T) {
 	buf.Reset()
 	engine.Allow = refcheck.Allowlist{{URL: "/nope.jpg"}}
 
-	if err := engine.checkReferences(rendered, nil); err != nil {
+	if err := engine.checkReferences(rendered, nil, nil); err != nil {
 		t.Fatalf("checkReferences() = %v", err)
 	}
 	if strings.Contains(buf.String(), "missing-target") {
@@ -206,7 +206,7 @@ func TestCheckReferences(t *testing.T) {
 			engine.Logger = slog.New(slog.NewTextHandler(&buf, nil))
 			engine.Strict = test.strict
 
-			err := engine.checkReferences(test.rendered, test.staticPaths)
+			err := engine.checkReferences(test.rendered, test.staticPaths, nil)
 
 			if test.wantErr && err == nil {
 				t.Errorf("checkReferences() = nil, want an error under strict")
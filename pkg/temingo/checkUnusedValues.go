@@ -0,0 +1,181 @@
+package temingo
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/thetillhoff/fileIO"
+	"gopkg.in/yaml.v3"
+)
+
+// unusedValue is a key of a values source that no template reads.
+type unusedValue struct {
+	File string // The values, meta or front matter file the key is set in, or "" for the global values
+	Key  string // The key path, like author.name for a nested key
+}
+
+// checkUnusedValues warns about every key of the global values, the values
+// files, the meta yamls and front matter that no template, metatemplate or
+// partial read while the build executed them, as recorded in
+// engine.valueReads. Under StrictValues, any such key is returned as an error.
+//
+// Nested keys are checked as well, unless the map they are in was read as a
+// whole, like by printing it, ranging over it or passing it to a function.
+func (engine *Engine) checkUnusedValues(fileList fileIO.FileList, metaPaths []string) error {
+	logger := engine.Logger
+	reads := engine.valueReads
+
+	engineKeys := map[string]bool{}
+	for _, key := range engine.engineMetaKeys(metaPaths) {
+		engineKeys[key] = true
+	}
+
+	var unused []unusedValue
+
+	for _, key := range reads.unused("", engine.Values, "", engineKeys) {
+		unused = append(unused, unusedValue{Key: key})
+	}
+
+	valuesFilePaths := slices.Clone(metaPaths)
+	if engine.ValuesFilename != "" {
+		valuesFilePaths = append(valuesFilePaths, fileList.FilterByFilename(engine.ValuesFilename).Files...)
+	}
+	for _, valuesFilePath := range valuesFilePaths {
+		content, err := fileIO.ReadFile(path.Join(engine.InputDir, valuesFilePath))
+		if err != nil {
+			return err
+		}
+		parsedContent := map[string]interface{}{}
+		if err = yaml.Unmarshal(content, &parsedContent); err != nil {
+			return engine.locateYAMLError(err, valuesFilePath)
+		}
+		for _, key := range reads.unused(valuesFilePath, parsedContent, "", engineKeys) {
+			unused = append(unused, unusedValue{File: valuesFilePath, Key: key})
+		}
+	}
+
+	for sourcePath, frontMatter := range engine.frontMatter {
+		for _, key := range reads.unused(sourcePath, frontMatter, "", engineKeys) {
+			unused = append(unused, unusedValue{File: sourcePath, Key: key})
+		}
+	}
+
+	slices.SortFunc(unused, func(a, b unusedValue) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Key, b.Key))
+	})
+	for _, value := range unused {
+		if value.File == "" {
+			logger.Warn("Unused value", "key", value.Key)
+		} else {
+			logger.Warn("Unused value", "key", value.Key, "file", value.File)
+		}
+	}
+
+	if engine.StrictValues && len(unused) > 0 {
+		return fmt.Errorf("%d unused values, and strict values mode is enabled", len(unused))
+	}
+
+	return nil
+}
+
+// collectReferencedNames adds every field name, function name and string
+// literal in the template content to names.
+func (engine *Engine) collectReferencedNames(content string, names map[string]bool) error {
+	tmpl, err := template.New("").Funcs(templateFuncMap(engine)).Parse(content)
+	if err != nil {
+		return err
+	}
+
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch node := node.(type) {
+		case *parse.ListNode:
+			if node == nil {
+				return
+			}
+			for _, child := range node.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(node.Pipe)
+		case *parse.PipeNode:
+			if node == nil {
+				return
+			}
+			for _, cmd := range node.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range node.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			for _, ident := range node.Ident {
+				names[ident] = true
+			}
+		case *parse.ChainNode:
+			walk(node.Node)
+			for _, field := range node.Field {
+				names[field] = true
+			}
+		case *parse.VariableNode:
+			for _, ident := range node.Ident[1:] { // The first is the variable itself
+				names[ident] = true
+			}
+		case *parse.IdentifierNode:
+			names[node.Ident] = true
+		case *parse.StringNode:
+			names[node.Text] = true
+		case *parse.IfNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.RangeNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.WithNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.TemplateNode:
+			walk(node.Pipe)
+		}
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			walk(t.Tree.Root)
+		}
+	}
+
+	return nil
+}
+
+// engineMetaKeys returns the meta keys the engine reads itself, which count as
+// read even when no template refers to them.
+func (engine *Engine) engineMetaKeys(metaPaths []string) []string {
+	keys := []string{"feed", "paginate", "recursive"}
+	keys = append(keys, engine.Taxonomies...) // Collected into .taxonomies
+	if len(engine.markdownPages) > 0 {
+		keys = append(keys, "layout") // Names the layout of a markdown page
+	}
+	if engine.Sitemap {
+		keys = append(keys, "sitemap", "lastmod", "changefreq", "priority")
+	}
+	for _, metaPath := range metaPaths {
+		meta, err := engine.readMetaFile(metaPath)
+		if err != nil { // Reported when the meta yaml is checked itself
+			continue
+		}
+		if _, ok := meta["feed"]; ok {
+			keys = append(keys, "title", "description", "author", "date", "summary") // Read for the feed and its items
+			break
+		}
+	}
+	return keys
+}
@@ -0,0 +1,221 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestCheckUnusedValues(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       map[string]interface{}
+		files        map[string]string
+		strictValues bool
+		wantWarnings []string
+		wantNoWarn   bool
+		wantErr      bool
+	}{
+		{
+			name:       "global value read as field",
+			values:     map[string]interface{}{"siteName": "x"},
+			files:      map[string]string{"index.template.html": `{{ .siteName }}`},
+			wantNoWarn: true,
+		},
+		{
+			name:         "unused global value warns",
+			values:       map[string]interface{}{"siteName": "x", "oldBanner": "y"},
+			files:        map[string]string{"index.template.html": `{{ .siteName }}`},
+			wantWarnings: []string{"key=oldBanner"},
+		},
+		{
+			name:   "value read in a partial",
+			values: map[string]interface{}{"author": "x"},
+			files: map[string]string{
+				"index.template.html": `{{ template "footer.partial.html" . }}`,
+				"footer.partial.html": `{{ $.author }}`,
+			},
+			wantNoWarn: true,
+		},
+		{
+			name:       "value read inside range, with and if",
+			values:     map[string]interface{}{"nav": []interface{}{"x"}, "social": "y", "draft": "z"},
+			files:      map[string]string{"index.template.html": `{{ range .nav }}{{ end }}{{ with .social }}{{ end }}{{ if .draft }}{{ else }}{{ end }}`},
+			wantNoWarn: true,
+		},
+		{
+			name: "meta key read through childMeta variable and by string argument",
+			files: map[string]string{
+				"blog/index.template.html": `{{ range $k, $v := sortBy "date" .childMeta }}{{ $v.value.title }}{{ end }}`,
+				"blog/a/meta.yaml":         "title: A\ndate: 2024-01-01",
+			},
+			wantNoWarn: true,
+		},
+		{
+			name: "unused meta key names its file",
+			files: map[string]string{
+				"blog/a/index.template.html": `{{ .meta.title }}`,
+				"blog/a/meta.yaml":           "title: A\nlegacyLayout: wide",
+			},
+			wantWarnings: []string{"key=legacyLayout", "file=blog/a/meta.yaml"},
+		},
+		{
+			name: "unused key in a discovered values file",
+			files: map[string]string{
+				"docs/index.template.html": `no values`,
+				"docs/values.yaml":         "navTitle: Docs",
+			},
+			wantWarnings: []string{"key=navTitle", "file=docs/values.yaml"},
+		},
+		{
+			name:         "value only read in a branch that is never executed",
+			values:       map[string]interface{}{"siteName": "x"},
+			files:        map[string]string{"index.template.html": `{{ if false }}{{ .siteName }}{{ end }}`},
+			wantWarnings: []string{"key=siteName"},
+		},
+		{
+			name:         "unused nested key",
+			values:       map[string]interface{}{"author": map[string]interface{}{"name": "x", "email": "y"}},
+			files:        map[string]string{"index.template.html": `{{ with .author }}{{ .name }}{{ end }}`},
+			wantWarnings: []string{"key=author.email"},
+		},
+		{
+			name: "nested meta key read through a variable",
+			files: map[string]string{
+				"index.template.html": `{{ $social := .meta.social }}{{ $social.mastodon }}`,
+				"meta.yaml":           "social:\n  mastodon: a\n  twitter: b",
+			},
+			wantWarnings: []string{"key=social.twitter", "file=meta.yaml"},
+		},
+		{
+			name: "nested front matter key read by index",
+			files: map[string]string{
+				"index.template.html": "---\nhero:\n  image: a.png\n---\n{{ index .meta \"hero\" \"image\" }}",
+			},
+			wantNoWarn: true,
+		},
+		{
+			name:       "map printed as a whole",
+			values:     map[string]interface{}{"author": map[string]interface{}{"name": "x", "email": "y"}},
+			files:      map[string]string{"index.template.html": `{{ .author }}`},
+			wantNoWarn: true,
+		},
+		{
+			name:       "map ranged over",
+			values:     map[string]interface{}{"links": map[string]interface{}{"a": "x", "b": "y"}},
+			files:      map[string]string{"index.template.html": `{{ range $name, $url := .links }}{{ $url }}{{ end }}`},
+			wantNoWarn: true,
+		},
+		{
+			name:         "strict values fails",
+			values:       map[string]interface{}{"oldBanner": "y"},
+			files:        map[string]string{"index.template.html": `nothing`},
+			strictValues: true,
+			wantWarnings: []string{"key=oldBanner"},
+			wantErr:      true,
+		},
+		{
+			name:         "strict values passes when everything is read",
+			values:       map[string]interface{}{"siteName": "x"},
+			files:        map[string]string{"index.template.html": `{{ index . "siteName" }}`},
+			strictValues: true,
+			wantNoWarn:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := setupWriteTestEngine(t, tt.files)
+			var buf bytes.Buffer
+			engine.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+			engine.Values = tt.values
+			engine.StrictValues = tt.strictValues
+
+			err := engine.Render()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			output := buf.String()
+			if tt.wantNoWarn {
+				if strings.Contains(output, "Unused value") {
+					t.Errorf("Render() logged unexpected warnings: %q", output)
+				}
+				return
+			}
+			for _, want := range tt.wantWarnings {
+				if !strings.Contains(output, want) {
+					t.Errorf("Render() log output missing %q, got: %q", want, output)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTemplate_ValueReadsKeepErrors(t *testing.T) {
+	templates := []string{
+		"{{ .meta.title.nope }}",
+		"{{ index 1 2 }}",
+		"{{ index .meta \"title\" \"x\" }}",
+		"{{ if .meta.title }}{{ printf \"%d\" .meta.title | len | .x }}{{ end }}",
+		"{{ range $k, $v := .meta }}{{ $v.nope }}{{ end }}",
+		"{{ with .missing }}{{ else }}{{ (index .meta \"title\").nope }}{{ end }}",
+	}
+
+	for _, content := range templates {
+		t.Run(content, func(t *testing.T) {
+			var errs []error
+			for _, instrumented := range []bool{true, false} {
+				engine := DefaultEngine()
+				if instrumented {
+					engine.valueReads = newValueReads()
+				}
+				meta := map[string]interface{}{"meta": map[string]interface{}{"title": "Home"}}
+				_, err := engine.renderTemplate(meta, "index.template.html", content, nil)
+				if err == nil {
+					t.Fatalf("renderTemplate() expected an error")
+				}
+				errs = append(errs, err)
+			}
+
+			if errs[0].Error() != errs[1].Error() {
+				t.Errorf("renderTemplate() error = %q, want the same as without recording reads, %q", errs[0], errs[1])
+			}
+			var execErr template.ExecError
+			if !errors.As(errs[0], &execErr) {
+				t.Errorf("renderTemplate() error = %#v, want a template.ExecError", errs[0])
+			}
+		})
+	}
+}
+
+func TestRender_ValueReadsKeepErrors(t *testing.T) {
+	engine, _, _ := setupWriteTestEngine(t, map[string]string{
+		"index.template.html": "{{ .meta.title.nope }}",
+		"meta.yaml":           "title: Home",
+	})
+
+	err := engine.Render()
+	if err == nil || !strings.Contains(err.Error(), "at <.meta.title.nope>: can't evaluate field nope in type interface {}") {
+		t.Errorf("Render() error = %v, want it at <.meta.title.nope>", err)
+	}
+}
+
+func TestRender_StrictValuesWritesNothing(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html": "{{ .meta.title }}",
+		"meta.yaml":           "title: Home\nstale: true",
+	})
+	engine.StrictValues = true
+
+	if err := engine.Render(); err == nil {
+		t.Fatalf("Render() with an unused meta key and StrictValues should fail")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(outputDir, "*")); len(matches) != 0 {
+		t.Errorf("Render() wrote %v despite failing", matches)
+	}
+}
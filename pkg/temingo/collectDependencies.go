@@ -0,0 +1,88 @@
+package temingo
+
+import (
+	"path"
+	"slices"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// collectDependencies returns every input file the content of outputPath is
+// derived from: its template, the partials and components the template pulls in
+// (also through other partials and components, and through its markdown when it
+// is executed as a template), the meta yamls on its tree path and in its direct children,
+// its markdown content file, the files whose front matter adds to its meta or childMeta, the values files on its tree path,
+// and the images it shows through the image function. When it reads .descendants, the meta yamls and front matter of
+// the pages at any depth below it count as well.
+//
+// It mirrors the lookups generateMetaObjectForTemplatePath makes, so the two
+// have to change together.
+func (engine *Engine) collectDependencies(outputPath string, sourcePath string, sourceContent string, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) []string {
+	dependencies := []string{sourcePath}
+
+	// Partials, transitively. A name that is no partial path was defined inside
+	// some partial file with an explicit {{ define }}; which file cannot be told
+	// without parsing, so the output depends on all of them.
+	sourceBody, _ := stripFrontMatter(sourcePath, sourceContent)
+	pending := []string{sourceBody}
+	if contentPath := engine.markdownContentPath(outputPath, fileList); contentPath != "" && engine.MarkdownTemplates { // Markdown executed as a template calls partials too
+		if content, err := engine.readMarkdownTemplate(contentPath); err == nil { // Rendering has just read it
+			pending = append(pending, content)
+		}
+	}
+	seen := map[string]bool{}
+	names := map[string]bool{} // Read by the template and its partials
+	for len(pending) > 0 {
+		content := pending[0]
+		pending = pending[1:]
+		_ = engine.collectReferencedNames(content, names) // Rendering has just parsed it
+		for _, name := range engine.calledPartials(content) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if partialContent, ok := partialFiles[name]; ok {
+				dependencies = append(dependencies, name)
+				pending = append(pending, partialContent)
+				continue
+			}
+			for partialPath, partialContent := range partialFiles {
+				if !seen[partialPath] {
+					seen[partialPath] = true
+					dependencies = append(dependencies, partialPath)
+					pending = append(pending, partialContent)
+				}
+			}
+		}
+	}
+
+	metaList := fileIO.FileList{Files: metaPaths}
+	dependencies = append(dependencies, metaList.FilterByTreePath(outputPath).Files...)
+	dependencies = append(dependencies, metaList.FilterByLevelAtFolderPath(path.Dir(outputPath), 1).Files...)
+	dependencies = append(dependencies, fileList.FilterByFolderPath(path.Dir(outputPath)).FilterByFilename(engine.MarkdownContentFilename).Files...)
+	dependencies = append(dependencies, engine.frontMatterSources(outputPath)...)
+	for _, childFolder := range engine.childPageFolders(path.Dir(outputPath), metaPaths, true) {
+		dependencies = append(dependencies, engine.folderFrontMatterSources(childFolder)...)
+	}
+	if names["descendants"] { // Only then the meta further below counts, so other pages are not rebuilt for it
+		for _, metaPath := range metaPaths {
+			if isBelowFolder(path.Dir(metaPath), path.Dir(outputPath)) {
+				dependencies = append(dependencies, metaPath)
+			}
+		}
+		for _, folder := range engine.descendantPageFolders(path.Dir(outputPath), metaPaths, true) {
+			dependencies = append(dependencies, engine.folderFrontMatterSources(folder)...)
+		}
+	}
+	if engine.ValuesFilename != "" {
+		dependencies = append(dependencies, fileList.FilterByTreePath(outputPath).FilterByFilename(engine.ValuesFilename).Files...)
+	}
+	for pageOutputPath, sourcePaths := range engine.imageReferences { // Recorded while rendering it and its later pages
+		if pageOutputPath == outputPath || isPageOf(pageOutputPath, outputPath) {
+			dependencies = append(dependencies, sourcePaths...)
+		}
+	}
+
+	slices.Sort(dependencies)
+	return slices.Compact(dependencies)
+}
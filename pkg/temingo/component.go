@@ -0,0 +1,221 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultSlot is the slot every component accepts without declaring it.
+const defaultSlot = "slot"
+
+// componentParamTypes are the types a component parameter can declare.
+var componentParamTypes = []string{"string", "int", "number", "bool", "list", "map", "any"}
+
+// component is a partial with a declared set of parameters, rendered with only
+// the arguments it is called with instead of the caller's whole context.
+type component struct {
+	name   string // What {{ component }} calls it by: the filename up to the component extension
+	path   string // The path of the component file, which is also the name its template is parsed under
+	params map[string]componentParam
+	slots  []string // The slots it accepts, the default slot included
+}
+
+// componentSchema is the front matter of a component file.
+type componentSchema struct {
+	Params map[string]componentParam `yaml:"params"`
+	Slots  []string                  `yaml:"slots"`
+}
+
+// componentParam declares one parameter of a component.
+type componentParam struct {
+	Type        string      `yaml:"type"`
+	Required    bool        `yaml:"required"`
+	Default     interface{} `yaml:"default"`
+	Description string      `yaml:"description"`
+}
+
+// componentName returns the name a component file is called by: its filename
+// up to the component extension, so components/card.component.html is card.
+// Inside the folder of a library it is prefixed with the library name, like
+// shared/card, whether it comes from the library or overrides one of its files.
+func (engine *Engine) componentName(componentPath string) string {
+	name, _, _ := strings.Cut(path.Base(componentPath), engine.ComponentExtension)
+	if namespace, _, found := strings.Cut(componentPath, "/"); found && engine.libraryNames[namespace] {
+		return namespace + "/" + name
+	}
+	return name
+}
+
+// parseComponent reads the parameters and slots a component file declares in
+// its front matter, and returns the component along with its body. The body
+// starts with as many empty lines as the front matter took up, so template
+// errors keep pointing at the right line of the file.
+func (engine *Engine) parseComponent(componentPath string, content string) (*component, string, error) {
+	comp := &component{
+		name:   engine.componentName(componentPath),
+		path:   componentPath,
+		params: map[string]componentParam{},
+		slots:  []string{defaultSlot},
+	}
+
+	frontMatter, body, lines, ok := splitFrontMatter(content, yamlFrontMatterDelimiter)
+	if !ok {
+		return comp, content, nil // A component without front matter takes no parameters, only the default slot
+	}
+
+	var schema componentSchema
+	decoder := yaml.NewDecoder(strings.NewReader(frontMatter))
+	decoder.KnownFields(true) // A misspelt key like `require` would otherwise be silently ignored
+	if err := decoder.Decode(&schema); err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", engine.locateFrontMatterError(err, componentPath, "parsing component front matter")
+	}
+
+	for name, param := range schema.Params {
+		if param.Type == "" {
+			param.Type = "any"
+		}
+		if !slices.Contains(componentParamTypes, param.Type) {
+			return nil, "", fmt.Errorf("component %s: parameter %q has unknown type %q, expected one of %s", componentPath, name, param.Type, strings.Join(componentParamTypes, ", "))
+		}
+		if param.Required && param.Default != nil {
+			return nil, "", fmt.Errorf("component %s: parameter %q is required, so its default is never used", componentPath, name)
+		}
+		if param.Default != nil {
+			if err := checkComponentArgType(param.Type, param.Default); err != nil {
+				return nil, "", fmt.Errorf("component %s: default of parameter %q: %w", componentPath, name, err)
+			}
+		}
+		comp.params[name] = param
+	}
+
+	for _, slot := range schema.Slots {
+		if _, ok := comp.params[slot]; ok {
+			return nil, "", fmt.Errorf("component %s: %q is declared as both a parameter and a slot", componentPath, slot)
+		}
+		if !slices.Contains(comp.slots, slot) {
+			comp.slots = append(comp.slots, slot)
+		}
+	}
+	if _, ok := comp.params[defaultSlot]; ok {
+		return nil, "", fmt.Errorf("component %s: %q is the default slot and cannot be declared as a parameter", componentPath, defaultSlot)
+	}
+
+	return comp, strings.Repeat("\n", lines) + body, nil
+}
+
+// arguments turns the key-value pairs a component is called with into the data
+// it is rendered with. Every parameter is present: unpassed ones hold their
+// default, or nil, and unfilled slots hold an empty string. Missing required
+// parameters, unknown keys and values of the wrong type are errors.
+func (comp *component) arguments(pairs []interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("component %s takes key-value pairs, but got %d arguments", comp.name, len(pairs))
+	}
+
+	data := make(map[string]interface{}, len(comp.params)+len(comp.slots))
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("component %s: argument %d is a key and has to be a string, not %T", comp.name, i+1, pairs[i])
+		}
+		if _, ok := data[key]; ok {
+			return nil, fmt.Errorf("component %s: %q is passed twice", comp.name, key)
+		}
+		value := pairs[i+1]
+
+		if param, ok := comp.params[key]; ok {
+			if param.Required && value == nil {
+				return nil, fmt.Errorf("component %s: required parameter %q is passed an empty value", comp.name, key)
+			}
+			if err := checkComponentArgType(param.Type, value); err != nil {
+				return nil, fmt.Errorf("component %s: parameter %q: %w", comp.name, key, err)
+			}
+		} else if slices.Contains(comp.slots, key) {
+			if err := checkComponentArgType("string", value); err != nil {
+				return nil, fmt.Errorf("component %s: slot %q: %w", comp.name, key, err)
+			}
+		} else {
+			return nil, fmt.Errorf("component %s has no parameter or slot %q; it accepts %s", comp.name, key, strings.Join(comp.accepted(), ", "))
+		}
+		data[key] = value
+	}
+
+	var missing []string
+	for name, param := range comp.params {
+		if _, ok := data[name]; ok {
+			continue
+		}
+		if param.Required {
+			missing = append(missing, name)
+		}
+		data[name] = param.Default
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("component %s: missing required parameters: %s", comp.name, strings.Join(missing, ", "))
+	}
+	for _, slot := range comp.slots {
+		if _, ok := data[slot]; !ok {
+			data[slot] = ""
+		}
+	}
+
+	return data, nil
+}
+
+// accepted returns the sorted names of the parameters and slots of comp.
+func (comp *component) accepted() []string {
+	names := slices.Clone(comp.slots)
+	for name := range comp.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkComponentArgType returns an error if value is not of the declared
+// parameter type. nil passes for every type, so an optional parameter can be
+// passed a value that is not set.
+func checkComponentArgType(paramType string, value interface{}) error {
+	if value == nil || paramType == "any" {
+		return nil
+	}
+
+	kind := reflect.TypeOf(value).Kind()
+	var ok bool
+	switch paramType {
+	case "string":
+		ok = kind == reflect.String
+	case "int":
+		ok = isIntKind(kind)
+	case "number":
+		ok = isIntKind(kind) || kind == reflect.Float32 || kind == reflect.Float64
+	case "bool":
+		ok = kind == reflect.Bool
+	case "list":
+		ok = kind == reflect.Slice || kind == reflect.Array
+	case "map":
+		ok = kind == reflect.Map
+	}
+	if !ok {
+		return fmt.Errorf("expected %s, got %T", paramType, value)
+	}
+	return nil
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
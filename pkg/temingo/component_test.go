@@ -0,0 +1,232 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cardComponent = `---
+params:
+  title:
+    type: string
+    required: true
+  href:
+    type: string
+    default: "#"
+  tags:
+    type: list
+slots: [footer]
+---
+<div class="card">
+  <a href="{{ .href }}">{{ .title }}</a>
+  {{- range .tags }} <span>{{ . }}</span>{{ end }}
+  {{ .slot }}
+  <footer>{{ .footer }}</footer>
+</div>`
+
+func TestRender_Components(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "arguments and defaults",
+			template: `{{ component "card" "title" .title }}`,
+			want:     "<div class=\"card\">\n  <a href=\"#\">Hello</a>\n  \n  <footer></footer>\n</div>",
+		},
+		{
+			name:     "typed arguments",
+			template: `{{ component "card" "title" "T" "href" "/t" "tags" .tags }}`,
+			want:     "<div class=\"card\">\n  <a href=\"/t\">T</a> <span>a</span> <span>b</span>\n  \n  <footer></footer>\n</div>",
+		},
+		{
+			name:     "slots filled by capture",
+			template: `{{ define "body" }}<p>{{ .title }}</p>{{ end }}{{ component "card" "title" "T" "slot" (capture "body" .) "footer" "bye" }}`,
+			want:     "<div class=\"card\">\n  <a href=\"#\">T</a>\n  <p>Hello</p>\n  <footer>bye</footer>\n</div>",
+		},
+		{
+			name:     "output indented like the call",
+			template: "<main>\n  {{ component \"card\" \"title\" .title }}\n</main>",
+			want:     "<main>\n  <div class=\"card\">\n    <a href=\"#\">Hello</a>\n    \n    <footer></footer>\n  </div>\n</main>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+				"components/card.component.html": cardComponent,
+				"index.template.html":            tt.template,
+			})
+			engine.Values = map[string]interface{}{"title": "Hello", "tags": []interface{}{"a", "b"}}
+
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+			if got := readOutput(t, outputDir, "index.html"); got != tt.want {
+				t.Errorf("Render() index.html = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_ComponentErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantError string
+	}{
+		{
+			name:      "missing required parameter",
+			files:     map[string]string{"index.template.html": `{{ component "card" "href" "/" }}`},
+			wantError: "component card: missing required parameters: title",
+		},
+		{
+			name:      "wrong type",
+			files:     map[string]string{"index.template.html": `{{ component "card" "title" 3 }}`},
+			wantError: `component card: parameter "title": expected string, got int`,
+		},
+		{
+			name:      "unknown parameter",
+			files:     map[string]string{"index.template.html": `{{ component "card" "title" "T" "titel" "T" }}`},
+			wantError: `component card has no parameter or slot "titel"; it accepts footer, href, slot, tags, title`,
+		},
+		{
+			name:      "odd number of arguments",
+			files:     map[string]string{"index.template.html": `{{ component "card" "title" }}`},
+			wantError: "component card takes key-value pairs, but got 1 arguments",
+		},
+		{
+			name:      "unknown component",
+			files:     map[string]string{"index.template.html": `{{ component "crad" }}`},
+			wantError: `no component named "crad"; known components are card`,
+		},
+		{
+			name: "component calling itself",
+			files: map[string]string{
+				"index.template.html": `{{ component "loop" }}`,
+				"loop.component.html": `{{ component "loop" }}`,
+			},
+			wantError: "calls nest more than 100 levels deep",
+		},
+		{
+			name: "unknown parameter type",
+			files: map[string]string{
+				"index.template.html":  `{{ component "badge" }}`,
+				"badge.component.html": "---\nparams:\n  size:\n    type: integer\n---\n{{ .size }}",
+			},
+			wantError: `parameter "size" has unknown type "integer"`,
+		},
+		{
+			name: "default of the wrong type",
+			files: map[string]string{
+				"index.template.html":  `{{ component "badge" }}`,
+				"badge.component.html": "---\nparams:\n  size:\n    type: int\n    default: large\n---\n{{ .size }}",
+			},
+			wantError: `default of parameter "size": expected int, got string`,
+		},
+		{
+			name: "duplicate component name",
+			files: map[string]string{
+				"index.template.html":      `{{ component "card" "title" "T" }}`,
+				"blog/card.component.html": `x`,
+			},
+			wantError: "duplicate component name 'card'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"components/card.component.html": cardComponent}
+			for name, content := range tt.files {
+				files[name] = content
+			}
+			engine, _, _ := setupWriteTestEngine(t, files)
+
+			err := engine.Render()
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Fatalf("Render() error = %v, want it to contain %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestRender_ComponentErrorPositions(t *testing.T) {
+	t.Run("front matter", func(t *testing.T) {
+		engine, inputDir, _ := setupWriteTestEngine(t, map[string]string{
+			"index.template.html":  `{{ component "badge" }}`,
+			"badge.component.html": "---\nparams:\n  size:\n    require: true\n---\n{{ .size }}",
+		})
+
+		err := engine.Render()
+		var sourceErr *SourceError
+		if !errors.As(err, &sourceErr) {
+			t.Fatalf("Render() error = %v, want a SourceError", err)
+		}
+		if want := filepath.Join(inputDir, "badge.component.html"); sourceErr.File != want || sourceErr.Line != 4 {
+			t.Errorf("Render() error at %s:%d, want %s:4", sourceErr.File, sourceErr.Line, want)
+		}
+	})
+
+	t.Run("body", func(t *testing.T) {
+		engine, inputDir, _ := setupWriteTestEngine(t, map[string]string{
+			"index.template.html":  "\n{{ component \"badge\" \"size\" 1 }}",
+			"badge.component.html": "---\nparams:\n  size: {type: int}\n---\n\n{{ .size.value }}",
+		})
+
+		err := engine.Render()
+		if err == nil {
+			t.Fatal("Render() error = nil, want an error")
+		}
+		for _, want := range []string{filepath.Join(inputDir, "index.template.html") + ":2:", filepath.Join(inputDir, "badge.component.html") + ":6:"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Render() error = %v, want it to contain %q", err, want)
+			}
+		}
+	})
+}
+
+func TestRender_UnusedComponentWarning(t *testing.T) {
+	var buf bytes.Buffer
+	engine, _, _ := setupWriteTestEngine(t, map[string]string{
+		"components/card.component.html": cardComponent,
+		"button.component.html":          `<button>{{ .slot }}</button>`,
+		"index.template.html":            `{{ component "card" "title" "T" }}`,
+	})
+	engine.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, `msg="Unused component" path=button.component.html`) || strings.Contains(out, "card.component.html") {
+		t.Errorf("expected only button.component.html to be reported as unused, got:\n%s", out)
+	}
+}
+
+func TestRenderChanged_ComponentRebuildsTheCallers(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"components/card.component.html": cardComponent,
+		"index.template.html":            `{{ component "card" "title" "T" }}`,
+		"about/index.template.html":      `about`,
+	})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	markOutputs(t, outputDir, "index.html", "about/index.html")
+
+	writeTestFiles(t, inputDir, map[string]string{"components/card.component.html": "---\nparams:\n  title: {type: string}\n---\n<h2>{{ .title }}</h2>"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "components/card.component.html")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+
+	if got := readOutput(t, outputDir, "index.html"); got != "<h2>T</h2>" {
+		t.Errorf("RenderChanged() index.html = %q, want %q", got, "<h2>T</h2>")
+	}
+	if got := readOutput(t, outputDir, "about/index.html"); got != "untouched" {
+		t.Errorf("RenderChanged() rewrote about/index.html, which calls no component")
+	}
+}
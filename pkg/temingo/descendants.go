@@ -0,0 +1,107 @@
+package temingo
+
+import (
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// Descendant is a folder below the folder of a page that is a page itself, or
+// has pages below it. Every template gets the ones directly below its folder as
+// .descendants, by folder name, each with its own as Children.
+type Descendant struct {
+	Name string
+	Path string // Relative to the folder of the page, like guides/install
+	// Meta is the meta of the folder's page, as its parent sees it in
+	// .childMeta, or nil for a folder that only has pages below it.
+	Meta     interface{}
+	Children map[string]*Descendant
+}
+
+// descendantMeta returns the tree of the page folders below folder, with their
+// meta. The pages of a folder share it, so it is built once per build.
+func (engine *Engine) descendantMeta(folder string, metaPaths []string) (map[string]*Descendant, error) {
+	if tree, ok := engine.descendants[folder]; ok {
+		return tree, nil
+	}
+	tree, err := engine.descendantTree(folder, folder, engine.descendantPageFolders(folder, metaPaths, true), metaPaths)
+	if err != nil {
+		return nil, err
+	}
+	if engine.descendants != nil {
+		engine.descendants[folder] = tree
+	}
+	return tree, nil
+}
+
+// descendantTree returns the descendants directly below folder, for the page in
+// root, out of pageFolders.
+func (engine *Engine) descendantTree(root string, folder string, pageFolders []string, metaPaths []string) (map[string]*Descendant, error) {
+	tree := map[string]*Descendant{}
+	var childMeta map[string]interface{}
+	for _, pageFolder := range pageFolders {
+		if !isBelowFolder(pageFolder, folder) {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(pageFolder, strings.TrimPrefix(folder+"/", "./")), "/")
+		if _, ok := tree[name]; ok {
+			continue
+		}
+		if childMeta == nil { // Only read for a folder with pages below it
+			var err error
+			if _, childMeta, err = engine.getMetaForTemplatePath(fileIO.FileList{Files: metaPaths}, path.Join(folder, "index.html")); err != nil {
+				return nil, err
+			}
+		}
+
+		childFolder := path.Join(folder, name)
+		descendant := &Descendant{Name: name, Path: strings.TrimPrefix(childFolder, strings.TrimPrefix(root+"/", "./")), Meta: childMeta[name]}
+		children, err := engine.descendantTree(root, childFolder, pageFolders, metaPaths)
+		if err != nil {
+			return nil, err
+		}
+		descendant.Children = children
+		tree[name] = descendant
+	}
+	return tree, nil
+}
+
+// descendantPageFolders returns the folders at any depth below folder that
+// are pages, like childPageFolders does for the direct ones.
+func (engine *Engine) descendantPageFolders(folder string, metaPaths []string, templates bool) []string {
+	folders := []string{}
+	for _, metaPath := range metaPaths {
+		if isBelowFolder(path.Dir(metaPath), folder) {
+			folders = append(folders, path.Dir(metaPath))
+		}
+	}
+	for _, sourcePath := range slices.Sorted(maps.Keys(engine.frontMatter)) {
+		if !isBelowFolder(path.Dir(sourcePath), folder) {
+			continue
+		}
+		if path.Base(sourcePath) == engine.MarkdownContentFilename || (templates && !engine.isMetaTemplate(sourcePath) && !engine.isMarkdownPage(sourcePath) && !engine.isTermTemplate(sourcePath)) {
+			folders = append(folders, path.Dir(sourcePath))
+		}
+	}
+	slices.Sort(folders)
+	return slices.Compact(folders)
+}
+
+// isBelowFolder reports whether dir is below folder, at any depth.
+func isBelowFolder(dir string, folder string) bool {
+	if folder == "." {
+		return dir != "."
+	}
+	return strings.HasPrefix(dir, folder+"/")
+}
+
+// isRecursiveMetaTemplate reports whether the metatemplate at metaTemplatePath
+// declares recursive: true in its front matter, to render for every folder
+// below its own that is a page, instead of only the direct ones.
+func (engine *Engine) isRecursiveMetaTemplate(metaTemplatePath string) bool {
+	recursive, _ := engine.frontMatter[metaTemplatePath]["recursive"].(bool)
+	return recursive
+}
@@ -0,0 +1,133 @@
+package temingo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// docsFiles returns docs whose landing page lists the pages two levels down,
+// below a guides folder that is no page itself.
+func docsFiles() map[string]string {
+	return map[string]string{
+		"docs/index.template.html":           `{{ range .descendants }}{{ .Path }}={{ with .Meta }}{{ .title }}{{ end }}[{{ range .Children }}{{ .Path }}={{ .Meta.title }} {{ end }}]{{ end }}`,
+		"docs/guides/install/meta.yaml":      "title: Install\n",
+		"docs/guides/deploy/content.md":      "---\ntitle: Deploy\n---\ndeploy",
+		"docs/reference/index.template.html": "---\ntitle: Reference\n---\n{{ .meta.title }}",
+		"index.template.html":                `{{ range $name, $child := .childMeta }}{{ $name }}{{ end }}`,
+	}
+}
+
+func TestRender_Descendants(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, docsFiles())
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	want := "guides=[guides/deploy=Deploy guides/install=Install ]reference=Reference[]"
+	if got := readOutput(t, outputDir, "docs/index.html"); got != want {
+		t.Errorf("Render() docs/index.html = %q, want %q", got, want)
+	}
+}
+
+func TestRender_DescendantsOnlyWhenReferenced(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":    `{{ range $key, $_ := . }}{{ $key }} {{ end }}`,
+		"docs/install/meta.yaml": "title: Install\n",
+	})
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "index.html"); strings.Contains(got, "descendants") {
+		t.Errorf("Render() index.html = %q, want no .descendants while no template refers to it", got)
+	}
+}
+
+func TestRender_RecursiveMetaTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		recursive string
+		want      map[string]string
+		wantNone  []string
+	}{
+		{
+			name:      "renders at any depth",
+			recursive: "recursive: true\n",
+			want: map[string]string{
+				"blog/a/index.html":      "A post",
+				"blog/2024/b/index.html": "B post",
+			},
+			wantNone: []string{"blog/2024/index.html"}, // No page itself
+		},
+		{
+			name: "renders for the direct subfolders without",
+			want: map[string]string{
+				"blog/a/index.html": "A post",
+			},
+			wantNone: []string{"blog/2024/b/index.html"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+				"blog/index.metatemplate.html": "---\n" + tt.recursive + "layout: post\n---\n{{ .meta.title }} {{ .meta.layout }}",
+				"blog/a/meta.yaml":             "title: A\n",
+				"blog/2024/b/meta.yaml":        "title: B\n",
+			})
+
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+			for outputPath, want := range tt.want {
+				if got := readOutput(t, outputDir, outputPath); got != want {
+					t.Errorf("Render() %s = %q, want %q", outputPath, got, want)
+				}
+			}
+			for _, outputPath := range tt.wantNone {
+				if _, err := os.Stat(filepath.Join(outputDir, outputPath)); !os.IsNotExist(err) {
+					t.Errorf("Render() rendered %s (%v)", outputPath, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_RecursiveMetaTemplateCollision(t *testing.T) {
+	engine, _, _ := setupWriteTestEngine(t, map[string]string{
+		"blog/index.metatemplate.html":   "---\nrecursive: true\n---\nouter",
+		"blog/x/index.metatemplate.html": "inner",
+		"blog/x/meta.yaml":               "title: X\n",
+		"blog/x/y/meta.yaml":             "title: Y\n",
+	})
+
+	err := engine.Render()
+	if err == nil || !strings.Contains(err.Error(), "both render to blog/x/y/index.html") {
+		t.Errorf("Render() error = %v, want the metatemplates to collide", err)
+	}
+}
+
+func TestRenderChanged_Descendants(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, docsFiles())
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if deps := engine.lastBuild.outputs["index.html"].dependencies; slices.Contains(deps, "docs/guides/install/meta.yaml") {
+		t.Errorf("dependencies of index.html = %v, want no meta below its children, since it does not read .descendants", deps)
+	}
+
+	writeTestFiles(t, inputDir, map[string]string{"docs/guides/install/meta.yaml": "title: Installation\n"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "docs/guides/install/meta.yaml")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "docs/index.html"); !strings.Contains(got, "guides/install=Installation") {
+		t.Errorf("RenderChanged() docs/index.html = %q, want the new title", got)
+	}
+	if !slices.Equal(engine.ChangedOutputs(), []string{"docs/index.html"}) {
+		t.Errorf("RenderChanged() changed %v, want only docs/index.html", engine.ChangedOutputs())
+	}
+}
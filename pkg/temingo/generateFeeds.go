@@ -0,0 +1,528 @@
+package temingo
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thetillhoff/fileIO"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// feedFormats are the feed formats a feed block can ask for, each with the
+// file it is written to in the feed's folder.
+var feedFormats = map[string]string{
+	"rss":  "feed.xml",
+	"atom": "atom.xml",
+	"json": "feed.json",
+}
+
+// defaultFeedLimit is how many items a feed lists when its block sets no limit.
+const defaultFeedLimit = 20
+
+// feed is one folder's feed: the folder's own settings, and an item for each
+// direct child folder with a meta yaml.
+type feed struct {
+	folder      string
+	title       string
+	description string
+	author      string
+	formats     []string
+	items       []feedItem
+}
+
+// feedItem is one child folder of a feed.
+type feedItem struct {
+	title   string
+	url     string
+	date    time.Time
+	summary string
+	author  string
+	content string // The html of the child's markdown content, if it has any
+}
+
+// generateFeeds returns the feed files for every folder whose meta yaml has a
+// feed block, by output path.
+//
+// A feed lists the direct child folders with a meta yaml or front matter - the
+// ones a template in the folder sees as .childMeta - newest first by their date, with their
+// title, summary, author and markdown content. Each item links to the page
+// rendered in its folder, preferring an index.html.
+func (engine *Engine) generateFeeds(rendered map[string][]byte, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	for _, metaPath := range metaPaths {
+		meta, err := engine.readMetaFile(metaPath)
+		if err != nil {
+			return nil, err
+		}
+		block, ok := meta["feed"]
+		if !ok {
+			continue
+		}
+
+		feed, err := engine.readFeed(path.Dir(metaPath), meta, block, rendered, fileList, metaPaths, partialFiles)
+		if err != nil {
+			return nil, fmt.Errorf("feed in %s: %w", metaPath, err)
+		}
+
+		for _, format := range feed.formats {
+			feedPath := path.Join(feed.folder, feedFormats[format])
+			var content []byte
+			switch format {
+			case "rss":
+				content, err = engine.marshalRSS(feed, feedPath)
+			case "atom":
+				content, err = engine.marshalAtom(feed, feedPath)
+			case "json":
+				content, err = engine.marshalJSONFeed(feed, feedPath)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("encoding feed %s: %w", feedPath, err)
+			}
+			files[feedPath] = content
+		}
+	}
+
+	return files, nil
+}
+
+// readMetaFile parses the meta yaml at metaPath on its own, without the meta
+// of its parent folders.
+func (engine *Engine) readMetaFile(metaPath string) (map[string]interface{}, error) {
+	content, err := fileIO.ReadFile(path.Join(engine.InputDir, metaPath))
+	if err != nil {
+		return nil, err
+	}
+	meta := map[string]interface{}{}
+	if err = yaml.Unmarshal(content, &meta); err != nil {
+		return nil, engine.locateYAMLError(err, metaPath)
+	}
+	return meta, nil
+}
+
+// readFeed reads the feed block of the folder's meta, and its items.
+func (engine *Engine) readFeed(folder string, meta map[string]interface{}, block interface{}, rendered map[string][]byte, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) (feed, error) {
+	settings, ok := block.(map[string]interface{})
+	if block != nil && !ok {
+		return feed{}, fmt.Errorf("feed must be a map of settings, got %v", block)
+	}
+	if engine.BaseURL == "" {
+		return feed{}, fmt.Errorf("feeds require a baseURL, since feeds list absolute URLs")
+	}
+
+	f := feed{
+		folder:      folder,
+		title:       stringField(settings, "title", stringField(meta, "title", "")),
+		description: stringField(settings, "description", stringField(meta, "description", "")),
+		author:      stringField(settings, "author", stringField(meta, "author", "")),
+		formats:     []string{"rss", "atom", "json"},
+	}
+
+	if formats, ok := settings["formats"]; ok {
+		list, ok := formats.([]interface{})
+		if !ok {
+			return f, fmt.Errorf("formats must be a list of rss, atom and json, got %v", formats)
+		}
+		f.formats = nil
+		for _, format := range list {
+			format, ok := format.(string)
+			if _, known := feedFormats[format]; !ok || !known {
+				return f, fmt.Errorf("formats must be a list of rss, atom and json, got %v", formats)
+			}
+			if !slices.Contains(f.formats, format) {
+				f.formats = append(f.formats, format)
+			}
+		}
+	}
+
+	limit := defaultFeedLimit
+	if value, ok := settings["limit"]; ok {
+		if limit, ok = value.(int); !ok || limit < 0 {
+			return f, fmt.Errorf("limit must be a number of items, or 0 for all of them, got %v", value)
+		}
+	}
+
+	for _, childFolder := range engine.childPageFolders(folder, metaPaths, true) {
+		item, ok, err := engine.readFeedItem(childFolder, rendered, fileList, metaPaths, partialFiles)
+		if err != nil {
+			return f, err
+		}
+		if ok {
+			f.items = append(f.items, item)
+		}
+	}
+
+	slices.SortFunc(f.items, func(a, b feedItem) int {
+		return cmp.Or(b.date.Compare(a.date), cmp.Compare(a.url, b.url)) // Newest first
+	})
+	if limit > 0 && len(f.items) > limit {
+		f.items = f.items[:limit]
+	}
+
+	return f, nil
+}
+
+// readFeedItem reads the feed item for the child folder, from its meta yaml,
+// the front matter of its page and its markdown content. A folder without a
+// date is skipped with a warning, since a feed cannot place it.
+func (engine *Engine) readFeedItem(folder string, rendered map[string][]byte, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) (feedItem, bool, error) {
+	var (
+		err        error
+		meta       = map[string]interface{}{}
+		dateSource string // The file that sets the date, or should
+	)
+	if metaPath := path.Join(folder, engine.MetaFilename); slices.Contains(metaPaths, metaPath) {
+		if meta, err = engine.readMetaFile(metaPath); err != nil {
+			return feedItem{}, false, err
+		}
+		dateSource = metaPath
+	}
+	for _, sourcePath := range engine.folderFrontMatterSources(folder) {
+		meta = overrideMeta(meta, engine.frontMatter[sourcePath])
+		if _, ok := engine.frontMatter[sourcePath]["date"]; ok || dateSource == "" {
+			dateSource = sourcePath
+		}
+	}
+
+	item := feedItem{
+		title:   stringField(meta, "title", path.Base(folder)),
+		summary: stringField(meta, "summary", ""),
+		author:  stringField(meta, "author", ""),
+		url:     engine.absoluteURL(feedItemPage(folder, rendered)),
+	}
+
+	date, ok := meta["date"]
+	if !ok {
+		engine.Logger.Warn("Skipping feed item without a date", "path", dateSource)
+		return item, false, nil
+	}
+	if item.date, _, err = parseMetaDate(date); err != nil {
+		return item, false, fmt.Errorf("date in %s %w", dateSource, err)
+	}
+
+	markdownContentFiles := fileList.FilterByFolderPath(folder).FilterByFilename(engine.MarkdownContentFilename).Files
+	if len(markdownContentFiles) == 1 { // Can only be 1 at max
+		if engine.MarkdownTemplates { // Executed with the meta object of the page the item links to, so it reads the same as there
+			pageMeta, err := engine.generateMetaObjectForTemplatePath(feedItemPage(folder, rendered), fileList, metaPaths, partialFiles)
+			if err != nil {
+				return item, false, err
+			}
+			item.content, _ = pageMeta["content"].(string)
+		} else {
+			markdownContent, err := fileIO.ReadFile(path.Join(engine.InputDir, markdownContentFiles[0]))
+			if err != nil {
+				return item, false, err
+			}
+			body, _ := stripFrontMatter(markdownContentFiles[0], string(markdownContent))
+			content, err := engine.Markdown.Convert([]byte(body))
+			if err != nil {
+				return item, false, &SourceError{Position: Position{File: path.Join(engine.InputDir, markdownContentFiles[0])}, Err: err}
+			}
+			item.content = string(content)
+		}
+		item.content = absoluteLinks(item.content, item.url)
+	}
+
+	return item, true, nil
+}
+
+// absoluteLinks resolves the relative href, src and srcset urls in the html
+// content against pageURL. Feed readers resolve them against the feed instead
+// of the page the content was written for, which breaks them.
+func absoluteLinks(content string, pageURL string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return content
+	}
+	resolve := func(ref string) string {
+		parsed, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil || parsed.IsAbs() || ref == "" {
+			return ref
+		}
+		return base.ResolveReference(parsed).String()
+	}
+
+	var (
+		buf       strings.Builder
+		tokenizer = html.NewTokenizer(strings.NewReader(content))
+	)
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return buf.String()
+		}
+		raw := string(tokenizer.Raw())
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			buf.WriteString(raw)
+			continue
+		}
+
+		token := tokenizer.Token()
+		changed := false
+		for i, attr := range token.Attr {
+			var value string
+			switch attr.Key {
+			case "href", "src":
+				value = resolve(attr.Val)
+			case "srcset": // Comma separated urls, each followed by an optional size
+				candidates := strings.Split(attr.Val, ",")
+				for j, candidate := range candidates {
+					fields := strings.Fields(candidate)
+					if len(fields) > 0 {
+						fields[0] = resolve(fields[0])
+					}
+					candidates[j] = strings.Join(fields, " ")
+				}
+				value = strings.Join(candidates, ", ")
+			default:
+				continue
+			}
+			if value != attr.Val {
+				token.Attr[i].Val = value
+				changed = true
+			}
+		}
+		if changed {
+			buf.WriteString(token.String())
+		} else {
+			buf.WriteString(raw)
+		}
+	}
+}
+
+// feedItemPage returns the output path an item in folder links to: the
+// folder's index.html if there is one, else the first html page rendered in
+// it, else the folder itself.
+func feedItemPage(folder string, rendered map[string][]byte) string {
+	var pages []string
+	for outputPath := range rendered {
+		if path.Dir(outputPath) == folder && path.Ext(outputPath) == ".html" {
+			pages = append(pages, outputPath)
+		}
+	}
+	if len(pages) == 0 || slices.Contains(pages, path.Join(folder, "index.html")) {
+		return path.Join(folder, "index.html")
+	}
+	slices.Sort(pages)
+	return pages[0]
+}
+
+// stringField returns the string at key in fields, or fallback if it is not
+// set or not a string.
+func stringField(fields map[string]interface{}, key string, fallback string) string {
+	if value, ok := fields[key].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// updated is the date of the newest item, which is when the feed last changed.
+// It is taken from the items rather than the clock, so an unchanged feed is
+// written byte for byte the same.
+func (f feed) updated() time.Time {
+	if len(f.items) == 0 {
+		return time.Unix(0, 0).UTC()
+	}
+	return f.items[0].date
+}
+
+type rssDocument struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	XmlnsAtom    string     `xml:"xmlns:atom,attr"`
+	XmlnsContent string     `xml:"xmlns:content,attr"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description,omitempty"`
+	Content     string  `xml:"content:encoded,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// marshalRSS encodes the feed as RSS 2.0.
+func (engine *Engine) marshalRSS(f feed, feedPath string) ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.title,
+		Link:        engine.absoluteURL(path.Join(f.folder, "index.html")),
+		Description: cmp.Or(f.description, f.title), // RSS requires a description
+		AtomLink:    rssLink{Href: engine.absoluteURL(feedPath), Rel: "self", Type: "application/rss+xml"},
+	}
+	if len(f.items) > 0 {
+		channel.LastBuildDate = f.updated().Format(time.RFC1123Z)
+	}
+	for _, item := range f.items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.title,
+			Link:        item.url,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.url},
+			PubDate:     item.date.Format(time.RFC1123Z),
+			Description: item.summary,
+			Content:     item.content,
+		})
+	}
+
+	return marshalXML(rssDocument{
+		Version:      "2.0",
+		XmlnsAtom:    "http://www.w3.org/2005/Atom",
+		XmlnsContent: "http://purl.org/rss/1.0/modules/content/",
+		Channel:      channel,
+	})
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Links   []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Link    atomLink     `xml:"link"`
+	Updated string       `xml:"updated"`
+	Author  *atomAuthor  `xml:"author,omitempty"`
+	Summary string       `xml:"summary,omitempty"`
+	Content *atomContent `xml:"content,omitempty"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// marshalAtom encodes the feed as Atom.
+func (engine *Engine) marshalAtom(f feed, feedPath string) ([]byte, error) {
+	folderURL := engine.absoluteURL(path.Join(f.folder, "index.html"))
+
+	document := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Title:   f.title,
+		ID:      folderURL,
+		Links:   []atomLink{{Href: folderURL}, {Href: engine.absoluteURL(feedPath), Rel: "self"}},
+		Updated: f.updated().Format(time.RFC3339),
+		Author:  atomAuthor{Name: cmp.Or(f.author, f.title)}, // Atom requires an author
+	}
+	for _, item := range f.items {
+		entry := atomEntry{
+			Title:   item.title,
+			ID:      item.url,
+			Link:    atomLink{Href: item.url},
+			Updated: item.date.Format(time.RFC3339),
+			Summary: item.summary,
+		}
+		if item.author != "" {
+			entry.Author = &atomAuthor{Name: item.author}
+		}
+		if item.content != "" {
+			entry.Content = &atomContent{Type: "html", Value: item.content}
+		}
+		document.Entries = append(document.Entries, entry)
+	}
+
+	return marshalXML(document)
+}
+
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+}
+
+// marshalJSONFeed encodes the feed as JSON Feed 1.1.
+func (engine *Engine) marshalJSONFeed(f feed, feedPath string) ([]byte, error) {
+	document := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.title,
+		HomePageURL: engine.absoluteURL(path.Join(f.folder, "index.html")),
+		FeedURL:     engine.absoluteURL(feedPath),
+		Description: f.description,
+		Items:       []jsonFeedItem{},
+	}
+	if f.author != "" {
+		document.Authors = []jsonFeedAuthor{{Name: f.author}}
+	}
+	for _, item := range f.items {
+		jsonItem := jsonFeedItem{
+			ID:            item.url,
+			URL:           item.url,
+			Title:         item.title,
+			ContentHTML:   item.content,
+			Summary:       item.summary,
+			DatePublished: item.date.Format(time.RFC3339),
+		}
+		if item.author != "" {
+			jsonItem.Authors = []jsonFeedAuthor{{Name: item.author}}
+		}
+		document.Items = append(document.Items, jsonItem)
+	}
+
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
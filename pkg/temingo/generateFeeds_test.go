@@ -0,0 +1,250 @@
+package temingo
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender_Feeds(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"blog/index.template.html":    `{{ range .childMeta }}{{ .title }}{{ end }}`,
+		"blog/meta.yaml":              "title: Blog\ndescription: Posts\nfeed:\n  author: Jane\n",
+		"blog/post.metatemplate.html": "{{ .meta.title }}{{ .content }}",
+		"blog/first/meta.yaml":        "title: First\ndate: 2024-01-01\nsummary: The first one",
+		"blog/first/content.md":       "# Hello",
+		"blog/second/meta.yaml":       "title: Second\ndate: 2024-02-01T10:00:00Z",
+		"blog/second/content.md":      "World, [more](../first/post.html) ![](pic.png)",
+		"blog/second/pic.png":         "png",
+		"blog/draft/meta.yaml":        "title: Draft", // Skipped, since it has no date
+	})
+	engine.BaseURL = "https://example.com/"
+	engine.StrictValues = true // Keys read by the feed are not unused
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	var rss struct {
+		Channel struct {
+			Title string   `xml:"title"`
+			Links []string `xml:"link"` // The channel link, then the empty atom:link to the feed itself
+			Items []struct {
+				Title       string `xml:"title"`
+				Link        string `xml:"link"`
+				PubDate     string `xml:"pubDate"`
+				Description string `xml:"description"`
+				Content     string `xml:"encoded"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	readFeed(t, filepath.Join(outputDir, "blog", "feed.xml"), xml.Unmarshal, &rss)
+	if rss.Channel.Title != "Blog" || len(rss.Channel.Links) == 0 || rss.Channel.Links[0] != "https://example.com/blog/" {
+		t.Errorf("RSS channel = %q at %q, want Blog at https://example.com/blog/", rss.Channel.Title, rss.Channel.Links)
+	}
+	if len(rss.Channel.Items) != 2 {
+		t.Fatalf("RSS lists %d items, want 2", len(rss.Channel.Items))
+	}
+	if item := rss.Channel.Items[0]; item.Title != "Second" || item.Link != "https://example.com/blog/second/post.html" || item.PubDate != "Thu, 01 Feb 2024 10:00:00 +0000" {
+		t.Errorf("RSS first item = %+v, want the newest post", item)
+	}
+	if item := rss.Channel.Items[1]; item.Description != "The first one" || !strings.Contains(item.Content, `<h1 id="hello">Hello</h1>`) {
+		t.Errorf("RSS second item = %+v, want its summary and content", item)
+	}
+
+	var atom struct {
+		Author  string `xml:"author>name"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	readFeed(t, filepath.Join(outputDir, "blog", "atom.xml"), xml.Unmarshal, &atom)
+	if atom.Author != "Jane" || atom.Updated != "2024-02-01T10:00:00Z" {
+		t.Errorf("Atom feed by %q updated %q, want Jane and the newest date", atom.Author, atom.Updated)
+	}
+	if len(atom.Entries) != 2 || atom.Entries[0].ID != "https://example.com/blog/second/post.html" || !strings.Contains(atom.Entries[0].Content, "World") {
+		t.Errorf("Atom entries = %+v, want the newest post first with its content", atom.Entries)
+	}
+	if content := atom.Entries[0].Content; !strings.Contains(content, `href="https://example.com/blog/first/post.html"`) || !strings.Contains(content, `src="https://example.com/blog/second/pic.png"`) {
+		t.Errorf("Atom entry content = %q, want its links resolved against the post's URL", content)
+	}
+
+	var jsonFeed struct {
+		Version string `json:"version"`
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			URL           string `json:"url"`
+			DatePublished string `json:"date_published"`
+		} `json:"items"`
+	}
+	readFeed(t, filepath.Join(outputDir, "blog", "feed.json"), json.Unmarshal, &jsonFeed)
+	if jsonFeed.Version != "https://jsonfeed.org/version/1.1" || jsonFeed.FeedURL != "https://example.com/blog/feed.json" {
+		t.Errorf("JSON feed = %+v, want version 1.1 at its own URL", jsonFeed)
+	}
+	if len(jsonFeed.Items) != 2 || jsonFeed.Items[1].DatePublished != "2024-01-01T00:00:00Z" {
+		t.Errorf("JSON feed items = %+v, want the oldest post last", jsonFeed.Items)
+	}
+}
+
+func TestRender_FeedItemsFromFrontMatter(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"blog/meta.yaml":              "title: Blog\nfeed:\n  formats: [json]\n",
+		"blog/post.metatemplate.html": "{{ .meta.title }}",
+		"blog/first/content.md":       "---\ntitle: First\ndate: 2024-01-01\n---\nHello",
+	})
+	engine.BaseURL = "https://example.com/"
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	var jsonFeed struct {
+		Items []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			ContentHTML string `json:"content_html"`
+		} `json:"items"`
+	}
+	readFeed(t, filepath.Join(outputDir, "blog", "feed.json"), json.Unmarshal, &jsonFeed)
+	if len(jsonFeed.Items) != 1 {
+		t.Fatalf("JSON feed lists %d items, want 1", len(jsonFeed.Items))
+	}
+	if item := jsonFeed.Items[0]; item.Title != "First" || item.URL != "https://example.com/blog/first/post.html" || item.ContentHTML != "<p>Hello</p>\n" {
+		t.Errorf("JSON feed item = %+v, want the post with its front matter stripped from the content", item)
+	}
+}
+
+func TestRender_FeedSettings(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"news/index.template.html":   "news",
+		"news/meta.yaml":             "feed:\n  title: News\n  formats: [json]\n  limit: 1\n",
+		"news/a/meta.yaml":           "date: 2024-01-01",
+		"news/a/index.template.html": "a",
+		"news/b/meta.yaml":           "date: 2024-03-01",
+		"news/b/index.template.html": "b",
+	})
+	engine.BaseURL = "https://example.com/"
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	for _, unwanted := range []string{"feed.xml", "atom.xml"} {
+		if _, err := os.Stat(filepath.Join(outputDir, "news", unwanted)); err == nil {
+			t.Errorf("news/%s was written, but only json was asked for", unwanted)
+		}
+	}
+
+	var jsonFeed struct {
+		Title string `json:"title"`
+		Items []struct {
+			URL   string `json:"url"`
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	readFeed(t, filepath.Join(outputDir, "news", "feed.json"), json.Unmarshal, &jsonFeed)
+	if jsonFeed.Title != "News" {
+		t.Errorf("JSON feed title = %q, want News", jsonFeed.Title)
+	}
+	if len(jsonFeed.Items) != 1 || jsonFeed.Items[0].URL != "https://example.com/news/b/" || jsonFeed.Items[0].Title != "b" {
+		t.Errorf("JSON feed items = %+v, want only the newest, titled by its folder", jsonFeed.Items)
+	}
+}
+
+func TestRender_FeedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		baseURL string
+		wantErr string
+	}{
+		{
+			name: "no base url",
+			files: map[string]string{
+				"blog/index.template.html": "blog",
+				"blog/meta.yaml":           "feed: {}",
+			},
+			wantErr: "feeds require a baseURL",
+		},
+		{
+			name: "unknown format",
+			files: map[string]string{
+				"blog/index.template.html": "blog",
+				"blog/meta.yaml":           "feed:\n  formats: [rdf]",
+			},
+			baseURL: "https://example.com/",
+			wantErr: "formats must be a list of rss, atom and json",
+		},
+		{
+			name: "hand-written feed",
+			files: map[string]string{
+				"blog/index.template.html": "blog",
+				"blog/meta.yaml":           "feed: {}",
+				"blog/feed.xml":            "<rss/>",
+			},
+			baseURL: "https://example.com/",
+			wantErr: "blog/feed.xml is generated, but the input directory has one as well",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := setupWriteTestEngine(t, tt.files)
+			engine.BaseURL = tt.baseURL
+
+			err := engine.Render()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAbsoluteLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "relative links and images",
+			content: `<p><a href="../other/">x</a> <img src="a.png" alt="a"></p>`,
+			want:    `<p><a href="https://example.com/blog/other/">x</a> <img src="https://example.com/blog/post/a.png" alt="a"></p>`,
+		},
+		{
+			name:    "absolute urls and fragments",
+			content: `<a href="https://other.org/">x</a><a href="mailto:a@b.c">y</a><a href="#top">z</a>`,
+			want:    `<a href="https://other.org/">x</a><a href="mailto:a@b.c">y</a><a href="https://example.com/blog/post/#top">z</a>`,
+		},
+		{
+			name:    "srcset",
+			content: `<img srcset="a-640w.webp 640w, /b.webp 2x">`,
+			want:    `<img srcset="https://example.com/blog/post/a-640w.webp 640w, https://example.com/b.webp 2x">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := absoluteLinks(tt.content, "https://example.com/blog/post/"); got != tt.want {
+				t.Errorf("absoluteLinks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// readFeed reads the feed file at filePath and decodes it into v.
+func readFeed(t *testing.T, filePath string, unmarshal func([]byte, any) error, v any) {
+	t.Helper()
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", filePath, err)
+	}
+	if err = unmarshal(content, v); err != nil {
+		t.Fatalf("Failed to decode %s: %v\n%s", filePath, err, content)
+	}
+}
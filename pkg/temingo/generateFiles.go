@@ -0,0 +1,95 @@
+package temingo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// generateFiles returns the files the engine generates from the build itself
+// rather than from a template, like the sitemap, the feeds, the stylesheet
+// for highlighted code and the image variants, by output path.
+func (engine *Engine) generateFiles(rendered map[string][]byte, staticPaths []string, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) (map[string][]byte, error) {
+	generated, err := engine.generatePageFiles(rendered, staticPaths, fileList, metaPaths, partialFiles)
+	if err != nil {
+		return nil, err
+	}
+	return engine.withImageVariants(generated, rendered, staticPaths)
+}
+
+// generatePageFiles returns the generated files that are made from the pages
+// and their meta and markdown - the sitemap and the feeds - and the stylesheet
+// for highlighted code, by output path.
+func (engine *Engine) generatePageFiles(rendered map[string][]byte, staticPaths []string, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) (map[string][]byte, error) {
+	generated := map[string][]byte{}
+
+	if engine.Sitemap {
+		sitemaps, err := engine.generateSitemap(rendered, staticPaths, metaPaths)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(generated, sitemaps)
+	}
+
+	feeds, err := engine.generateFeeds(rendered, fileList, metaPaths, partialFiles)
+	if err != nil {
+		return nil, err
+	}
+	for feedPath, content := range feeds {
+		if _, ok := generated[feedPath]; ok {
+			return nil, fmt.Errorf("%s is generated twice, as a feed and as a sitemap", feedPath)
+		}
+		generated[feedPath] = content
+	}
+
+	if engine.Markdown.HighlightStyle != "" {
+		stylesheet, err := engine.Markdown.Stylesheet()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := generated[engine.HighlightStylesheet]; ok {
+			return nil, fmt.Errorf("%s is generated twice, as the highlight stylesheet and as a feed or sitemap", engine.HighlightStylesheet)
+		}
+		generated[engine.HighlightStylesheet] = engine.postProcess(stylesheet, ".css")
+	}
+
+	return generated, nil
+}
+
+// withImageVariants returns the generated files with the image variants the
+// rendered templates asked for added to them. No generated file may take the
+// place of a rendered or static one.
+func (engine *Engine) withImageVariants(pageFiles map[string][]byte, rendered map[string][]byte, staticPaths []string) (map[string][]byte, error) {
+	generated := maps.Clone(pageFiles)
+	images, err := engine.generateImageVariants()
+	if err != nil {
+		return nil, err
+	}
+	for imagePath, content := range images {
+		if _, ok := generated[imagePath]; ok {
+			return nil, fmt.Errorf("%s is generated twice, as an image variant and as another generated file", imagePath)
+		}
+		generated[imagePath] = content
+	}
+
+	// A generated file silently replacing a hand-written one would be a surprise either way
+	for generatedPath := range generated {
+		if _, ok := rendered[generatedPath]; ok || slices.Contains(staticPaths, generatedPath) {
+			return nil, fmt.Errorf("%s is generated, but the input directory has one as well", generatedPath)
+		}
+	}
+
+	return generated, nil
+}
+
+// marshalXML encodes a generated xml file with its xml declaration.
+func marshalXML(document interface{}) ([]byte, error) {
+	content, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
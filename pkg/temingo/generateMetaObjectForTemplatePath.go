@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/thetillhoff/fileIO"
-	"github.com/thetillhoff/temingo/pkg/markdown2html"
 )
 
 // Breadcrumb represents a single breadcrumb with its name and path
@@ -14,7 +13,7 @@ type Breadcrumb struct {
 	Path string
 }
 
-func (engine Engine) generateMetaObjectForTemplatePath(renderedTemplatePath string, fileList fileIO.FileList, metaPaths []string) (map[string]interface{}, error) {
+func (engine Engine) generateMetaObjectForTemplatePath(renderedTemplatePath string, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) (map[string]interface{}, error) {
 	logger := engine.Logger
 
 	var (
@@ -35,6 +34,15 @@ func (engine Engine) generateMetaObjectForTemplatePath(renderedTemplatePath stri
 	// c should not be added, as the index.html is meant for that folder
 	meta["breadcrumbs"] = createBreadcrumbs(renderedTemplatePath)
 
+	// with .site
+	meta["site"] = engine.site
+
+	// with .taxonomies, and .term on the page of a term
+	meta["taxonomies"] = engine.taxonomies
+	if termPage, ok := engine.termPages[renderedTemplatePath]; ok {
+		meta["term"] = termPage.term
+	}
+
 	// with .meta and .childMeta
 	logger.Debug("Searching metadata", "path", renderedTemplatePath)
 	meta["meta"], meta["childMeta"], err = engine.getMetaForTemplatePath(fileIO.FileList{Files: metaPaths}, renderedTemplatePath) // Contains aggregated meta yamls (up to parent dir, were children overwrite their parents values during the merge)
@@ -42,19 +50,13 @@ func (engine Engine) generateMetaObjectForTemplatePath(renderedTemplatePath stri
 		return meta, err
 	}
 
-	// with .content
-	markdownContentFiles := fileList.FilterByFolderPath(path.Dir(renderedTemplatePath)).FilterByFilename(engine.MarkdownContentFilename).Files
-	if len(markdownContentFiles) == 1 { // Can only be 1 at max
-		logger.Debug("Getting markdown content", "path", renderedTemplatePath)
-		markdownContent, err := fileIO.ReadFile(path.Join(engine.InputDir, markdownContentFiles[0])) // Read file contents
-		if err != nil {
-			return meta, err
-		}
-		content, err := markdown2html.Convert(markdownContent) // Convert markdown to html and assign it to `.content`
+	// with .descendants, the page folders below this one at any depth. It reads
+	// the meta of every page below, so only if some template refers to it.
+	if engine.referencedNames["descendants"] {
+		meta["descendants"], err = engine.descendantMeta(path.Dir(renderedTemplatePath), metaPaths)
 		if err != nil {
 			return meta, err
 		}
-		meta["content"] = string(content)
 	}
 
 	// with .<values>
@@ -62,6 +64,46 @@ func (engine Engine) generateMetaObjectForTemplatePath(renderedTemplatePath stri
 		meta[key] = value
 	}
 
+	// with .<values> from the values files on the tree path, which override the global ones
+	directoryValues, err := engine.getValuesForTemplatePath(fileList, renderedTemplatePath)
+	if err != nil {
+		return meta, err
+	}
+	for key, value := range directoryValues {
+		meta[key] = value
+	}
+	valuesFilePaths := []string{""} // The global values
+	if engine.ValuesFilename != "" {
+		valuesFilePaths = append(valuesFilePaths, fileList.FilterByTreePath(renderedTemplatePath).FilterByFilename(engine.ValuesFilename).Files...)
+	}
+	engine.valueReads.register(meta, valuesFilePaths, "")
+	for key, value := range engine.Values {
+		engine.valueReads.track(value, []string{""}, key+".")
+	}
+	for key, value := range directoryValues {
+		engine.valueReads.track(value, valuesFilePaths[1:], key+".")
+	}
+
+	// with .content, last so markdown executed as a template sees everything else
+	if contentPath := engine.markdownContentPath(renderedTemplatePath, fileList); contentPath != "" {
+		logger.Debug("Getting markdown content", "path", renderedTemplatePath)
+		body, err := engine.readMarkdownContent(contentPath, meta, partialFiles)
+		if err != nil {
+			return meta, err
+		}
+		document, err := engine.convertMarkdown(contentPath, body) // Convert markdown to html and assign it to `.content`
+		if err != nil {
+			return meta, err
+		}
+		meta["content"] = string(document.HTML)
+
+		// with .toc, .summary, .wordCount and .readingTime, read from the same parsed markdown
+		meta["toc"] = document.TOC
+		meta["summary"] = document.Summary
+		meta["wordCount"] = document.WordCount
+		meta["readingTime"] = document.ReadingTime
+	}
+
 	return meta, nil
 }
 
@@ -14,7 +14,7 @@ func TestGenerateMetaObjectForTemplatePath(t *testing.T) {
 		name                 string
 		renderedTemplatePath string
 		setup                func(tmpDir string) (fileIO.FileList, []string, error)
-		engineValues         map[string]string
+		engineValues         map[string]interface{}
 		wantPath             string
 		wantBreadcrumbs      []Breadcrumb
 		wantHasContent       bool
@@ -29,7 +29,7 @@ func TestGenerateMetaObjectForTemplatePath(t *testing.T) {
 				// No files needed for basic test
 				return fileIO.FileList{Files: []string{}}, []string{}, nil
 			},
-			engineValues:     map[string]string{},
+			engineValues:     map[string]interface{}{},
 			wantPath:         "index.html",
 			wantBreadcrumbs:  []Breadcrumb{},
 			wantHasContent:   false,
@@ -43,7 +43,7 @@ func TestGenerateMetaObjectForTemplatePath(t *testing.T) {
 			setup: func(tmpDir string) (fileIO.FileList, []string, error) {
 				return fileIO.FileList{Files: []string{}}, []string{}, nil
 			},
-			engineValues: map[string]string{},
+			engineValues: map[string]interface{}{},
 			wantPath:     "blog/posts/index.html",
 			wantBreadcrumbs: []Breadcrumb{
 				{Name: "blog", Path: "/blog/"},
@@ -77,7 +77,7 @@ func TestGenerateMetaObjectForTemplatePath(t *testing.T) {
 					Path:  inputDir,
 				}, []string{}, nil
 			},
-			engineValues:     map[string]string{},
+			engineValues:     map[string]interface{}{},
 			wantPath:         "about/index.html",
 			wantBreadcrumbs:  []Breadcrumb{},
 			wantHasContent:   true,
@@ -109,7 +109,7 @@ func TestGenerateMetaObjectForTemplatePath(t *testing.T) {
 					Path:  inputDir,
 				}, []string{"blog/meta.yaml"}, nil
 			},
-			engineValues:     map[string]string{},
+			engineValues:     map[string]interface{}{},
 			wantPath:         "blog/index.html",
 			wantBreadcrumbs:  []Breadcrumb{},
 			wantHasContent:   false,
@@ -123,7 +123,7 @@ func TestGenerateMetaObjectForTemplatePath(t *testing.T) {
 			setup: func(tmpDir string) (fileIO.FileList, []string, error) {
 				return fileIO.FileList{Files: []string{}}, []string{}, nil
 			},
-			engineValues: map[string]string{
+			engineValues: map[string]interface{}{
 				"siteName": "My Site",
 				"version":  "1.0.0",
 			},
@@ -150,7 +150,7 @@ func TestGenerateMetaObjectForTemplatePath(t *testing.T) {
 			engine.InputDir = filepath.Join(tmpDir, "input") + string(filepath.Separator)
 			engine.Values = tt.engineValues
 
-			meta, err := engine.generateMetaObjectForTemplatePath(tt.renderedTemplatePath, fileList, metaPaths)
+			meta, err := engine.generateMetaObjectForTemplatePath(tt.renderedTemplatePath, fileList, metaPaths, nil)
 			if err != nil {
 				t.Fatalf("generateMetaObjectForTemplatePath() unexpected error: %v", err)
 			}
@@ -196,7 +196,7 @@ func TestGenerateMetaObjectForTemplatePath(t *testing.T) {
 			// Check values
 			for key, expectedValue := range tt.engineValues {
 				if meta[key] != expectedValue {
-					t.Errorf("meta[%q] = %q, want %q", key, meta[key], expectedValue)
+					t.Errorf("meta[%q] = %v, want %v", key, meta[key], expectedValue)
 				}
 			}
 		})
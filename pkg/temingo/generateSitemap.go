@@ -0,0 +1,184 @@
+package temingo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// sitemapPath is where the sitemap, or the sitemap index when it is split, is
+// written.
+const sitemapPath = "sitemap.xml"
+
+// sitemapXmlns is the namespace of both sitemaps and sitemap indexes.
+const sitemapXmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapMaxURLs is the most URLs one sitemap file may list, per the sitemap
+// protocol. More than that are split into numbered files under an index.
+var sitemapMaxURLs = 50000
+
+// sitemapChangefreqs are the values the sitemap protocol allows for changefreq.
+var sitemapChangefreqs = []string{"always", "hourly", "daily", "weekly", "monthly", "yearly", "never"}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	Lastmod    string `xml:"lastmod,omitempty"`
+	Changefreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name          `xml:"sitemapindex"`
+	Xmlns    string            `xml:"xmlns,attr"`
+	Sitemaps []sitemapIndexRef `xml:"sitemap"`
+}
+
+type sitemapIndexRef struct {
+	Loc string `xml:"loc"`
+}
+
+// generateSitemap returns the sitemap files for the html outputs and static
+// html files, by output path. Pages are listed under the BaseURL, with the
+// lastmod, changefreq and priority of their meta, and left out when their meta
+// sets sitemap to false. Beyond sitemapMaxURLs pages, sitemap.xml becomes an
+// index of numbered sitemap files.
+func (engine *Engine) generateSitemap(rendered map[string][]byte, staticPaths []string, metaPaths []string) (map[string][]byte, error) {
+	var pagePaths []string
+	for outputPath := range rendered {
+		if path.Ext(outputPath) == ".html" {
+			pagePaths = append(pagePaths, outputPath)
+		}
+	}
+	for _, staticPath := range staticPaths {
+		if path.Ext(staticPath) == ".html" {
+			pagePaths = append(pagePaths, staticPath)
+		}
+	}
+	slices.Sort(pagePaths)
+
+	urls := []sitemapURL{}
+	for _, pagePath := range pagePaths {
+		if pagePath == "404.html" { // An error page is no page to index
+			continue
+		}
+
+		meta, _, err := engine.getMetaForTemplatePath(fileIO.FileList{Files: metaPaths}, pagePath)
+		if err != nil {
+			return nil, err
+		}
+		entry, include, err := engine.sitemapEntry(pagePath, meta)
+		if err != nil {
+			return nil, fmt.Errorf("sitemap entry for %s: %w", pagePath, err)
+		}
+		if include {
+			urls = append(urls, entry)
+		}
+	}
+
+	files := map[string][]byte{}
+	if len(urls) <= sitemapMaxURLs {
+		content, err := marshalXML(sitemapURLSet{Xmlns: sitemapXmlns, URLs: urls})
+		if err != nil {
+			return nil, fmt.Errorf("encoding sitemap: %w", err)
+		}
+		files[sitemapPath] = content
+	} else {
+		index := sitemapIndex{Xmlns: sitemapXmlns}
+		for i := 0; i*sitemapMaxURLs < len(urls); i++ {
+			chunkPath := fmt.Sprintf("sitemap-%d.xml", i+1)
+			chunk := urls[i*sitemapMaxURLs : min((i+1)*sitemapMaxURLs, len(urls))]
+			content, err := marshalXML(sitemapURLSet{Xmlns: sitemapXmlns, URLs: chunk})
+			if err != nil {
+				return nil, fmt.Errorf("encoding sitemap: %w", err)
+			}
+			files[chunkPath] = content
+			index.Sitemaps = append(index.Sitemaps, sitemapIndexRef{Loc: engine.absoluteURL(chunkPath)})
+		}
+		content, err := marshalXML(index)
+		if err != nil {
+			return nil, fmt.Errorf("encoding sitemap index: %w", err)
+		}
+		files[sitemapPath] = content
+	}
+
+	return files, nil
+}
+
+// sitemapEntry returns the sitemap entry for the page at pagePath, and whether
+// its meta leaves it in the sitemap.
+func (engine *Engine) sitemapEntry(pagePath string, meta interface{}) (sitemapURL, bool, error) {
+	entry := sitemapURL{Loc: engine.absoluteURL(pagePath)}
+
+	fields, _ := meta.(map[string]interface{})
+
+	if include, ok := fields["sitemap"]; ok {
+		include, ok := include.(bool)
+		if !ok {
+			return entry, false, fmt.Errorf("sitemap must be true or false, got %v", fields["sitemap"])
+		}
+		if !include {
+			return entry, false, nil
+		}
+	}
+
+	if lastmod, ok := fields["lastmod"]; ok {
+		date, dateOnly, err := parseMetaDate(lastmod)
+		if err != nil {
+			return entry, false, fmt.Errorf("lastmod %w", err)
+		}
+		if dateOnly {
+			entry.Lastmod = date.Format(time.DateOnly)
+		} else {
+			entry.Lastmod = date.Format(time.RFC3339)
+		}
+	}
+
+	if changefreq, ok := fields["changefreq"]; ok {
+		changefreq, ok := changefreq.(string)
+		if !ok || !slices.Contains(sitemapChangefreqs, changefreq) {
+			return entry, false, fmt.Errorf("changefreq must be one of %s, got %v", strings.Join(sitemapChangefreqs, ", "), fields["changefreq"])
+		}
+		entry.Changefreq = changefreq
+	}
+
+	if priority, ok := fields["priority"]; ok {
+		var value float64
+		switch priority := priority.(type) {
+		case int:
+			value = float64(priority)
+		case float64:
+			value = priority
+		default:
+			return entry, false, fmt.Errorf("priority must be a number from 0.0 to 1.0, got %v", priority)
+		}
+		if value < 0 || value > 1 {
+			return entry, false, fmt.Errorf("priority must be a number from 0.0 to 1.0, got %v", priority)
+		}
+		entry.Priority = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	return entry, true, nil
+}
+
+// absoluteURL returns the URL an output is served at under the BaseURL. An
+// index.html is addressed by its folder.
+func (engine *Engine) absoluteURL(outputPath string) string {
+	urlPath := outputPath
+	if path.Base(urlPath) == "index.html" {
+		urlPath = strings.TrimSuffix(urlPath, "index.html")
+	}
+	return strings.TrimSuffix(engine.BaseURL, "/") + "/" + (&url.URL{Path: urlPath}).EscapedPath()
+}
@@ -0,0 +1,193 @@
+package temingo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender_Sitemap(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":         `<a href="/sitemap.xml">sitemap</a>`,
+		"404.template.html":           "not found",
+		"about.template.html":         "about",
+		"blog/index.template.html":    "blog",
+		"blog/meta.yaml":              "changefreq: weekly\npriority: 0.8",
+		"blog/post.metatemplate.html": "{{ .meta.title }}",
+		"blog/first/meta.yaml":        "title: First\nlastmod: 2024-03-01",
+		"blog/second/meta.yaml":       "title: Second\nsitemap: false",
+		"static/legal.html":           "<p>legal</p>",
+		"styles.css":                  "body {}",
+		"drafts/index.template.html":  "draft",
+		"drafts/meta.yaml":            "sitemap: false",
+	})
+	engine.Sitemap = true
+	engine.BaseURL = "https://example.com/docs"
+	engine.Strict = true // The link to the sitemap must resolve
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read sitemap.xml: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/docs/about.html</loc>
+  </url>
+  <url>
+    <loc>https://example.com/docs/blog/first/post.html</loc>
+    <lastmod>2024-03-01</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/docs/blog/</loc>
+    <changefreq>weekly</changefreq>
+    <priority>0.8</priority>
+  </url>
+  <url>
+    <loc>https://example.com/docs/</loc>
+  </url>
+  <url>
+    <loc>https://example.com/docs/static/legal.html</loc>
+  </url>
+</urlset>
+`
+	if string(content) != want {
+		t.Errorf("sitemap.xml =\n%s\nwant\n%s", content, want)
+	}
+}
+
+func TestRender_SitemapIndex(t *testing.T) {
+	previous := sitemapMaxURLs
+	sitemapMaxURLs = 2
+	t.Cleanup(func() { sitemapMaxURLs = previous })
+
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"a.template.html": "a",
+		"b.template.html": "b",
+		"c.template.html": "c",
+	})
+	engine.Sitemap = true
+	engine.BaseURL = "https://example.com/"
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read sitemap.xml: %v", err)
+	}
+	for _, want := range []string{"<sitemapindex", "<loc>https://example.com/sitemap-1.xml</loc>", "<loc>https://example.com/sitemap-2.xml</loc>"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("sitemap.xml missing %q, got:\n%s", want, index)
+		}
+	}
+
+	first, err := os.ReadFile(filepath.Join(outputDir, "sitemap-1.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read sitemap-1.xml: %v", err)
+	}
+	if got := strings.Count(string(first), "<url>"); got != 2 {
+		t.Errorf("sitemap-1.xml lists %d URLs, want 2", got)
+	}
+	second, err := os.ReadFile(filepath.Join(outputDir, "sitemap-2.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read sitemap-2.xml: %v", err)
+	}
+	if !strings.Contains(string(second), "<loc>https://example.com/c.html</loc>") {
+		t.Errorf("sitemap-2.xml missing c.html, got:\n%s", second)
+	}
+}
+
+func TestRender_SitemapErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "invalid changefreq",
+			files: map[string]string{
+				"index.template.html": "home",
+				"meta.yaml":           "changefreq: sometimes",
+			},
+			wantErr: "changefreq must be one of",
+		},
+		{
+			name: "priority out of range",
+			files: map[string]string{
+				"index.template.html": "home",
+				"meta.yaml":           "priority: 2",
+			},
+			wantErr: "priority must be a number from 0.0 to 1.0",
+		},
+		{
+			name: "invalid lastmod",
+			files: map[string]string{
+				"index.template.html": "home",
+				"meta.yaml":           "lastmod: yesterday",
+			},
+			wantErr: "lastmod must be a date",
+		},
+		{
+			name: "hand-written sitemap",
+			files: map[string]string{
+				"index.template.html": "home",
+				"sitemap.xml":         "<urlset/>",
+			},
+			wantErr: "sitemap.xml is generated, but the input directory has one as well",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := setupWriteTestEngine(t, tt.files)
+			engine.Sitemap = true
+			engine.BaseURL = "https://example.com/"
+
+			err := engine.Render()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderChanged_UpdatesSitemap(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":      "home",
+		"blog/index.template.html": "{{ .meta.title }}",
+		"blog/meta.yaml":           "title: Blog\nlastmod: 2024-03-01",
+	})
+	engine.Sitemap = true
+	engine.BaseURL = "https://example.com/"
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	metaPath := filepath.Join(inputDir, "blog", "meta.yaml")
+	if err := os.WriteFile(metaPath, []byte("title: Blog\nlastmod: 2024-04-01"), 0644); err != nil {
+		t.Fatalf("Failed to update meta.yaml: %v", err)
+	}
+	if err := engine.RenderChanged(metaPath); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read sitemap.xml: %v", err)
+	}
+	if !strings.Contains(string(content), "<lastmod>2024-04-01</lastmod>") {
+		t.Errorf("sitemap.xml was not updated, got:\n%s", content)
+	}
+	if !strings.Contains(strings.Join(engine.ChangedOutputs(), ","), "sitemap.xml") {
+		t.Errorf("ChangedOutputs() = %v, want it to contain sitemap.xml", engine.ChangedOutputs())
+	}
+}
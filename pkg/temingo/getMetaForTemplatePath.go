@@ -2,6 +2,7 @@ package temingo
 
 import (
 	"path"
+	"slices"
 
 	"github.com/thetillhoff/fileIO"
 	"github.com/thetillhoff/temingo/pkg/mergeYaml"
@@ -11,6 +12,8 @@ import (
 // getMetaForTemplatePath reads the metadata for the specified templatePath.
 // Returns the metadata from the treePath (meta yamls of the template-dir and the direct children-dirs),
 // and the childMetadata in map[folderName]metadata format.
+// Front matter ranks above the meta yamls: the front matter of the template and markdown content file of a page overrides their keys,
+// while the front matter of a metatemplate only sets defaults for the pages it renders.
 func (engine *Engine) getMetaForTemplatePath(metaTemplatePaths fileIO.FileList, templatePath string) (interface{}, map[string]interface{}, error) {
 	logger := engine.Logger
 
@@ -23,9 +26,12 @@ func (engine *Engine) getMetaForTemplatePath(metaTemplatePaths fileIO.FileList,
 		parsedContent interface{}
 
 		folderName string
+
+		treeMetaPaths = metaTemplatePaths.FilterByTreePath(templatePath).Files
+		childSources  = map[string][]string{} // The files each child's meta comes from, by folder name
 	)
 
-	for _, metaFilePath := range metaTemplatePaths.FilterByTreePath(templatePath).Files { // For each meta yaml in dirTree for templatePath (top-down)
+	for _, metaFilePath := range treeMetaPaths { // For each meta yaml in dirTree for templatePath (top-down)
 		logger.Debug("Reading metadata", "path", metaFilePath)
 
 		metaContent, err = fileIO.ReadFile(path.Join(engine.InputDir, metaFilePath)) // Read file contents
@@ -34,7 +40,7 @@ func (engine *Engine) getMetaForTemplatePath(metaTemplatePaths fileIO.FileList,
 		}
 		err = yaml.Unmarshal(metaContent, &parsedContent) // Store yaml into map
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, engine.locateYAMLError(err, metaFilePath)
 		}
 
 		meta = mergeYaml.Merge(parsedContent, meta, true)
@@ -49,12 +55,42 @@ func (engine *Engine) getMetaForTemplatePath(metaTemplatePaths fileIO.FileList,
 		}
 		err = yaml.Unmarshal(metaContent, &parsedContent) // Store yaml into map
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, engine.locateYAMLError(err, childMetaFilePath)
 		}
 
 		folderName = path.Base(path.Dir(childMetaFilePath)) // Get the name of the last folder
 
 		childMeta[folderName] = mergeYaml.Merge(parsedContent, meta, true) // Store parent+child meta into childMeta objects per child-folder
+		childSources[folderName] = append(slices.Clone(treeMetaPaths), childMetaFilePath)
+	}
+
+	for _, childFolder := range engine.childPageFolders(path.Dir(templatePath), metaTemplatePaths.Files, true) { // For each direct child folder, add the front matter of its page
+		folderName = path.Base(childFolder)
+		for _, sourcePath := range engine.folderFrontMatterSources(childFolder) {
+			child, ok := childMeta[folderName]
+			if !ok {
+				child = meta
+			}
+			childMeta[folderName] = overrideMeta(child, engine.frontMatter[sourcePath])
+			if _, ok := childSources[folderName]; !ok {
+				childSources[folderName] = slices.Clone(treeMetaPaths)
+			}
+			childSources[folderName] = append(childSources[folderName], sourcePath)
+		}
+	}
+
+	for _, sourcePath := range engine.frontMatterSources(templatePath) { // Only after the children, which shouldn't inherit the front matter of this page
+		if engine.isMetaTemplate(sourcePath) {
+			fields, _ := meta.(map[string]interface{})
+			meta = overrideMeta(engine.frontMatter[sourcePath], fields)
+		} else {
+			meta = overrideMeta(meta, engine.frontMatter[sourcePath])
+		}
+	}
+
+	engine.valueReads.track(meta, slices.Concat(treeMetaPaths, engine.frontMatterSources(templatePath)), "")
+	for folderName, child := range childMeta {
+		engine.valueReads.track(child, childSources[folderName], "")
 	}
 
 	return meta, childMeta, nil
@@ -53,7 +53,7 @@ func TestGetMetaForTemplatePath(t *testing.T) {
 				}
 
 				// Get meta paths
-				_, _, _, metaPaths, _, _ := engine.sortPaths(fileList)
+				_, _, _, metaPaths, _, _, _ := engine.sortPaths(fileList)
 
 				return fileList, metaPaths, nil
 			},
@@ -80,7 +80,7 @@ func TestGetMetaForTemplatePath(t *testing.T) {
 				}
 
 				// Get meta paths
-				_, _, _, metaPaths, _, _ := engine.sortPaths(fileList)
+				_, _, _, metaPaths, _, _, _ := engine.sortPaths(fileList)
 
 				return fileList, metaPaths, nil
 			},
@@ -169,7 +169,7 @@ func TestGetMetaForTemplatePath_WithInitFilesTestProject(t *testing.T) {
 	}
 
 	// Get meta paths
-	_, _, _, metaPaths, _, _ := engine.sortPaths(fileList)
+	_, _, _, metaPaths, _, _, _ := engine.sortPaths(fileList)
 
 	// Test template with meta
 	meta, childMeta, err := engine.getMetaForTemplatePath(fileIO.FileList{Files: metaPaths, Path: engine.InputDir}, "test_meta/index.html")
@@ -254,7 +254,7 @@ func TestGetMetaForTemplatePath_ParentMetaMerging(t *testing.T) {
 	}
 
 	// Get meta paths
-	_, _, _, metaPaths, _, _ := engine.sortPaths(fileList)
+	_, _, _, metaPaths, _, _, _ := engine.sortPaths(fileList)
 
 	// Test child template - should have merged meta (parent + child)
 	meta, _, err := engine.getMetaForTemplatePath(fileIO.FileList{Files: metaPaths, Path: engine.InputDir}, "test_parentmeta/parent/child/index.html")
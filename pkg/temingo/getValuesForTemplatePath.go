@@ -0,0 +1,48 @@
+package temingo
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/thetillhoff/fileIO"
+	"github.com/thetillhoff/temingo/pkg/mergeYaml"
+	"gopkg.in/yaml.v3"
+)
+
+// getValuesForTemplatePath reads the values files in every folder from the
+// inputDir down to the folder of templatePath, and merges them top-down, so
+// the keys of a deeper file override those of the files above it.
+func (engine *Engine) getValuesForTemplatePath(fileList fileIO.FileList, templatePath string) (map[string]interface{}, error) {
+	logger := engine.Logger
+
+	var (
+		values interface{} = map[interface{}]interface{}{}
+	)
+
+	if engine.ValuesFilename == "" { // Discovery is disabled
+		return map[string]interface{}{}, nil
+	}
+
+	for _, valuesFilePath := range fileList.FilterByTreePath(templatePath).FilterByFilename(engine.ValuesFilename).Files { // For each values yaml in dirTree for templatePath (top-down)
+		logger.Debug("Reading values", "path", valuesFilePath)
+
+		content, err := fileIO.ReadFile(path.Join(engine.InputDir, valuesFilePath)) // Read file contents
+		if err != nil {
+			return nil, err
+		}
+
+		parsedContent := map[interface{}]interface{}{}
+		if err = yaml.Unmarshal(content, &parsedContent); err != nil { // Store yaml into map
+			return nil, engine.locateYAMLError(err, valuesFilePath)
+		}
+
+		values = mergeYaml.Merge(parsedContent, values, true) // Deeper files override the keys of their parents
+	}
+
+	result := map[string]interface{}{}
+	for key, value := range values.(map[interface{}]interface{}) {
+		result[fmt.Sprint(key)] = value
+	}
+
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package temingo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetValuesForTemplatePath(t *testing.T) {
+	tests := []struct {
+		name         string
+		templatePath string
+		want         map[string]interface{}
+	}{
+		{
+			name:         "root template only sees root values",
+			templatePath: "index.html",
+			want:         map[string]interface{}{"siteName": "Site", "navTitle": "Home"},
+		},
+		{
+			name:         "deeper values override their parents",
+			templatePath: "docs/guide/index.html",
+			want:         map[string]interface{}{"siteName": "Site", "navTitle": "Guide", "section": "docs"},
+		},
+		{
+			name:         "sibling folders do not see each other",
+			templatePath: "blog/index.html",
+			want:         map[string]interface{}{"siteName": "Site", "navTitle": "Home"},
+		},
+	}
+
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, map[string]string{
+		"values.yaml":            "siteName: Site\nnavTitle: Home",
+		"docs/values.yaml":       "navTitle: Docs\nsection: docs",
+		"docs/guide/values.yaml": "navTitle: Guide",
+		"blog/index.template":    "",
+	})
+
+	engine := DefaultEngine()
+	engine.InputDir = tmpDir + string(filepath.Separator)
+	fileList, err := engine.readFileList()
+	if err != nil {
+		t.Fatalf("readFileList() unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.getValuesForTemplatePath(fileList, tt.templatePath)
+			if err != nil {
+				t.Fatalf("getValuesForTemplatePath() unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("getValuesForTemplatePath() = %v, want %v", got, tt.want)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("getValuesForTemplatePath()[%q] = %v, want %v", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_DiscoversValuesFiles(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"values.yaml":              "title: Site\nnav:\n  label: Top",
+		"index.template.html":      "{{ .title }} {{ .nav.label }} {{ .global }}",
+		"docs/values.yaml":         "nav:\n  label: Docs",
+		"docs/index.template.html": "{{ .title }} {{ .nav.label }} {{ .global }}",
+		"docs/sub/values.yaml":     "global: overridden",
+		"docs/sub/index.template":  "{{ .global }}",
+		"docs/assets/logo.svg":     "<svg/>",
+		"docs/assets/values.yaml":  "unused: true",
+		"docs/assets/notes.txt":    "static",
+		"blog/index.template.html": "{{ .nav.label }}",
+	})
+	engine.Values = map[string]interface{}{"global": "from flag"}
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"index.html":      "Site Top from flag",
+		"docs/index.html": "Site Docs from flag",
+		"docs/sub/index":  "overridden",
+		"blog/index.html": "Top",
+	}
+	for outputPath, content := range want {
+		if got := readOutput(t, outputDir, outputPath); got != content {
+			t.Errorf("Render() %s = %q, want %q", outputPath, got, content)
+		}
+	}
+	if got := readOutput(t, outputDir, "docs/assets/notes.txt"); got != "static" {
+		t.Errorf("Render() static file = %q, want it copied", got)
+	}
+	for _, excluded := range []string{"values.yaml", "docs/values.yaml", "docs/assets/values.yaml"} {
+		if _, err := os.Stat(filepath.Join(outputDir, excluded)); !os.IsNotExist(err) {
+			t.Errorf("Render() copied values file %s to the outputDir", excluded)
+		}
+	}
+}
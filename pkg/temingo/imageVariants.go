@@ -0,0 +1,188 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/thetillhoff/fileIO"
+	encodewebp "github.com/thetillhoff/temingo/pkg/encodeWebP"
+	"golang.org/x/image/draw"
+)
+
+// ImageOptions configures the variants the image template function generates.
+type ImageOptions struct {
+	// Widths are the widths variants are generated at when a call names none.
+	// Empty generates a single variant at the width of the image.
+	Widths []int
+	// Quality is the quality of the WebP and jpeg variants, from 0 to 100.
+	Quality int
+}
+
+// imageVariant is one resized and re-encoded copy of an input image.
+type imageVariant struct {
+	outputPath    string
+	sourcePath    string
+	format        string // webp, jpeg or png
+	width, height int
+}
+
+// generateImageVariants returns the image variants the rendered outputs asked
+// for, by output path. Each is taken from the cache if an earlier build
+// made it from the same bytes with the same options, and encoded otherwise.
+// Cached variants this build did not ask for are dropped from the cache.
+func (engine *Engine) generateImageVariants() (map[string][]byte, error) {
+	generated := map[string][]byte{}
+	sources := map[string][]byte{}
+	decoded := map[string]image.Image{}
+	used := map[string]bool{} // The cache keys of the variants
+
+	variants := map[string]imageVariant{} // Several outputs can show the same image
+	for _, pageVariants := range engine.imageVariants {
+		for _, variant := range pageVariants {
+			variants[variant.outputPath] = variant
+		}
+	}
+	for _, outputPath := range slices.Sorted(maps.Keys(variants)) {
+		variant := variants[outputPath]
+
+		source, ok := sources[variant.sourcePath]
+		if !ok {
+			var err error
+			if source, err = fileIO.ReadFile(engine.inputFilePath(variant.sourcePath)); err != nil {
+				return nil, fmt.Errorf("reading image %s: %w", variant.sourcePath, err)
+			}
+			sources[variant.sourcePath] = source
+		}
+
+		key := fmt.Sprintf("%s-%dx%d-q%d.%s", hashContent(source), variant.width, variant.height, engine.Images.Quality, variant.format)
+		used[key] = true
+		if content, ok := engine.cachedImage(key); ok {
+			generated[outputPath] = content
+			continue
+		}
+
+		img, ok := decoded[variant.sourcePath]
+		if !ok {
+			var err error
+			if img, _, err = image.Decode(bytes.NewReader(source)); err != nil {
+				return nil, fmt.Errorf("decoding image %s: %w", variant.sourcePath, err)
+			}
+			decoded[variant.sourcePath] = img
+		}
+
+		content, err := engine.encodeImageVariant(img, variant)
+		if err != nil {
+			return nil, fmt.Errorf("generating %s: %w", outputPath, err)
+		}
+		if err = engine.cacheImage(key, content); err != nil {
+			return nil, err
+		}
+		generated[outputPath] = content
+	}
+
+	if err := engine.pruneImageCache(used); err != nil {
+		return nil, err
+	}
+	return generated, nil
+}
+
+// encodeImageVariant scales img to the size of variant and encodes it in its
+// format.
+func (engine *Engine) encodeImageVariant(img image.Image, variant imageVariant) ([]byte, error) {
+	scaled := img
+	if bounds := img.Bounds(); bounds.Dx() != variant.width || bounds.Dy() != variant.height {
+		resized := image.NewNRGBA(image.Rect(0, 0, variant.width, variant.height))
+		draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+		scaled = resized
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch variant.format {
+	case "webp":
+		err = encodewebp.Encode(&buf, scaled, engine.Images.Quality)
+	case "jpeg":
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: max(engine.Images.Quality, 1)})
+	case "png":
+		err = png.Encode(&buf, scaled)
+	default:
+		err = fmt.Errorf("unsupported image format %s", variant.format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// cachedImage returns the variant cached under key, from memory or from the
+// CacheDir.
+func (engine *Engine) cachedImage(key string) ([]byte, bool) {
+	if content, ok := engine.imageCache[key]; ok {
+		return content, true
+	}
+	if engine.CacheDir == "" {
+		return nil, false
+	}
+	content, err := os.ReadFile(engine.cacheDir("images", key))
+	if err != nil {
+		return nil, false
+	}
+	engine.imageCache[key] = content
+	return content, true
+}
+
+// pruneImageCache removes every variant but the used ones from the cache, in
+// memory and in the CacheDir, so variants of images that changed or are no
+// longer shown do not pile up.
+func (engine *Engine) pruneImageCache(used map[string]bool) error {
+	maps.DeleteFunc(engine.imageCache, func(key string, _ []byte) bool {
+		return !used[key]
+	})
+	if engine.CacheDir == "" {
+		return nil
+	}
+
+	cacheDir := engine.cacheDir("images")
+	entries, err := os.ReadDir(cacheDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading image cache directory %s: %w", cacheDir, err)
+	}
+	for _, entry := range entries {
+		if used[entry.Name()] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(cacheDir, entry.Name())); err != nil {
+			return fmt.Errorf("pruning image cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// cacheImage keeps the variant under key in memory, and in the CacheDir if
+// there is one, so later builds skip encoding it.
+func (engine *Engine) cacheImage(key string, content []byte) error {
+	engine.imageCache[key] = content
+	if engine.CacheDir == "" {
+		return nil
+	}
+	cacheDir := engine.cacheDir("images")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("creating image cache directory %s: %w", cacheDir, err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, key), content, 0644); err != nil {
+		return fmt.Errorf("caching image variant: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package temingo
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/thetillhoff/fileIO"
+	"github.com/thetillhoff/temingo/internal/library"
+)
+
+// Library is a partial library as vendored for a build.
+type Library struct {
+	Name   string
+	URL    string
+	Ref    string
+	Commit string
+	// Partials are the partial and component files of the library, by the
+	// path they are available under, like shared/header.partial.html.
+	Partials []LibraryPartial
+}
+
+// LibraryPartial is a partial or component file of a library.
+type LibraryPartial struct {
+	// Path is what templates call the partial by: the library name, followed
+	// by its path within the library.
+	Path string
+	// Source is the vendored file in the cache.
+	Source string
+	// OverriddenBy is the input file at the same path within the InputDir,
+	// which builds use instead, or "" if there is none.
+	OverriddenBy string
+}
+
+// lockFilePath returns the path of the lockfile that pins the libraries of the
+// LibrariesFile, next to it: component.yaml is pinned by component.lock.
+func (engine *Engine) lockFilePath() string {
+	return strings.TrimSuffix(engine.LibrariesFile, path.Ext(engine.LibrariesFile)) + ".lock"
+}
+
+// Libraries vendors the libraries the LibrariesFile declares and returns them.
+// A library keeps the commit the lockfile pins it to; one the lockfile does
+// not pin yet, or every one if update is set, is pinned to the commit its ref
+// points to now, and the lockfile is rewritten.
+func (engine *Engine) Libraries(update bool) ([]Library, error) {
+	if engine.LibrariesFile == "" {
+		return nil, nil
+	}
+	manifest, err := library.ReadManifest(engine.LibrariesFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Libraries) == 0 {
+		return nil, nil
+	}
+	if engine.CacheDir == "" {
+		return nil, fmt.Errorf("the libraries in %s need a cacheDir to be vendored into", engine.LibrariesFile)
+	}
+
+	lockPath := engine.lockFilePath()
+	lock, err := library.ReadLock(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cache     = library.Cache{Dir: engine.cacheDir("libraries")}
+		libraries []Library
+		newLock   library.Lock
+	)
+	for _, declared := range manifest.Libraries {
+		commit, pinned := lock.Commit(declared)
+		if !pinned || update {
+			if commit, err = cache.Resolve(declared); err != nil {
+				return nil, err
+			}
+			engine.Logger.Info("Pinned library", "name", declared.Name, "ref", declared.Ref, "commit", commit)
+		}
+		newLock.Libraries = append(newLock.Libraries, library.Locked{Name: declared.Name, URL: declared.URL, Ref: declared.Ref, Commit: commit})
+
+		dir, err := cache.Checkout(declared, commit)
+		if err != nil {
+			return nil, err
+		}
+		partials, err := engine.libraryPartials(declared.Name, dir)
+		if err != nil {
+			return nil, err
+		}
+		libraries = append(libraries, Library{Name: declared.Name, URL: declared.URL, Ref: declared.Ref, Commit: commit, Partials: partials})
+	}
+
+	if !slices.Equal(lock.Libraries, newLock.Libraries) {
+		if err = newLock.Write(lockPath); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", lockPath, err)
+		}
+	}
+
+	return libraries, nil
+}
+
+// libraryPartials returns the partial and component files in the vendored
+// library dir, marking those an input file at the same path overrides.
+func (engine *Engine) libraryPartials(name string, dir string) ([]LibraryPartial, error) {
+	var partials []LibraryPartial
+
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		partialPath := path.Join(name, filepath.ToSlash(rel))
+		if !strings.Contains(partialPath, engine.PartialExtension) && !engine.isComponent(partialPath) {
+			return nil // Only partials are used from a library
+		}
+
+		partial := LibraryPartial{Path: partialPath, Source: filePath}
+		local := path.Join(engine.InputDir, partialPath)
+		if _, err := os.Stat(local); err == nil {
+			partial.OverriddenBy = local
+		}
+		partials = append(partials, partial)
+		return nil
+	})
+
+	return partials, err
+}
+
+// addLibraryPartials vendors the libraries and adds their partials to
+// partialPaths, except the ones an input file in fileList overrides, which is
+// among partialPaths already.
+func (engine *Engine) addLibraryPartials(partialPaths []string, fileList fileIO.FileList) ([]string, error) {
+	libraries, err := engine.Libraries(false)
+	if err != nil {
+		return nil, err
+	}
+
+	engine.libraryNames = map[string]bool{}
+	engine.libraryFiles = map[string]string{}
+	for _, lib := range libraries {
+		engine.libraryNames[lib.Name] = true
+		for _, partial := range lib.Partials {
+			if slices.Contains(fileList.Files, partial.Path) {
+				engine.Logger.Debug("Input file overrides library partial", "path", partial.Path, "library", lib.Name)
+				continue
+			}
+			engine.libraryFiles[partial.Path] = partial.Source
+			partialPaths = append(partialPaths, partial.Path)
+		}
+	}
+
+	return partialPaths, nil
+}
+
+// inputFilePath returns the file the input path filePath is read from: the
+// vendored file for a library partial, otherwise the file in the InputDir.
+func (engine *Engine) inputFilePath(filePath string) string {
+	if source, ok := engine.libraryFiles[filePath]; ok {
+		return source
+	}
+	return path.Join(engine.InputDir, filePath)
+}
@@ -0,0 +1,154 @@
+package temingo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupLibraryRepository creates a git repository holding files, committed and
+// tagged v1, and returns its path.
+func setupLibraryRepository(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	runTestGit(t, dir, "init", "--quiet")
+	commitLibraryFiles(t, dir, files)
+	return dir
+}
+
+// commitLibraryFiles writes files into the repository at dir, commits them and
+// moves the tag v1 to the new commit.
+func commitLibraryFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	writeTestFiles(t, dir, files)
+	runTestGit(t, dir, "add", "--all")
+	runTestGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message", "v1")
+	runTestGit(t, dir, "tag", "--force", "v1")
+}
+
+func runTestGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+}
+
+// setupLibraryTestEngine returns an engine rendering files, with the library
+// "shared" declared at the tag v1 of repository.
+func setupLibraryTestEngine(t *testing.T, repository string, files map[string]string) (*Engine, string, string) {
+	t.Helper()
+	engine, inputDir, outputDir := setupWriteTestEngine(t, files)
+	tmpDir := filepath.Dir(inputDir)
+	engine.CacheDir = filepath.Join(tmpDir, "cache")
+	engine.LibrariesFile = filepath.Join(tmpDir, "component.yaml")
+	writeTestFiles(t, tmpDir, map[string]string{
+		"component.yaml": "libraries:\n  - name: shared\n    url: file://" + repository + "\n    ref: v1\n    path: partials\n",
+	})
+	return engine, inputDir, outputDir
+}
+
+func TestRender_Libraries(t *testing.T) {
+	repository := setupLibraryRepository(t, map[string]string{
+		"partials/header.partial.html":     "<header>library</header>",
+		"partials/card.component.html":     "---\nparams:\n  title:\n    type: string\n    required: true\n---\n<div>{{ .title }}</div>",
+		"partials/unrelated.template.html": "not a partial",
+	})
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "partial from a library",
+			files: map[string]string{"index.template.html": `{{ template "shared/header.partial.html" }}`},
+			want:  "<header>library</header>",
+		},
+		{
+			name:  "component from a library",
+			files: map[string]string{"index.template.html": `{{ component "shared/card" "title" "T" }}`},
+			want:  "<div>T</div>",
+		},
+		{
+			name: "input file overrides a library partial",
+			files: map[string]string{
+				"index.template.html":        `{{ template "shared/header.partial.html" }}`,
+				"shared/header.partial.html": "<header>local</header>",
+			},
+			want: "<header>local</header>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, outputDir := setupLibraryTestEngine(t, repository, tt.files)
+
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+			if got := readOutput(t, outputDir, "index.html"); got != tt.want {
+				t.Errorf("Render() index.html = %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, "shared")); err == nil {
+				t.Error("Render() wrote library files to the output")
+			}
+		})
+	}
+}
+
+func TestRender_LibrariesPinnedByLockfile(t *testing.T) {
+	repository := setupLibraryRepository(t, map[string]string{"partials/header.partial.html": "<header>v1</header>"})
+	engine, _, outputDir := setupLibraryTestEngine(t, repository, map[string]string{
+		"index.template.html": `{{ template "shared/header.partial.html" }}`,
+	})
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	lock, err := os.ReadFile(engine.lockFilePath())
+	if err != nil {
+		t.Fatalf("Render() wrote no lockfile: %v", err)
+	}
+	if !strings.Contains(string(lock), "commit: ") {
+		t.Errorf("lockfile = %q, want the pinned commit", lock)
+	}
+
+	// Moving the tag changes nothing until the lockfile is updated
+	commitLibraryFiles(t, repository, map[string]string{"partials/header.partial.html": "<header>v2</header>"})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "index.html"); got != "<header>v1</header>" {
+		t.Errorf("Render() with the lockfile = %q, want the pinned v1", got)
+	}
+
+	libraries, err := engine.Libraries(true)
+	if err != nil {
+		t.Fatalf("Libraries(true) unexpected error: %v", err)
+	}
+	if len(libraries) != 1 || len(libraries[0].Partials) != 1 || libraries[0].Partials[0].Path != "shared/header.partial.html" {
+		t.Fatalf("Libraries(true) = %+v, want shared with its header partial", libraries)
+	}
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "index.html"); got != "<header>v2</header>" {
+		t.Errorf("Render() after updating the lockfile = %q, want v2", got)
+	}
+}
+
+func TestLibraries_NeedCacheDir(t *testing.T) {
+	repository := setupLibraryRepository(t, map[string]string{"partials/header.partial.html": "<header></header>"})
+	engine, _, _ := setupLibraryTestEngine(t, repository, map[string]string{"index.template.html": "home"})
+	engine.CacheDir = ""
+
+	if err := engine.Render(); err == nil || !strings.Contains(err.Error(), "cacheDir") {
+		t.Errorf("Render() error = %v, want one asking for a cacheDir", err)
+	}
+}
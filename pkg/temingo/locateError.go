@@ -0,0 +1,81 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// yamlErrorRe matches the line yaml puts in front of syntax errors.
+var yamlErrorRe = regexp.MustCompile(`(?s)^yaml: line (\d+): (.*)$`)
+
+// templateErrorRe matches the location text/template puts in front of parse
+// errors (name:line) and execution errors (name:line:col).
+var templateErrorRe = regexp.MustCompile(`(?s)^template: (.+?):(\d+)(?::(\d+))?: (.*)$`)
+
+// templateSource is the input file a named template was parsed from.
+// lineOffset is the number of lines temingo put in front of the file's own
+// content, like the define line around a partial, or minus the number of lines
+// it removed from its start, like front matter.
+type templateSource struct {
+	path       string
+	lineOffset int
+}
+
+// partialLineOffset is the define line readPartials wraps every partial in.
+const partialLineOffset = 1
+
+// locateTemplateError turns a text/template error into a SourceError at the
+// line and column of the input file it occurred in, with context describing
+// what was being done. Errors that name no known template are only wrapped
+// with the context.
+func (engine *Engine) locateTemplateError(err error, sources map[string]templateSource, context string) error {
+	match := templateErrorRe.FindStringSubmatch(err.Error())
+	if match == nil {
+		return fmt.Errorf("%s: %w", context, err)
+	}
+	source, ok := sources[match[1]]
+	if !ok {
+		return fmt.Errorf("%s: %w", context, err)
+	}
+
+	line, _ := strconv.Atoi(match[2])
+	col := 0 // Parse errors carry no column
+	if match[3] != "" {
+		col, _ = strconv.Atoi(match[3])
+		col++ // text/template counts columns from 0, editors from 1
+	}
+	line -= source.lineOffset
+	if line < 1 { // Inside the wrapping temingo added, so the start of the file is closest
+		line, col = 1, 0
+	}
+
+	return &SourceError{
+		Position: Position{File: engine.inputFilePath(source.path), Line: line, Col: col},
+		Err:      fmt.Errorf("%s: %w", context, errors.New(match[4])),
+	}
+}
+
+// partialSources returns the source of every partial, by the name it is
+// parsed under.
+func partialSources(partialFiles map[string]string) map[string]templateSource {
+	sources := make(map[string]templateSource, len(partialFiles))
+	for partialPath := range partialFiles {
+		sources[partialPath] = templateSource{path: partialPath, lineOffset: partialLineOffset}
+	}
+	return sources
+}
+
+// locateYAMLError turns an error from parsing the yaml file at filePath into a
+// SourceError, at the line yaml reports if it reports one.
+func (engine *Engine) locateYAMLError(err error, filePath string) error {
+	position := Position{File: engine.inputFilePath(filePath)}
+
+	if match := yamlErrorRe.FindStringSubmatch(err.Error()); match != nil {
+		position.Line, _ = strconv.Atoi(match[1])
+		return &SourceError{Position: position, Err: errors.New(match[2])}
+	}
+
+	return &SourceError{Position: position, Err: err}
+}
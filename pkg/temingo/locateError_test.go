@@ -0,0 +1,153 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPositionString(t *testing.T) {
+	tests := []struct {
+		position Position
+		want     string
+	}{
+		{position: Position{File: "src/a.html"}, want: "src/a.html"},
+		{position: Position{File: "src/a.html", Line: 3}, want: "src/a.html:3"},
+		{position: Position{File: "src/a.html", Line: 3, Col: 7}, want: "src/a.html:3:7"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.position.String(); got != tt.want {
+			t.Errorf("Position.String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRender_ErrorsPointAtSourceFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantFile string
+		wantLine int
+		wantCol  int
+		wantMsg  string
+	}{
+		{
+			name: "execution error in a template",
+			files: map[string]string{
+				"index.template.html": "<html>\n  {{ index \"ab\" 5 }}\n</html>",
+			},
+			wantFile: "index.template.html",
+			wantLine: 2,
+			wantCol:  6,
+			wantMsg:  "rendering template index.template.html",
+		},
+		{
+			name: "parse error in a template",
+			files: map[string]string{
+				"index.template.html": "<html>\n\n{{ if }}\n</html>",
+			},
+			wantFile: "index.template.html",
+			wantLine: 3,
+		},
+		{
+			name: "execution error in a partial is not offset by its define wrapping",
+			files: map[string]string{
+				"index.template.html": `{{ template "header.partial.html" . }}`,
+				"header.partial.html": "<header>\n  <h1>\n    {{ index \"ab\" 5 }}\n  </h1>\n</header>",
+			},
+			wantFile: "header.partial.html",
+			wantLine: 3,
+			wantCol:  8,
+			wantMsg:  "rendering template index.template.html",
+		},
+		{
+			name: "parse error in a partial",
+			files: map[string]string{
+				"index.template.html": "home",
+				"header.partial.html": "<header>\n{{ if }}\n</header>",
+			},
+			wantFile: "header.partial.html",
+			wantLine: 2,
+			wantMsg:  "parsing partial header.partial.html",
+		},
+		{
+			name: "execution error in a metatemplate",
+			files: map[string]string{
+				"blog/index.metatemplate.html": "{{ .meta.title }}\n{{ index \"ab\" 5 }}",
+				"blog/first/meta.yaml":         "title: First",
+			},
+			wantFile: "blog/index.metatemplate.html",
+			wantLine: 2,
+			wantMsg:  "for blog/first/index.html",
+		},
+		{
+			name: "syntax error in a meta yaml",
+			files: map[string]string{
+				"index.template.html": "{{ .meta.title }}",
+				"meta.yaml":           "title: Home\ndescription: First\n  nested: value\n",
+			},
+			wantFile: "meta.yaml",
+			wantLine: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, inputDir, _ := setupWriteTestEngine(t, tt.files)
+
+			err := engine.Render()
+			var sourceErr *SourceError
+			if !errors.As(err, &sourceErr) {
+				t.Fatalf("Render() error = %v, want a SourceError", err)
+			}
+
+			if want := filepath.Join(inputDir, tt.wantFile); sourceErr.File != want {
+				t.Errorf("SourceError.File = %q, want %q", sourceErr.File, want)
+			}
+			if sourceErr.Line != tt.wantLine {
+				t.Errorf("SourceError.Line = %d, want %d (%v)", sourceErr.Line, tt.wantLine, err)
+			}
+			if tt.wantCol != 0 && sourceErr.Col != tt.wantCol {
+				t.Errorf("SourceError.Col = %d, want %d (%v)", sourceErr.Col, tt.wantCol, err)
+			}
+			if !strings.HasPrefix(err.Error(), sourceErr.Position.String()+": ") {
+				t.Errorf("Render() error = %q, want it to start with the position", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Render() error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestRender_ReferenceFindingsPointAtSourceFiles(t *testing.T) {
+	engine, inputDir, _ := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":       "<html>\n<body>\n{{ template \"nav.partial.html\" }}\n{{ .content }}\n</body>\n</html>",
+		"nav.partial.html":          "<nav>\n  <a href=\"/missing-from-nav/\">x</a>\n</nav>",
+		"content.md":                "# Home\n\nSee [the docs](/missing-from-content/).",
+		"built/index.template.html": `<a href="{{ .target }}">x</a>`,
+		"static/page.html":          "<p>\n<img src=\"/missing.png\">\n</p>",
+	})
+	engine.Values = map[string]interface{}{"target": "/missing-from-values/"}
+
+	var buf bytes.Buffer
+	engine.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"file=" + filepath.Join(inputDir, "nav.partial.html") + ":2:12",
+		"file=" + filepath.Join(inputDir, "content.md") + ":3:16",
+		"file=" + filepath.Join(inputDir, "built/index.template.html") + " output=built/index.html:1:10",
+		"file=" + filepath.Join(inputDir, "static/page.html") + ":2:11",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Render() findings missing %q, got:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package temingo
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// layoutName is the filename, without its partial extension, of the layout
+// partial a markdown page is rendered with when its front matter names none.
+// The nearest one up the directory tree of the page applies.
+const layoutName = "_layout.html"
+
+// markdownPage is a standalone markdown file rendered as a page of its own,
+// through a layout partial that places its converted content.
+type markdownPage struct {
+	sourcePath string
+	layout     string // Path of the layout partial
+}
+
+// templateContent returns the template the page is rendered from, which calls
+// its layout with the page's meta object.
+func (page markdownPage) templateContent() string {
+	return "{{ template \"" + page.layout + "\" . }}"
+}
+
+// readMarkdownPages finds the layout of each markdown page among
+// markdownPagePaths, and keeps the pages on the engine by output path. A page
+// uses the partial named by the layout key of its front matter, or else the
+// nearest layout partial up its directory tree. Markdown files without either
+// are no pages, and are returned to be copied as static files.
+func (engine *Engine) readMarkdownPages(markdownPagePaths []string, partialFiles map[string]string) ([]string, error) {
+	var (
+		pages       = map[string]markdownPage{}
+		staticPaths []string
+	)
+
+	for _, pagePath := range markdownPagePaths {
+		layout, err := engine.markdownPageLayout(pagePath, partialFiles)
+		if err != nil {
+			return nil, err
+		}
+		if layout == "" {
+			engine.Logger.Debug("No layout for markdown file, copying it as static file", "path", pagePath)
+			delete(engine.frontMatter, pagePath) // Its front matter is not read by anything
+			staticPaths = append(staticPaths, pagePath)
+			continue
+		}
+
+		outputPath := engine.markdownPageOutputPath(pagePath)
+		if existing, ok := pages[outputPath]; ok {
+			return nil, fmt.Errorf("markdown pages %s and %s both render to %s", existing.sourcePath, pagePath, outputPath)
+		}
+		pages[outputPath] = markdownPage{sourcePath: pagePath, layout: layout}
+		engine.Logger.Debug("Identified as markdown page", "path", pagePath, "layout", layout)
+	}
+
+	engine.markdownPages = pages
+	return staticPaths, nil
+}
+
+// markdownPageLayout returns the path of the layout partial for the markdown
+// page at pagePath, or "" if it has none.
+func (engine *Engine) markdownPageLayout(pagePath string, partialFiles map[string]string) (string, error) {
+	if value, ok := engine.frontMatter[pagePath]["layout"]; ok {
+		layout, ok := value.(string)
+		if !ok {
+			return "", &SourceError{Position: Position{File: engine.inputFilePath(pagePath)}, Err: fmt.Errorf("layout must be the path of a partial, got %v", value)}
+		}
+		if _, ok := partialFiles[layout]; !ok {
+			return "", &SourceError{Position: Position{File: engine.inputFilePath(pagePath)}, Err: fmt.Errorf("layout %s is no partial", layout)}
+		}
+		return layout, nil
+	}
+
+	for folder := path.Dir(pagePath); ; folder = path.Dir(folder) { // From the folder of the page up to the inputDir
+		for partialPath := range partialFiles {
+			if path.Dir(partialPath) == folder && strings.ReplaceAll(path.Base(partialPath), engine.PartialExtension, "") == layoutName {
+				return partialPath, nil
+			}
+		}
+		if folder == "." {
+			return "", nil
+		}
+	}
+}
+
+// markdownPageOutputPath returns the output path of the markdown page at
+// pagePath: docs/intro.md renders to docs/intro/index.html, or to
+// docs/intro.html with FlatMarkdownPages. An index.md renders to the
+// index.html of its own folder.
+func (engine *Engine) markdownPageOutputPath(pagePath string) string {
+	name := strings.TrimSuffix(pagePath, path.Ext(pagePath))
+	if engine.FlatMarkdownPages || path.Base(name) == "index" {
+		return name + ".html"
+	}
+	return path.Join(name, "index.html")
+}
+
+// isMarkdownPage reports whether filePath is a standalone markdown file, which
+// renders as a page of its own when it has a layout.
+func (engine *Engine) isMarkdownPage(filePath string) bool {
+	return path.Ext(filePath) == ".md" &&
+		path.Base(filePath) != engine.MarkdownContentFilename &&
+		!strings.Contains(filePath, engine.PartialExtension) &&
+		!strings.Contains(filePath, engine.TemplateExtension) &&
+		!engine.isMetaTemplate(filePath) &&
+		!engine.isComponent(filePath)
+}
@@ -0,0 +1,196 @@
+package temingo
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender_MarkdownPages(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		flat  bool
+		want  map[string]string // Output path to content
+	}{
+		{
+			name: "nearest layout up the tree",
+			files: map[string]string{
+				"_layout.partial.html":      "<main>{{ .meta.title }}: {{ .content }}</main>",
+				"docs/_layout.partial.html": "<article>{{ .path }} {{ range .breadcrumbs }}{{ .Name }}{{ end }}: {{ .content }}</article>",
+				"about.md":                  "---\ntitle: About\n---\nWho we are",
+				"docs/guide/install.md":     "Install it",
+			},
+			want: map[string]string{
+				"about/index.html":              "<main>About: <p>Who we are</p>\n</main>",
+				"docs/guide/install/index.html": "<article>docs/guide/install/index.html docsguide: <p>Install it</p>\n</article>",
+			},
+		},
+		{
+			name: "layout named by front matter, with meta yaml below the front matter",
+			files: map[string]string{
+				"_layout.partial.html":        "default",
+				"layouts/wide.partial.html":   "{{ .meta.title }} {{ .meta.lang }} {{ .content }}",
+				"docs/meta.yaml":              "title: Docs\nlang: en\n",
+				"docs/getting-started.md":     "---\nlayout: layouts/wide.partial.html\ntitle: Getting started\n---\nFirst steps",
+				"docs/index.template.html":    "docs",
+				"layouts/unused.partial.html": "",
+			},
+			want: map[string]string{
+				"docs/getting-started/index.html": "Getting started en <p>First steps</p>\n",
+				"docs/index.html":                 "docs",
+			},
+		},
+		{
+			name: "flat pages, and index pages in their own folder",
+			files: map[string]string{
+				"_layout.partial.html": "{{ .content }}",
+				"docs/index.md":        "Docs",
+				"docs/intro.md":        "Intro",
+			},
+			flat: true,
+			want: map[string]string{
+				"docs/index.html": "<p>Docs</p>\n",
+				"docs/intro.html": "<p>Intro</p>\n",
+			},
+		},
+		{
+			name: "markdown without a layout is copied",
+			files: map[string]string{
+				"README.md":           "---\nnot: a page\n---\n# Readme",
+				"index.template.html": "home",
+			},
+			want: map[string]string{
+				"README.md":  "---\nnot: a page\n---\n# Readme",
+				"index.html": "home",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, outputDir := setupWriteTestEngine(t, tt.files)
+			engine.FlatMarkdownPages = tt.flat
+			engine.StrictValues = true
+
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+			for outputPath, want := range tt.want {
+				if got := readOutput(t, outputDir, outputPath); got != want {
+					t.Errorf("Render() %s = %q, want %q", outputPath, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_MarkdownPageErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "layout that is no partial",
+			files:   map[string]string{"intro.md": "---\nlayout: missing.partial.html\n---\nIntro"},
+			wantErr: "intro.md: layout missing.partial.html is no partial",
+		},
+		{
+			name: "page and template rendering to the same output",
+			files: map[string]string{
+				"_layout.partial.html":      "{{ .content }}",
+				"intro.md":                  "Intro",
+				"intro/index.template.html": "intro",
+			},
+			wantErr: "intro/index.template.html and markdown page intro.md both render to intro/index.html",
+		},
+		{
+			name: "error in the layout",
+			files: map[string]string{
+				"_layout.partial.html": "{{ .content.nope }}",
+				"intro.md":             "Intro",
+			},
+			wantErr: "_layout.partial.html:1:12: rendering markdown page intro.md with layout _layout.partial.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := setupWriteTestEngine(t, tt.files)
+
+			err := engine.Render()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderChanged_MarkdownPages(t *testing.T) {
+	files := map[string]string{
+		"_layout.partial.html": "<main>{{ .content }}</main>",
+		"docs/intro.md":        "Intro",
+		"docs/usage.md":        "Usage",
+		"plain.partial.html":   "{{ .content }}",
+	}
+
+	t.Run("changed page rebuilds only itself", func(t *testing.T) {
+		engine, inputDir, outputDir := setupWriteTestEngine(t, files)
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() unexpected error: %v", err)
+		}
+		markOutputs(t, outputDir, "docs/usage/index.html")
+
+		writeTestFiles(t, inputDir, map[string]string{"docs/intro.md": "Introduction"})
+		if err := engine.RenderChanged(filepath.Join(inputDir, "docs/intro.md")); err != nil {
+			t.Fatalf("RenderChanged() unexpected error: %v", err)
+		}
+
+		if got := readOutput(t, outputDir, "docs/intro/index.html"); got != "<main><p>Introduction</p>\n</main>" {
+			t.Errorf("RenderChanged() docs/intro/index.html = %q, want the new content", got)
+		}
+		if got := readOutput(t, outputDir, "docs/usage/index.html"); got != "untouched" {
+			t.Errorf("RenderChanged() docs/usage/index.html = %q, want it untouched", got)
+		}
+	})
+
+	t.Run("switching the layout rebuilds everything", func(t *testing.T) {
+		engine, inputDir, outputDir := setupWriteTestEngine(t, files)
+		writeTestFiles(t, inputDir, map[string]string{"docs/intro.md": "---\ntitle: Intro\n---\nIntro"})
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() unexpected error: %v", err)
+		}
+
+		writeTestFiles(t, inputDir, map[string]string{"docs/intro.md": "---\nlayout: plain.partial.html\n---\nIntro"})
+		if err := engine.RenderChanged(filepath.Join(inputDir, "docs/intro.md")); err != nil {
+			t.Fatalf("RenderChanged() unexpected error: %v", err)
+		}
+
+		if got := readOutput(t, outputDir, "docs/intro/index.html"); got != "<p>Intro</p>\n" {
+			t.Errorf("RenderChanged() docs/intro/index.html = %q, want it in the new layout", got)
+		}
+	})
+
+	t.Run("markdown file given a layout becomes a page", func(t *testing.T) {
+		engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+			"plain.partial.html": "{{ .content }}",
+			"notes.md":           "Notes",
+		})
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() unexpected error: %v", err)
+		}
+		if got := readOutput(t, outputDir, "notes.md"); got != "Notes" {
+			t.Fatalf("Render() notes.md = %q, want it copied", got)
+		}
+
+		writeTestFiles(t, inputDir, map[string]string{"notes.md": "---\nlayout: plain.partial.html\n---\nNotes"})
+		if err := engine.RenderChanged(filepath.Join(inputDir, "notes.md")); err != nil {
+			t.Fatalf("RenderChanged() unexpected error: %v", err)
+		}
+
+		if got := readOutput(t, outputDir, "notes/index.html"); got != "<p>Notes</p>\n" {
+			t.Errorf("RenderChanged() notes/index.html = %q, want the page", got)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/thetillhoff/fileIO"
+	"github.com/thetillhoff/temingo/pkg/markdown2html"
+)
+
+// shortcodeRe matches a shortcode, the markdown form of a component call:
+//
+//	{{< video id="dQw4w9WgXcQ" autoplay=false title=.meta.title >}}
+//
+// Quoted values are strings, others are passed to the component as they are
+// written, like numbers, booleans, fields and variables.
+var shortcodeRe = regexp.MustCompile(`\{\{<\s*([\w./-]+)((?:\s+[\w-]+=(?:"(?:[^"\\\n]|\\.)*"|[^\s"<>]+))*)\s*>\}\}`)
+
+// shortcodeArgRe matches one key=value argument of a shortcode.
+var shortcodeArgRe = regexp.MustCompile(`([\w-]+)=("(?:[^"\\\n]|\\.)*"|[^\s"<>]+)`)
+
+// expandShortcodes rewrites every shortcode in content to the component call it
+// stands for. A shortcode that cannot be read is returned as a SourceError at
+// its line and column in content.
+func expandShortcodes(content string) (string, error) {
+	shortcodeStarts := map[int]bool{}
+	for _, loc := range shortcodeRe.FindAllStringIndex(content, -1) {
+		shortcodeStarts[loc[0]] = true
+	}
+	for index := strings.Index(content, "{{<"); index >= 0; index = nextIndex(content, "{{<", index) {
+		if !shortcodeStarts[index] {
+			return "", &SourceError{
+				Position: Position{Line: strings.Count(content[:index], "\n") + 1, Col: index - strings.LastIndex(content[:index], "\n")},
+				Err:      errors.New(`malformed shortcode, want {{< name key="value" ... >}} on a single line`),
+			}
+		}
+	}
+
+	return shortcodeRe.ReplaceAllStringFunc(content, func(shortcode string) string {
+		match := shortcodeRe.FindStringSubmatch(shortcode)
+		call := []string{"component", `"` + match[1] + `"`}
+		for _, arg := range shortcodeArgRe.FindAllStringSubmatch(match[2], -1) {
+			call = append(call, `"`+arg[1]+`"`, arg[2])
+		}
+		return "{{ " + strings.Join(call, " ") + " }}"
+	}), nil
+}
+
+// nextIndex returns the index of the next substr in s after the one at index,
+// or -1 if there is none.
+func nextIndex(s string, substr string, index int) int {
+	next := strings.Index(s[index+1:], substr)
+	if next < 0 {
+		return -1
+	}
+	return index + 1 + next
+}
+
+// markdownContentPath returns the markdown file that is the content of the
+// output at outputPath: the markdown page rendering to it, or the markdown
+// content file in its folder. It is "" when there is none.
+func (engine *Engine) markdownContentPath(outputPath string, fileList fileIO.FileList) string {
+	if page, ok := engine.markdownPages[outputPath]; ok { // A markdown page is the content of its own output
+		return page.sourcePath
+	}
+	markdownContentFiles := fileList.FilterByFolderPath(path.Dir(outputPath)).FilterByFilename(engine.MarkdownContentFilename).Files
+	if len(markdownContentFiles) == 1 { // Can only be 1 at max
+		return markdownContentFiles[0]
+	}
+	return ""
+}
+
+// readMarkdownContent returns the markdown at contentPath without its front
+// matter. With MarkdownTemplates it is executed as a template first, after its
+// shortcodes are expanded, with meta as data and the partials and components of
+// partialFiles to call.
+func (engine *Engine) readMarkdownContent(contentPath string, meta map[string]interface{}, partialFiles map[string]string) (string, error) {
+	content, err := fileIO.ReadFile(path.Join(engine.InputDir, contentPath))
+	if err != nil {
+		return "", err
+	}
+	body, frontMatterLines := stripFrontMatter(contentPath, string(content))
+	if !engine.MarkdownTemplates {
+		return body, nil
+	}
+
+	if body, err = expandShortcodes(body); err != nil {
+		var sourceErr *SourceError
+		if errors.As(err, &sourceErr) {
+			sourceErr.File = engine.inputFilePath(contentPath)
+			sourceErr.Line += frontMatterLines
+		}
+		return "", err
+	}
+
+	sources := partialSources(partialFiles)
+	sources[contentPath] = templateSource{path: contentPath, lineOffset: -frontMatterLines}
+	rendered, err := engine.renderTemplate(meta, contentPath, body, partialFiles)
+	if err != nil {
+		return "", engine.locateTemplateError(err, sources, fmt.Sprintf("rendering markdown %s", contentPath))
+	}
+	return string(rendered), nil
+}
+
+// convertMarkdown converts body, the markdown read from contentPath, to html.
+// A body converted before in the same build is not converted again.
+func (engine *Engine) convertMarkdown(contentPath string, body string) (markdown2html.Document, error) {
+	if document, ok := engine.markdownDocuments[body]; ok {
+		return document, nil
+	}
+	document, err := engine.Markdown.ConvertDocument([]byte(body))
+	if err != nil {
+		return document, &SourceError{Position: Position{File: path.Join(engine.InputDir, contentPath)}, Err: err}
+	}
+	if engine.markdownDocuments != nil {
+		engine.markdownDocuments[body] = document
+	}
+	return document, nil
+}
+
+// markdownTemplateContents returns the markdown content files and markdown
+// pages among fileList with their shortcodes expanded, when they are executed
+// as templates, so the partials and values they read count as used. Without
+// MarkdownTemplates it returns nothing.
+func (engine *Engine) markdownTemplateContents(fileList fileIO.FileList) ([]string, error) {
+	if !engine.MarkdownTemplates {
+		return nil, nil
+	}
+
+	var contents []string
+	for _, contentPath := range fileList.FilterByFilename(engine.MarkdownContentFilename).Files {
+		content, err := engine.readMarkdownTemplate(contentPath)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, content)
+	}
+	for _, page := range engine.markdownPages {
+		content, err := engine.readMarkdownTemplate(page.sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, content)
+	}
+	return contents, nil
+}
+
+// readMarkdownTemplate returns the markdown file at contentPath as the template
+// it is executed as, with its shortcodes expanded. Malformed shortcodes are left
+// for rendering to report.
+func (engine *Engine) readMarkdownTemplate(contentPath string) (string, error) {
+	content, err := fileIO.ReadFile(path.Join(engine.InputDir, contentPath))
+	if err != nil {
+		return "", err
+	}
+	body, _ := stripFrontMatter(contentPath, string(content))
+	if expanded, err := expandShortcodes(body); err == nil {
+		return expanded, nil
+	}
+	return body, nil
+}
@@ -0,0 +1,135 @@
+package temingo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandShortcodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "named arguments of every kind",
+			content: `Watch {{< video id="a \"b\"" start=30 autoplay=false title=.meta.title >}} now`,
+			want:    `Watch {{ component "video" "id" "a \"b\"" "start" 30 "autoplay" false "title" .meta.title }} now`,
+		},
+		{
+			name:    "no arguments, and text that only looks alike",
+			content: "{{<br>}}\n`{{ .x }}` and a < b > c",
+			want:    "{{ component \"br\" }}\n`{{ .x }}` and a < b > c",
+		},
+		{
+			name:    "malformed shortcode",
+			content: "Intro\n\nSee {{< video id=\"x\" >}} and {{< video id >}}",
+			wantErr: "3:30: malformed shortcode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandShortcodes(tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expandShortcodes() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandShortcodes() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expandShortcodes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_MarkdownTemplates(t *testing.T) {
+	files := map[string]string{
+		"components/video.component.html": "---\nparams:\n  id:\n    type: string\n    required: true\n  start:\n    type: int\n---\n<iframe src=\"https://example.com/{{ .id }}?t={{ .start }}\"></iframe>",
+		"blog/hello/meta.yaml":            "title: Hello\n",
+		"blog/hello/content.md":           "---\nauthor: Jane\n---\n# {{ .meta.title }} on {{ .siteName }}\n\nBy {{ .meta.author }}.\n\n{{< video id=\"abc\" start=30 >}}",
+		"blog/hello/index.template.html":  "{{ .content }}",
+		"_layout.partial.html":            "{{ .content }}",
+		"about.md":                        "---\ntitle: About\n---\n{{ .meta.title | capitalize }} {{ .siteName }}",
+	}
+
+	t.Run("executed before conversion", func(t *testing.T) {
+		engine, _, outputDir := setupWriteTestEngine(t, files)
+		engine.MarkdownTemplates = true
+		engine.Markdown.Unsafe = true // The html components render
+		engine.Values = map[string]interface{}{"siteName": "Example"}
+		engine.StrictValues = true
+
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() unexpected error: %v", err)
+		}
+		want := "<h1 id=\"hello-on-example\">Hello on Example</h1>\n<p>By Jane.</p>\n<iframe src=\"https://example.com/abc?t=30\"></iframe>"
+		if got := readOutput(t, outputDir, "blog/hello/index.html"); got != want {
+			t.Errorf("Render() blog/hello/index.html = %q, want %q", got, want)
+		}
+		if got := readOutput(t, outputDir, "about/index.html"); got != "<p>About Example</p>\n" {
+			t.Errorf("Render() about/index.html = %q, want the markdown page executed", got)
+		}
+	})
+
+	t.Run("converted verbatim by default", func(t *testing.T) {
+		engine, _, outputDir := setupWriteTestEngine(t, files)
+
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() unexpected error: %v", err)
+		}
+		if got := readOutput(t, outputDir, "about/index.html"); got != "<p>{{ .meta.title | capitalize }} {{ .siteName }}</p>\n" {
+			t.Errorf("Render() about/index.html = %q, want the markdown as written", got)
+		}
+	})
+}
+
+func TestRender_MarkdownTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "error below front matter",
+			files: map[string]string{
+				"content.md":          "---\ntitle: Home\n---\nIntro\n\n{{ .meta.title.nope }}",
+				"index.template.html": "{{ .content }}",
+			},
+			wantErr: "content.md:6:9: rendering markdown content.md",
+		},
+		{
+			name: "malformed shortcode",
+			files: map[string]string{
+				"content.md":          "---\ntitle: Home\n---\n{{< video id= >}}",
+				"index.template.html": "{{ .content }}",
+			},
+			wantErr: "content.md:4:1: malformed shortcode",
+		},
+		{
+			name: "shortcode for an unknown component",
+			files: map[string]string{
+				"content.md":          "{{< video >}}",
+				"index.template.html": "{{ .content }}",
+			},
+			wantErr: `no component named "video"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := setupWriteTestEngine(t, tt.files)
+			engine.MarkdownTemplates = true
+
+			err := engine.Render()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
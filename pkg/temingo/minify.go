@@ -1,9 +1,44 @@
 package temingo
 
+import (
+	"bytes"
+	"encoding/json"
+
+	minifycss "github.com/thetillhoff/temingo/pkg/minifyCSS"
+	minifyhtml "github.com/thetillhoff/temingo/pkg/minifyHTML"
+	minifyjs "github.com/thetillhoff/temingo/pkg/minifyJS"
+	minifyxml "github.com/thetillhoff/temingo/pkg/minifyXML"
+)
+
+// minifiedExtensions are the extensions minify handles. Static files with one of
+// them are minified on their way into the outputDir as well.
+var minifiedExtensions = []string{".html", ".css", ".js", ".svg", ".json", ".xml"}
+
 func (engine Engine) minify(content []byte, ext string) []byte {
+	logger := engine.Logger
+
 	switch ext {
+	case ".html":
+		logger.Debug("Minifying content", "extension", ext)
+		return []byte(minifyhtml.Minify(string(content)))
+	case ".css":
+		logger.Debug("Minifying content", "extension", ext)
+		return []byte(minifycss.Minify(string(content)))
+	case ".js":
+		logger.Debug("Minifying content", "extension", ext)
+		return []byte(minifyjs.Minify(string(content)))
+	case ".svg", ".xml":
+		logger.Debug("Minifying content", "extension", ext)
+		return []byte(minifyxml.Minify(string(content)))
+	case ".json":
+		logger.Debug("Minifying content", "extension", ext)
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, content); err != nil {
+			logger.Warn("Not minifying invalid JSON", "error", err)
+			return content
+		}
+		return compacted.Bytes()
 	default:
-		engine.Logger.Warn("Minification not implemented for extension", "extension", ext)
 		return content
 	}
 }
@@ -18,9 +53,6 @@ HTML and CSS is best minified together, because there are synergies.
 - separate stylesheets based on media queries (f.e. preferred-color-scheme, or `<link media="print">`)
 
 For CSS:
-- remove comments between `/* ... *\/`
-- remove empty lines
-- remove whitespace around selectors and properties, only leave brackets, commas and semicolons
 - identify existing objects (no matter if hover, active, etc), and remove unused ones
 - split critical css and inline it, while removing it from the original css file
   or use preload to indicate it's required even before the rendering starts
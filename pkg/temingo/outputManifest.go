@@ -0,0 +1,139 @@
+package temingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// outputManifestFilename is the name of the manifest inside the CacheDir.
+const outputManifestFilename = "manifest.json"
+
+// outputManifest records the content hash of every file in the outputDir, so a
+// build only writes the files whose bytes changed. Untouched files keep their
+// mtime, which is what rsync, S3 syncs and browser caches key on.
+type outputManifest struct {
+	// files maps output-relative paths to what was last written there.
+	files map[string]manifestEntry
+	// owned reports whether every path in files is a file temingo produced. A
+	// manifest seeded from an existing outputDir is not owned until a build has
+	// pruned it to its own outputs, and until then nothing may be deleted on
+	// its say-so: with noDeleteOutputDir the outputDir can hold anything,
+	// including the inputDir itself.
+	owned bool
+}
+
+// manifestEntry describes one output file. Size and modification time are kept
+// next to the hash so a file edited behind temingo's back is noticed, and
+// rewritten, without reading it.
+type manifestEntry struct {
+	SHA256  string    `json:"sha256"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// hashContent returns the hex sha256 of content.
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadOutputManifest returns the manifest of the previous build. It is read
+// from the CacheDir if one was persisted there, and otherwise seeded by hashing
+// whatever the outputDir already holds.
+func (engine *Engine) loadOutputManifest() (*outputManifest, error) {
+	manifest := &outputManifest{files: map[string]manifestEntry{}}
+
+	if engine.CacheDir != "" {
+		content, err := os.ReadFile(engine.cacheDir(outputManifestFilename))
+		if err == nil {
+			if err = json.Unmarshal(content, &manifest.files); err != nil {
+				return nil, fmt.Errorf("parsing output manifest: %w", err)
+			}
+			manifest.owned = true
+			engine.Logger.Debug("Loaded output manifest", "path", engine.cacheDir(outputManifestFilename), "files", len(manifest.files))
+			return manifest, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading output manifest: %w", err)
+		}
+	}
+
+	err := filepath.WalkDir(engine.OutputDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil // Nothing to seed from
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(engine.OutputDir, filePath)
+		if err != nil {
+			return err
+		}
+		return manifest.record(engine.OutputDir, filepath.ToSlash(rel), content)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("seeding output manifest from %s: %w", engine.OutputDir, err)
+	}
+	engine.Logger.Debug("Seeded output manifest from output directory", "path", engine.OutputDir, "files", len(manifest.files))
+
+	return manifest, nil
+}
+
+// unchanged reports whether outputPath already holds content.
+func (manifest *outputManifest) unchanged(outputDir string, outputPath string, content []byte) bool {
+	entry, ok := manifest.files[outputPath]
+	if !ok || entry.SHA256 != hashContent(content) {
+		return false
+	}
+	info, err := os.Stat(path.Join(outputDir, outputPath))
+	return err == nil && info.Size() == entry.Size && info.ModTime().Equal(entry.ModTime)
+}
+
+// record notes that outputPath now holds content.
+func (manifest *outputManifest) record(outputDir string, outputPath string, content []byte) error {
+	info, err := os.Stat(path.Join(outputDir, outputPath))
+	if err != nil {
+		return err
+	}
+	manifest.files[outputPath] = manifestEntry{
+		SHA256:  hashContent(content),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	return nil
+}
+
+// saveOutputManifest persists the manifest to the CacheDir. Without a CacheDir
+// it is only kept in memory, for the rebuilds of a watch session.
+func (engine *Engine) saveOutputManifest() error {
+	if engine.CacheDir == "" || engine.manifest == nil {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(engine.manifest.files, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding output manifest: %w", err)
+	}
+	if err = os.MkdirAll(engine.cacheDir(), 0755); err != nil {
+		return fmt.Errorf("creating cache directory %s: %w", engine.cacheDir(), err)
+	}
+	if err = os.WriteFile(engine.cacheDir(outputManifestFilename), content, 0644); err != nil {
+		return fmt.Errorf("writing output manifest: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package temingo
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Paginator is one page of a paginated listing, which a page whose meta
+// declares a page size with paginate: <size> gets as .paginator. The page is
+// rendered once for every page of items: the first to its own output path,
+// the others to page/<number>/ next to it.
+type Paginator struct {
+	Items      []interface{} // The items of this page
+	Current    int           // The number of this page, from 1
+	Total      int           // The number of pages, at least 1
+	Size       int           // The number of items per page
+	TotalItems int
+
+	// The URLs of the first, last, previous and next page. Prev and Next are
+	// empty on the first and the last page.
+	First, Last, Prev, Next string
+	// Pages links to every page, for numbered navigation.
+	Pages []PageLink
+
+	outputPath string // Of the first page
+}
+
+// PageLink is the number and URL of one page of a paginated listing.
+type PageLink struct {
+	Number int
+	URL    string
+}
+
+// newPaginator returns the page with number current of items, size at a time,
+// for the listing whose first page renders to outputPath. A map, like
+// .childMeta or the result of filterBy, is listed by key, with the same
+// {"key", "value"} entries sortBy returns.
+func newPaginator(outputPath string, current int, size int, items interface{}) (*Paginator, error) {
+	var list []interface{}
+	switch items := items.(type) {
+	case []interface{}:
+		list = items
+	case map[string]interface{}:
+		for _, key := range slices.Sorted(maps.Keys(items)) {
+			list = append(list, map[string]interface{}{"key": key, "value": items[key]})
+		}
+	case nil:
+	default:
+		return nil, fmt.Errorf("cannot paginate a %T, only lists and maps like .childMeta", items)
+	}
+
+	p := &Paginator{
+		Current:    current,
+		Total:      max((len(list)+size-1)/size, 1),
+		Size:       size,
+		TotalItems: len(list),
+		outputPath: outputPath,
+	}
+	start := min((current-1)*size, len(list))
+	p.Items = list[start:min(start+size, len(list))]
+	for number := 1; number <= p.Total; number++ {
+		p.Pages = append(p.Pages, PageLink{Number: number, URL: pageURL(outputPath, number)})
+	}
+	p.First, p.Last = pageURL(outputPath, 1), pageURL(outputPath, p.Total)
+	if current > 1 {
+		p.Prev = pageURL(outputPath, current-1)
+	}
+	if current < p.Total {
+		p.Next = pageURL(outputPath, current+1)
+	}
+	return p, nil
+}
+
+// pageSize returns the number of items per page the meta of a page declares
+// with paginate, or 0 if it declares none.
+func pageSize(meta interface{}) (int, error) {
+	fields, _ := meta.(map[string]interface{})
+	value, ok := fields["paginate"]
+	if !ok {
+		return 0, nil
+	}
+	size, _ := value.(int) // TOML integers are normalized to int as well
+	if size < 1 {
+		return 0, fmt.Errorf("paginate must be a positive number of items per page, got %v", value)
+	}
+	return size, nil
+}
+
+// pagePath returns the output path of page number of the listing whose first
+// page renders to outputPath: blog/index.html, blog/page/2/index.html, ...
+func pagePath(outputPath string, number int) string {
+	if number == 1 {
+		return outputPath
+	}
+	return path.Join(path.Dir(outputPath), "page", strconv.Itoa(number), path.Base(outputPath))
+}
+
+// pageURL returns the site-absolute URL of page number of the listing whose
+// first page renders to outputPath, without the filename of an index.html.
+func pageURL(outputPath string, number int) string {
+	url := "/" + pagePath(outputPath, number)
+	if path.Base(url) == "index.html" {
+		return strings.TrimSuffix(url, "index.html")
+	}
+	return url
+}
+
+// isPageOf reports whether pageOutputPath is a later page of the listing whose
+// first page renders to outputPath.
+func isPageOf(pageOutputPath string, outputPath string) bool {
+	rest, ok := strings.CutPrefix(pageOutputPath, path.Join(path.Dir(outputPath), "page")+"/")
+	if !ok {
+		return false
+	}
+	number, file, ok := strings.Cut(rest, "/")
+	n, err := strconv.Atoi(number)
+	return ok && err == nil && n > 1 && file == path.Base(outputPath)
+}
@@ -0,0 +1,96 @@
+package temingo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewPaginator(t *testing.T) {
+	items := []interface{}{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name      string
+		current   int
+		items     interface{}
+		wantItems []interface{}
+		wantTotal int
+		wantPrev  string
+		wantNext  string
+	}{
+		{name: "first page", current: 1, items: items, wantItems: []interface{}{"a", "b"}, wantTotal: 3, wantNext: "/blog/page/2/"},
+		{name: "middle page", current: 2, items: items, wantItems: []interface{}{"c", "d"}, wantTotal: 3, wantPrev: "/blog/", wantNext: "/blog/page/3/"},
+		{name: "last page", current: 3, items: items, wantItems: []interface{}{"e"}, wantTotal: 3, wantPrev: "/blog/page/2/"},
+		{
+			name:      "map listed by key",
+			current:   1,
+			items:     map[string]interface{}{"b": 2, "a": 1},
+			wantItems: []interface{}{map[string]interface{}{"key": "a", "value": 1}, map[string]interface{}{"key": "b", "value": 2}},
+			wantTotal: 1,
+		},
+		{name: "no items still make a page", current: 1, items: nil, wantTotal: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newPaginator("blog/index.html", tt.current, 2, tt.items)
+			if err != nil {
+				t.Fatalf("newPaginator() unexpected error: %v", err)
+			}
+			if len(p.Items) != len(tt.wantItems) || (len(tt.wantItems) > 0 && !reflect.DeepEqual(p.Items, tt.wantItems)) {
+				t.Errorf("Items = %v, want %v", p.Items, tt.wantItems)
+			}
+			if p.Total != tt.wantTotal || len(p.Pages) != tt.wantTotal {
+				t.Errorf("Total = %d with %d page links, want %d", p.Total, len(p.Pages), tt.wantTotal)
+			}
+			if p.Prev != tt.wantPrev || p.Next != tt.wantNext {
+				t.Errorf("Prev, Next = %q, %q, want %q, %q", p.Prev, p.Next, tt.wantPrev, tt.wantNext)
+			}
+			if p.First != "/blog/" {
+				t.Errorf("First = %q, want /blog/", p.First)
+			}
+		})
+	}
+
+	if _, err := newPaginator("index.html", 1, 2, "text"); err == nil || !strings.Contains(err.Error(), "cannot paginate a string") {
+		t.Errorf("newPaginator() of a string error = %v, want it to reject it", err)
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	tests := []struct {
+		outputPath string
+		number     int
+		want       string
+	}{
+		{outputPath: "index.html", number: 1, want: "/"},
+		{outputPath: "index.html", number: 2, want: "/page/2/"},
+		{outputPath: "blog/index.html", number: 3, want: "/blog/page/3/"},
+		{outputPath: "blog/myindex.html", number: 2, want: "/blog/page/2/myindex.html"},
+	}
+
+	for _, tt := range tests {
+		if got := pageURL(tt.outputPath, tt.number); got != tt.want {
+			t.Errorf("pageURL(%q, %d) = %q, want %q", tt.outputPath, tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestIsPageOf(t *testing.T) {
+	tests := []struct {
+		pageOutputPath string
+		want           bool
+	}{
+		{pageOutputPath: "blog/page/2/index.html", want: true},
+		{pageOutputPath: "blog/page/1/index.html", want: false},
+		{pageOutputPath: "blog/page/2/other.html", want: false},
+		{pageOutputPath: "blog/page/two/index.html", want: false},
+		{pageOutputPath: "blog/index.html", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isPageOf(tt.pageOutputPath, "blog/index.html"); got != tt.want {
+			t.Errorf("isPageOf(%q, blog/index.html) = %v, want %v", tt.pageOutputPath, got, tt.want)
+		}
+	}
+}
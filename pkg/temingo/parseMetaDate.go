@@ -0,0 +1,25 @@
+package temingo
+
+import (
+	"fmt"
+	"time"
+)
+
+// parseMetaDate reads a date set in a meta yaml, either as a date like
+// 2006-01-02 or as a timestamp like 2006-01-02T15:04:05Z. It also reports
+// whether the value was a date only, without a time.
+func parseMetaDate(value interface{}) (time.Time, bool, error) {
+	switch value := value.(type) {
+	case time.Time: // yaml decodes unquoted dates and timestamps itself
+		dateOnly := value.Equal(value.Truncate(24*time.Hour)) && value.Location() == time.UTC
+		return value, dateOnly, nil
+	case string:
+		if date, err := time.Parse(time.DateOnly, value); err == nil {
+			return date, true, nil
+		}
+		if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+			return timestamp, false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("must be a date like 2006-01-02 or a timestamp like 2006-01-02T15:04:05Z, got %v", value)
+}
@@ -0,0 +1,261 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/thetillhoff/fileIO"
+	"gopkg.in/yaml.v3"
+)
+
+// readFrontMatter parses the front matter of the templates, metatemplates,
+// markdown content files and markdown pages among filePaths, and keeps it on the engine by input
+// path. Files without front matter, or whose output is neither html nor
+// markdown, are left out.
+func (engine *Engine) readFrontMatter(filePaths []string) error {
+	frontMatter := map[string]map[string]interface{}{}
+
+	for _, filePath := range filePaths {
+		fields, err := engine.readFileFrontMatter(filePath)
+		if err != nil {
+			return err
+		}
+		if fields != nil {
+			frontMatter[filePath] = fields
+		}
+	}
+
+	engine.frontMatter = frontMatter
+	return nil
+}
+
+// updateFrontMatter reads the front matter of the input file filePath again,
+// after it changed, and keeps what readFrontMatter read for every other file.
+func (engine *Engine) updateFrontMatter(filePath string) error {
+	fields, err := engine.readFileFrontMatter(filePath)
+	if err != nil {
+		return err
+	}
+
+	frontMatter := maps.Clone(engine.frontMatter) // The last build holds on to the old one
+	delete(frontMatter, filePath)
+	if fields != nil {
+		frontMatter[filePath] = fields
+	}
+	engine.frontMatter = frontMatter
+	return nil
+}
+
+// readFileFrontMatter returns the front matter of the input file filePath, or
+// nil if it has none.
+func (engine *Engine) readFileFrontMatter(filePath string) (map[string]interface{}, error) {
+	if !hasFrontMatter(filePath) {
+		return nil, nil
+	}
+	content, err := fileIO.ReadFile(path.Join(engine.InputDir, filePath))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filePath, err)
+	}
+	return engine.parseFrontMatter(filePath, string(content))
+}
+
+// parseFrontMatter parses the front matter at the start of the content of the
+// input file filePath: yaml between --- lines, or toml between +++ lines. It
+// returns nil for content without front matter.
+func (engine *Engine) parseFrontMatter(filePath string, content string) (map[string]interface{}, error) {
+	if frontMatter, _, _, ok := splitFrontMatter(content, yamlFrontMatterDelimiter); ok {
+		fields := map[string]interface{}{}
+		if err := yaml.NewDecoder(strings.NewReader(frontMatter)).Decode(&fields); err != nil && !errors.Is(err, io.EOF) {
+			return nil, engine.locateFrontMatterError(err, filePath, "parsing front matter")
+		}
+		return fields, nil
+	}
+
+	if frontMatter, _, _, ok := splitFrontMatter(content, tomlFrontMatterDelimiter); ok {
+		fields := map[string]interface{}{}
+		if _, err := toml.Decode(frontMatter, &fields); err != nil {
+			return nil, engine.locateFrontMatterError(err, filePath, "parsing front matter")
+		}
+		return normalizeTOML(fields).(map[string]interface{}), nil
+	}
+
+	return nil, nil
+}
+
+// locateFrontMatterError turns an error from parsing the yaml or toml front
+// matter of the input file filePath into a SourceError at its line in the file,
+// described by context.
+func (engine *Engine) locateFrontMatterError(err error, filePath string, context string) error {
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		return &SourceError{
+			Position: Position{File: engine.inputFilePath(filePath), Line: parseErr.Position.Line + 1}, // The front matter starts on the second line
+			Err:      fmt.Errorf("%s: %s", context, parseErr.Message),
+		}
+	}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 { // Like "line 3: field require not found in type temingo.componentParam"
+		message, _, _ := strings.Cut(typeErr.Errors[0], " in type ")
+		err = errors.New("yaml: " + message)
+	}
+
+	located := engine.locateYAMLError(err, filePath)
+	var sourceErr *SourceError
+	if errors.As(located, &sourceErr) {
+		if sourceErr.Line > 0 {
+			sourceErr.Line++ // The front matter starts on the second line
+		}
+		sourceErr.Err = fmt.Errorf("%s: %w", context, sourceErr.Err)
+	}
+	return located
+}
+
+// normalizeTOML converts decoded toml into the types yaml decodes into, so
+// front matter reads the same in either format: int instead of int64, a list
+// for an array of tables, and dates and date-times without an offset in UTC.
+// A time of day without a date stays text.
+func normalizeTOML(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, entry := range value {
+			value[key] = normalizeTOML(entry)
+		}
+		return value
+	case []interface{}:
+		for i, entry := range value {
+			value[i] = normalizeTOML(entry)
+		}
+		return value
+	case []map[string]interface{}:
+		list := make([]interface{}, len(value))
+		for i, entry := range value {
+			list[i] = normalizeTOML(entry)
+		}
+		return list
+	case int64:
+		return int(value)
+	case time.Time:
+		switch value.Location().String() { // The zones toml marks local dates and times with
+		case "time-local":
+			return value.Format("15:04:05")
+		case "date-local", "datetime-local":
+			return time.Date(value.Year(), value.Month(), value.Day(), value.Hour(), value.Minute(), value.Second(), value.Nanosecond(), time.UTC)
+		}
+		return value
+	}
+	return value
+}
+
+// hasFrontMatter returns whether the input file filePath can start with front
+// matter. Only html and markdown sources can; in other formats a leading ---
+// or +++ line belongs to the output, like the document separators of a yaml
+// file.
+func hasFrontMatter(filePath string) bool {
+	switch path.Ext(filePath) {
+	case ".html", ".htm", ".md":
+		return true
+	}
+	return false
+}
+
+// stripFrontMatter returns content of the input file filePath without its front
+// matter, and the number of lines the front matter took up.
+func stripFrontMatter(filePath string, content string) (string, int) {
+	if !hasFrontMatter(filePath) {
+		return content, 0
+	}
+	for _, delimiter := range []string{yamlFrontMatterDelimiter, tomlFrontMatterDelimiter} {
+		if _, body, lines, ok := splitFrontMatter(content, delimiter); ok {
+			return body, lines
+		}
+	}
+	return content, 0
+}
+
+// frontMatterSources returns the files whose front matter applies to the page
+// at outputPath, lowest precedence first: the metatemplate that renders it,
+// the template that renders it, the markdown content file in its folder, and
+// the markdown page it is rendered from.
+func (engine *Engine) frontMatterSources(outputPath string) []string {
+	var (
+		folder           = path.Dir(outputPath)
+		metaTemplatePath string
+		templatePath     string
+		sources          []string
+	)
+
+	for sourcePath := range engine.frontMatter {
+		switch {
+		case path.Base(sourcePath) == engine.MarkdownContentFilename, engine.isMarkdownPage(sourcePath):
+			// Added last, below
+		case engine.isMetaTemplate(sourcePath):
+			rendersFolder := path.Dir(sourcePath) == path.Dir(folder) || (engine.isRecursiveMetaTemplate(sourcePath) && isBelowFolder(folder, path.Dir(sourcePath)))
+			if folder != "." && rendersFolder && strings.ReplaceAll(path.Base(sourcePath), engine.MetaTemplateExtension, "") == path.Base(outputPath) {
+				metaTemplatePath = sourcePath
+			}
+		case strings.ReplaceAll(sourcePath, engine.TemplateExtension, "") == outputPath, engine.termPages[outputPath].templatePath == sourcePath:
+			templatePath = sourcePath
+		}
+	}
+
+	for _, sourcePath := range []string{metaTemplatePath, templatePath, path.Join(folder, engine.MarkdownContentFilename), engine.markdownPages[outputPath].sourcePath} {
+		if _, ok := engine.frontMatter[sourcePath]; ok {
+			sources = append(sources, sourcePath)
+		}
+	}
+	return sources
+}
+
+// folderFrontMatterSources returns the templates directly in folder that have
+// front matter, followed by its markdown content file if that has front matter.
+// Together they make up the front matter of the folder's page as its parent
+// sees it in .childMeta.
+func (engine *Engine) folderFrontMatterSources(folder string) []string {
+	var sources []string
+	for _, sourcePath := range slices.Sorted(maps.Keys(engine.frontMatter)) {
+		if path.Dir(sourcePath) == folder && !engine.isMetaTemplate(sourcePath) && !engine.isMarkdownPage(sourcePath) && !engine.isTermTemplate(sourcePath) && path.Base(sourcePath) != engine.MarkdownContentFilename {
+			sources = append(sources, sourcePath)
+		}
+	}
+	if contentPath := path.Join(folder, engine.MarkdownContentFilename); engine.frontMatter[contentPath] != nil {
+		sources = append(sources, contentPath)
+	}
+	return sources
+}
+
+// childPageFolders returns the direct child folders of folder that a
+// metatemplate in it renders for: those with a meta yaml, and those whose
+// markdown content file has front matter. With templates set, it also returns
+// those whose templates have front matter, which show up in .childMeta.
+func (engine *Engine) childPageFolders(folder string, metaPaths []string, templates bool) []string {
+	folders := []string{}
+	for _, metaPath := range (fileIO.FileList{Files: metaPaths}).FilterByLevelAtFolderPath(folder, 1).Files {
+		folders = append(folders, path.Dir(metaPath))
+	}
+	for _, sourcePath := range (fileIO.FileList{Files: slices.Collect(maps.Keys(engine.frontMatter))}).FilterByLevelAtFolderPath(folder, 1).Files {
+		if path.Base(sourcePath) == engine.MarkdownContentFilename || (templates && !engine.isMetaTemplate(sourcePath) && !engine.isMarkdownPage(sourcePath) && !engine.isTermTemplate(sourcePath)) {
+			folders = append(folders, path.Dir(sourcePath))
+		}
+	}
+	slices.Sort(folders)
+	return slices.Compact(folders)
+}
+
+// overrideMeta returns a copy of meta with the keys of frontMatter set over its
+// own.
+func overrideMeta(meta interface{}, frontMatter map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	if fields, ok := meta.(map[string]interface{}); ok {
+		maps.Copy(result, fields)
+	}
+	maps.Copy(result, frontMatter)
+	return result
+}
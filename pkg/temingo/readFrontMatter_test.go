@@ -0,0 +1,218 @@
+package temingo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender_FrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		output   string
+		want     string
+		wantNone []string // Outputs that must not be rendered
+	}{
+		{
+			name: "template front matter overrides meta yaml and is stripped",
+			files: map[string]string{
+				"about/meta.yaml":           "title: Meta\nlang: en\n",
+				"about/index.template.html": "---\ntitle: About\n---\n{{ .meta.title }} {{ .meta.lang }}",
+			},
+			output: "about/index.html",
+			want:   "About en",
+		},
+		{
+			name: "toml front matter",
+			files: map[string]string{
+				"index.template.html": "+++\ntitle = \"Home\"\nweight = 3\ndate = 2024-01-02\n+++\n{{ .meta.title }} {{ printf \"%T\" .meta.weight }} {{ .meta.date.Format \"2006-01-02\" }}",
+			},
+			output: "index.html",
+			want:   "Home int 2024-01-02",
+		},
+		{
+			name: "content.md front matter ranks above the template's and is stripped from the content",
+			files: map[string]string{
+				"post/index.template.html": "---\ntitle: Template\nauthor: Ann\n---\n{{ .meta.title }} by {{ .meta.author }}: {{ .content }}",
+				"post/content.md":          "---\ntitle: Post\n---\nHello",
+			},
+			output: "post/index.html",
+			want:   "Post by Ann: <p>Hello</p>\n",
+		},
+		{
+			name: "child front matter shows up in childMeta",
+			files: map[string]string{
+				"blog/index.template.html":        "{{ range $name, $post := .childMeta }}{{ $name }}={{ $post.title }} {{ end }}",
+				"blog/first/index.template.html":  "---\ntitle: First\n---\nfirst",
+				"blog/second/meta.yaml":           "title: Meta\ndate: 2024-01-01\n",
+				"blog/second/content.md":          "---\ntitle: Second\n---\nsecond",
+				"blog/third/meta.yaml":            "title: Third\n",
+				"blog/third/index.template.html":  "third",
+				"blog/fourth/index.template.html": "no front matter",
+			},
+			output: "blog/index.html",
+			want:   "first=First second=Second third=Third ",
+		},
+		{
+			name: "the page's own front matter is not inherited by its children",
+			files: map[string]string{
+				"blog/index.template.html":       "---\nsubtitle: Blog\n---\n{{ range .childMeta }}[{{ .title }}{{ with .subtitle }}/{{ . }}{{ end }}]{{ end }}",
+				"blog/first/index.template.html": "---\ntitle: First\n---\nfirst",
+			},
+			output: "blog/index.html",
+			want:   "[First]",
+		},
+		{
+			name: "metatemplate renders for content.md front matter, with its own front matter as defaults",
+			files: map[string]string{
+				"blog/index.metatemplate.html": "---\nlayout: post\ntitle: Untitled\n---\n{{ .meta.layout }}: {{ .meta.title }}",
+				"blog/first/content.md":        "---\ntitle: First\n---\nfirst",
+				"blog/second/meta.yaml":        "draft: true\n",
+				"blog/third/content.md":        "no front matter",
+			},
+			output:   "blog/first/index.html",
+			want:     "post: First",
+			wantNone: []string{"blog/third/index.html"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, outputDir := setupWriteTestEngine(t, tt.files)
+
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+			if got := readOutput(t, outputDir, tt.output); got != tt.want {
+				t.Errorf("Render() %s = %q, want %q", tt.output, got, tt.want)
+			}
+			for _, outputPath := range tt.wantNone {
+				if _, err := os.Stat(filepath.Join(outputDir, outputPath)); err == nil {
+					t.Errorf("Render() wrote %s", outputPath)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_FrontMatterOnlyInHtmlAndMarkdown(t *testing.T) {
+	k8s := "---\napiVersion: v1\nkind: A\n---\napiVersion: v1\nkind: B\n"
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"k8s.template.yaml": k8s,
+	})
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "k8s.yaml"); got != k8s {
+		t.Errorf("Render() k8s.yaml = %q, want %q", got, k8s)
+	}
+	if fields, ok := engine.frontMatter["k8s.template.yaml"]; ok {
+		t.Errorf("Render() read front matter %v from a yaml template", fields)
+	}
+}
+
+func TestRender_FrontMatterErrorPositions(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		file     string
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name:     "yaml front matter",
+			files:    map[string]string{"index.template.html": "---\ntitle: a\ntitle: b\n---\nbody"},
+			file:     "index.template.html",
+			wantLine: 3,
+			wantErr:  "parsing front matter",
+		},
+		{
+			name:     "toml front matter",
+			files:    map[string]string{"post/content.md": "+++\ntitle = \"a\"\ndraft = yes\n+++\nbody", "post/index.template.html": "x"},
+			file:     "post/content.md",
+			wantLine: 3,
+			wantErr:  "parsing front matter",
+		},
+		{
+			name:     "template error below front matter",
+			files:    map[string]string{"index.template.html": "---\ntitle: a\n---\nline 4\n{{ .meta.title.nope }}"},
+			file:     "index.template.html",
+			wantLine: 5,
+			wantErr:  "nope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, inputDir, _ := setupWriteTestEngine(t, tt.files)
+
+			err := engine.Render()
+			var sourceErr *SourceError
+			if !errors.As(err, &sourceErr) {
+				t.Fatalf("Render() error = %v, want a SourceError", err)
+			}
+			if want := filepath.Join(inputDir, tt.file); sourceErr.File != want || sourceErr.Line != tt.wantLine {
+				t.Errorf("Render() error at %s:%d, want %s:%d", sourceErr.File, sourceErr.Line, want, tt.wantLine)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderChanged_FrontMatter(t *testing.T) {
+	files := map[string]string{
+		"blog/index.template.html":        "{{ range .childMeta }}{{ .title }} {{ end }}",
+		"blog/first/index.template.html":  "---\ntitle: First\n---\n{{ .meta.title }}",
+		"blog/second/index.template.html": "second",
+		"about/index.template.html":       "about",
+	}
+
+	t.Run("changed front matter rebuilds the parent", func(t *testing.T) {
+		engine, inputDir, outputDir := setupWriteTestEngine(t, files)
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() unexpected error: %v", err)
+		}
+		markOutputs(t, outputDir, "blog/index.html", "blog/second/index.html", "about/index.html")
+
+		writeTestFiles(t, inputDir, map[string]string{"blog/first/index.template.html": "---\ntitle: Renamed\n---\n{{ .meta.title }}"})
+		if err := engine.RenderChanged(filepath.Join(inputDir, "blog/first/index.template.html")); err != nil {
+			t.Fatalf("RenderChanged() unexpected error: %v", err)
+		}
+
+		for outputPath, want := range map[string]string{
+			"blog/index.html":        "Renamed ",
+			"blog/first/index.html":  "Renamed",
+			"blog/second/index.html": "untouched",
+			"about/index.html":       "untouched",
+		} {
+			if got := readOutput(t, outputDir, outputPath); got != want {
+				t.Errorf("RenderChanged() %s = %q, want %q", outputPath, got, want)
+			}
+		}
+	})
+
+	t.Run("added front matter rebuilds everything", func(t *testing.T) {
+		engine, inputDir, outputDir := setupWriteTestEngine(t, files)
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() unexpected error: %v", err)
+		}
+
+		writeTestFiles(t, inputDir, map[string]string{"blog/second/index.template.html": "---\ntitle: Second\n---\nsecond"})
+		if err := engine.RenderChanged(filepath.Join(inputDir, "blog/second/index.template.html")); err != nil {
+			t.Fatalf("RenderChanged() unexpected error: %v", err)
+		}
+
+		if got := readOutput(t, outputDir, "blog/index.html"); got != "First Second " {
+			t.Errorf("RenderChanged() blog/index.html = %q, want %q", got, "First Second ")
+		}
+		if got := readOutput(t, outputDir, "blog/second/index.html"); got != "second" {
+			t.Errorf("RenderChanged() blog/second/index.html = %q, want the front matter stripped", got)
+		}
+	})
+}
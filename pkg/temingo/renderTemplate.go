@@ -2,6 +2,7 @@ package temingo
 
 import (
 	"bytes"
+	"maps"
 	"text/template"
 )
 
@@ -22,9 +23,13 @@ func (engine *Engine) renderTemplate(meta map[string]interface{}, templatePath s
 
 	// Defining additional template functions
 	templateEngine = templateEngine.Funcs(templateFuncMap(engine))
+	templateEngine = templateEngine.Funcs(engine.componentFuncs(templateEngine))
+	pagePath, _ := meta["path"].(string)
+	templateEngine = templateEngine.Funcs(engine.imageFuncs(pagePath))
+	templateEngine = templateEngine.Funcs(engine.paginateFuncs(meta))
 
-	for _, partialFileContent := range partialFiles { // For each partialFile
-		if _, err = templateEngine.Parse(partialFileContent); err != nil { // Parse the partials contained in it
+	for partialPath, partialFileContent := range partialFiles { // For each partialFile
+		if _, err = templateEngine.New(partialPath).Parse(partialFileContent); err != nil { // Parse the partials contained in it, under their own name so errors point at the partial file
 			return nil, err
 		}
 	}
@@ -34,9 +39,25 @@ func (engine *Engine) renderTemplate(meta map[string]interface{}, templatePath s
 		return nil, err
 	}
 
+	if !engine.NoAutoIndent { // Lets the output of every {{ template }} be indented like its call
+		sources := map[string]string{templatePath: templateContent}
+		maps.Copy(sources, partialFiles)
+		markTemplateCalls(templateEngine, sources)
+	}
+
+	var errorContexts map[string]string
+	if engine.valueReads != nil { // Record the values and meta keys it reads, for checkUnusedValues
+		templateEngine = templateEngine.Funcs(engine.valueReads.funcs())
+		errorContexts = instrumentReads(templateEngine)
+	}
+
 	err = templateEngine.Execute(outputBuffer, meta)
 	if err != nil {
-		return nil, err
+		return nil, restoreErrorContexts(err, errorContexts)
+	}
+
+	if !engine.NoAutoIndent {
+		return indentTemplateCalls(outputBuffer.Bytes()), nil
 	}
 
 	// Return rendered template
@@ -0,0 +1,192 @@
+package temingo
+
+import (
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// Site is the index of every page the build renders, computed before any of
+// them is rendered. Every template gets it as .site, and pagesIn and pageAt
+// query it.
+type Site struct {
+	Pages    map[string]*Page // By URL
+	Sections []string         // The top-level folders with pages, sorted
+}
+
+// Page is one page in the site index.
+type Page struct {
+	Path        string // The output path, like blog/first/index.html
+	URL         string // Like /blog/first/
+	Meta        interface{}
+	Breadcrumbs []Breadcrumb
+	Section     string // The top-level folder of the page, empty at the root
+	Summary     string // The html of the summary of its markdown content, if it has any
+}
+
+// pageOutputPaths returns the output paths of the html pages the templates,
+// metatemplates and markdown pages render, sorted. Term pages and the later
+// pages of paginated listings are not among them.
+func (engine *Engine) pageOutputPaths(templatePaths []string, metaTemplatePaths []string, metaPaths []string) []string {
+	outputPaths := []string{}
+	for _, templatePath := range templatePaths {
+		if !engine.isTermTemplate(templatePath) {
+			outputPaths = append(outputPaths, strings.ReplaceAll(templatePath, engine.TemplateExtension, ""))
+		}
+	}
+	for _, metaTemplatePath := range metaTemplatePaths {
+		outputPaths = append(outputPaths, engine.metaTemplateOutputPaths(metaTemplatePath, metaPaths)...)
+	}
+	outputPaths = append(outputPaths, slices.Collect(maps.Keys(engine.markdownPages))...)
+
+	outputPaths = slices.DeleteFunc(outputPaths, func(outputPath string) bool {
+		return path.Ext(outputPath) != ".html"
+	})
+	slices.Sort(outputPaths)
+	return slices.Compact(outputPaths)
+}
+
+// readSite indexes every page the build renders, term pages included, with
+// its meta and the summary of its markdown content. When no template reads the
+// index, it stays empty. While the index is read, .site is empty, so markdown
+// executed as a template sees no pages.
+func (engine *Engine) readSite(templatePaths []string, metaTemplatePaths []string, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) error {
+	engine.site = &Site{Pages: map[string]*Page{}, Sections: []string{}}
+	engine.pageMetas = map[string]pageMeta{}
+	if !engine.siteIsRead() {
+		return nil
+	}
+
+	outputPaths := engine.pageOutputPaths(templatePaths, metaTemplatePaths, metaPaths)
+	outputPaths = append(outputPaths, slices.Sorted(maps.Keys(engine.termPages))...)
+
+	site := &Site{Pages: map[string]*Page{}, Sections: []string{}}
+	for _, outputPath := range outputPaths {
+		meta, _, err := engine.getMetaForTemplatePath(fileIO.FileList{Files: metaPaths}, outputPath)
+		if err != nil {
+			return err
+		}
+		summary, err := engine.pageSummary(outputPath, fileList, metaPaths, partialFiles)
+		if err != nil {
+			return err
+		}
+		page := &Page{
+			Path:        outputPath,
+			URL:         pageURL(outputPath, 1),
+			Meta:        meta,
+			Breadcrumbs: createBreadcrumbs(outputPath),
+			Section:     pageSection(outputPath),
+			Summary:     summary,
+		}
+		site.Pages[page.URL] = page
+		if page.Section != "" && !slices.Contains(site.Sections, page.Section) {
+			site.Sections = append(site.Sections, page.Section)
+		}
+	}
+	slices.Sort(site.Sections)
+
+	engine.site = site
+	return nil
+}
+
+// pageMeta is the meta object of a page that reading the site index computed,
+// with the images that executing its markdown showed.
+type pageMeta struct {
+	meta            map[string]interface{}
+	imageReferences []string
+	imageVariants   []imageVariant
+}
+
+// pageSummary returns the html summary of the markdown content of the page at
+// outputPath, or "" if it has none. Markdown executed as a template needs the
+// whole meta object of the page, which is kept for rendering the page unless
+// the markdown reads the site index, which is still empty.
+func (engine *Engine) pageSummary(outputPath string, fileList fileIO.FileList, metaPaths []string, partialFiles map[string]string) (string, error) {
+	contentPath := engine.markdownContentPath(outputPath, fileList)
+	if contentPath == "" {
+		return "", nil
+	}
+
+	if engine.MarkdownTemplates {
+		// The images the markdown shows are the page's once it is rendered with
+		// this meta object, and until then those of its last rendering are kept
+		references, variants := engine.imageReferences[outputPath], engine.imageVariants[outputPath]
+		delete(engine.imageReferences, outputPath)
+		delete(engine.imageVariants, outputPath)
+		meta, err := engine.generateMetaObjectForTemplatePath(outputPath, fileList, metaPaths, partialFiles)
+		if err != nil {
+			return "", err
+		}
+		page := pageMeta{meta: meta, imageReferences: engine.imageReferences[outputPath], imageVariants: engine.imageVariants[outputPath]}
+		engine.imageReferences[outputPath], engine.imageVariants[outputPath] = references, variants
+
+		if content, err := engine.readMarkdownTemplate(contentPath); err == nil {
+			names := map[string]bool{}
+			if err = engine.collectReferencedNames(content, names); err == nil && !names["site"] && !names["pagesIn"] && !names["pageAt"] {
+				engine.pageMetas[outputPath] = page
+			}
+		}
+		summary, _ := meta["summary"].(string)
+		return summary, nil
+	}
+
+	body, err := engine.readMarkdownContent(contentPath, nil, partialFiles)
+	if err != nil {
+		return "", err
+	}
+	document, err := engine.convertMarkdown(contentPath, body)
+	if err != nil {
+		return "", err
+	}
+	return document.Summary, nil
+}
+
+// pageSection returns the top-level folder of the page at outputPath, or "" for
+// a page at the root.
+func pageSection(outputPath string) string {
+	section, _, ok := strings.Cut(outputPath, "/")
+	if !ok {
+		return ""
+	}
+	return section
+}
+
+// siteIsRead reports whether any template, metatemplate, partial or markdown
+// template refers to .site, pagesIn or pageAt, in which case a change to the
+// site index can change any page.
+func (engine *Engine) siteIsRead() bool {
+	return engine.referencedNames["site"] || engine.referencedNames["pagesIn"] || engine.referencedNames["pageAt"]
+}
+
+// readReferencedNames collects the names every template, metatemplate,
+// partial, markdown page layout and markdown template refers to, into
+// engine.referencedNames.
+func (engine *Engine) readReferencedNames(templatePaths []string, metaTemplatePaths []string, partialFiles map[string]string, fileList fileIO.FileList) error {
+	contents := slices.Collect(maps.Values(partialFiles))
+	for _, templatePath := range slices.Concat(templatePaths, metaTemplatePaths) {
+		content, err := fileIO.ReadFile(path.Join(engine.InputDir, templatePath))
+		if err != nil {
+			return err
+		}
+		body, _ := stripFrontMatter(templatePath, string(content))
+		contents = append(contents, body)
+	}
+	for _, page := range engine.markdownPages {
+		contents = append(contents, page.templateContent())
+	}
+	markdownTemplates, err := engine.markdownTemplateContents(fileList)
+	if err != nil {
+		return err
+	}
+	contents = append(contents, markdownTemplates...)
+
+	names := map[string]bool{}
+	for _, content := range contents {
+		_ = engine.collectReferencedNames(content, names) // One that does not parse fails the build when it is rendered, at its position
+	}
+	engine.referencedNames = names
+	return nil
+}
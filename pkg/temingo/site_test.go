@@ -0,0 +1,172 @@
+package temingo
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// siteFiles returns a site with a blog, an about page and a footer partial
+// listing the latest posts, which every page includes.
+func siteFiles() map[string]string {
+	return map[string]string{
+		"footer.partial.html":                  `{{ range reverse (sortBy "date" (pagesIn "blog")) }}[{{ .value.title }}]{{ end }}`,
+		"index.template.html":                  `{{ range .site.Sections }}{{ . }} {{ end }}{{ with pageAt "/about/" }}<a href="{{ .URL }}">{{ .Meta.title }}</a>{{ end }}{{ template "footer.partial.html" . }}`,
+		"about/index.template.html":            "---\ntitle: About\n---\n{{ .meta.title }}{{ template \"footer.partial.html\" . }}",
+		"blog/index.template.html":             `{{ range $url, $page := .site.Pages }}{{ if eq $page.Section "blog" }}{{ $url }}:{{ $page.Summary }}|{{ end }}{{ end }}`,
+		"blog/first/meta.yaml":                 "title: First\ndate: 2024-01-01\n",
+		"blog/first/content.md":                "Hello\n\nMore",
+		"blog/first/index.template.html":       "{{ .content }}",
+		"blog/2024/second/index.template.html": "---\ntitle: Second\ndate: 2024-02-01\n---\n{{ (pageAt \"blog/first/index.html\").Meta.title }}",
+	}
+}
+
+func TestRender_Site(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, siteFiles())
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	for outputPath, want := range map[string]string{
+		"index.html":                  `about blog <a href="/about/">About</a>[Second][First]`,
+		"about/index.html":            "About[Second][First]",
+		"blog/index.html":             "/blog/:|/blog/2024/second/:|/blog/first/:<p>Hello</p>\n|",
+		"blog/2024/second/index.html": "First",
+	} {
+		if got := readOutput(t, outputDir, outputPath); got != want {
+			t.Errorf("Render() %s = %q, want %q", outputPath, got, want)
+		}
+	}
+
+	page := engine.site.Pages["/blog/2024/second/"]
+	if page == nil || page.Path != "blog/2024/second/index.html" || page.Section != "blog" || len(page.Breadcrumbs) != 2 {
+		t.Errorf("site page /blog/2024/second/ = %+v, want its path, section and breadcrumbs", page)
+	}
+}
+
+func TestRender_SiteErrors(t *testing.T) {
+	engine, _, _ := setupWriteTestEngine(t, map[string]string{
+		"index.template.html": `{{ pageAt "/missing/" }}`,
+	})
+
+	err := engine.Render()
+	if err == nil || !strings.Contains(err.Error(), "pageAt: no page renders at /missing/") {
+		t.Errorf("Render() error = %v, want it to name the missing page", err)
+	}
+}
+
+func TestRenderChanged_Site(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, siteFiles())
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	// A changed title reaches every page that lists it through the site index
+	writeTestFiles(t, inputDir, map[string]string{"blog/first/meta.yaml": "title: Renamed\ndate: 2024-01-01\n"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "blog/first/meta.yaml")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	for _, outputPath := range []string{"index.html", "about/index.html"} {
+		if got := readOutput(t, outputDir, outputPath); !strings.HasSuffix(got, "[Second][Renamed]") {
+			t.Errorf("RenderChanged() %s = %q, want the new title", outputPath, got)
+		}
+	}
+	if got := readOutput(t, outputDir, "blog/2024/second/index.html"); got != "Renamed" {
+		t.Errorf("RenderChanged() blog/2024/second/index.html = %q, want Renamed", got)
+	}
+}
+
+func TestRenderChanged_SiteAfterFailedRebuild(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"about/index.template.html":    `{{ (pageAt "/blog/first/").Meta.title }}`,
+		"blog/index.metatemplate.html": "{{ .meta.title }}",
+		"blog/first/meta.yaml":         "title: First\n",
+	})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	// The post is renamed in the same event that breaks the metatemplate
+	writeTestFiles(t, inputDir, map[string]string{
+		"blog/first/meta.yaml":         "title: Renamed\n",
+		"blog/index.metatemplate.html": "{{ .meta.title }",
+	})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "blog/first/meta.yaml")); err == nil {
+		t.Fatalf("RenderChanged() with a broken metatemplate unexpectedly succeeded")
+	}
+
+	writeTestFiles(t, inputDir, map[string]string{"blog/index.metatemplate.html": "{{ .meta.title }}"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "blog/index.metatemplate.html")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "about/index.html"); got != "Renamed" {
+		t.Errorf("RenderChanged() about/index.html = %q, want the renamed post", got)
+	}
+}
+
+func TestRender_SiteOnlyWhenRead(t *testing.T) {
+	engine, _, _ := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":       "{{ .path }}",
+		"about/meta.yaml":           "title: About\n",
+		"about/index.template.html": "{{ .meta.title }}",
+	})
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if len(engine.site.Pages) != 0 {
+		t.Errorf("Render() indexed %d pages, want none when no template reads the index", len(engine.site.Pages))
+	}
+}
+
+func TestRender_SiteMarkdownTemplates(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":            `{{ range $url, $page := .site.Pages }}{{ $url }}:{{ $page.Summary }}|{{ end }}`,
+		"blog/first/meta.yaml":           "title: First\n",
+		"blog/first/content.md":          "{{ .meta.title }}",
+		"blog/first/index.template.html": "{{ .content }}",
+		"blog/list/meta.yaml":            "title: List\n",
+		"blog/list/content.md":           `{{ range pagesIn "blog" }}{{ .title }}{{ end }}`,
+		"blog/list/index.template.html":  "{{ .content }}",
+	})
+	engine.MarkdownTemplates = true
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	for outputPath, want := range map[string]string{
+		"index.html":            "/:|/blog/first/:<p>First</p>\n|/blog/list/:|",
+		"blog/first/index.html": "<p>First</p>\n",
+		"blog/list/index.html":  "<p>FirstList</p>\n",
+	} {
+		if got := readOutput(t, outputDir, outputPath); got != want {
+			t.Errorf("Render() %s = %q, want %q", outputPath, got, want)
+		}
+	}
+	if len(engine.pageMetas) != 0 {
+		t.Errorf("Render() left %d meta objects unused, want every one reused", len(engine.pageMetas))
+	}
+}
+
+func TestRender_SiteMarkdownTemplatesKeepImages(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":            `{{ len .site.Pages }}`,
+		"blog/first/meta.yaml":           "title: First\n",
+		"blog/first/hero.png":            encodeTestImage(t, "png", 40, 20),
+		"blog/first/content.md":          `{{ image "hero.png" "10w" "alt" .meta.title }}`,
+		"blog/first/index.template.html": "{{ .content }}",
+	})
+	engine.MarkdownTemplates = true
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	readOutput(t, outputDir, "blog/first/hero-10w.webp")
+	if deps := engine.lastBuild.outputs["blog/first/index.html"].dependencies; !slices.Contains(deps, "blog/first/hero.png") {
+		t.Errorf("dependencies of blog/first/index.html = %v, want the image among them", deps)
+	}
+}
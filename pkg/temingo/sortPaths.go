@@ -9,14 +9,15 @@ import (
 )
 
 // Takes the paths from FileList.Files and sorts them into one list per filetype
-// Order of returned lists: templatePaths, metaTemplatePaths, partialPaths, metaPaths, staticPaths
-func (engine *Engine) sortPaths(fileList fileIO.FileList) ([]string, []string, []string, []string, []string, []string) {
+// Order of returned lists: templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, markdownPagePaths, staticPaths
+func (engine *Engine) sortPaths(fileList fileIO.FileList) ([]string, []string, []string, []string, []string, []string, []string) {
 	var (
 		templatePaths        []string
 		metaTemplatePaths    []string
 		partialPaths         []string
 		metaPaths            []string
 		markdownContentPaths []string
+		markdownPagePaths    []string
 		staticPaths          []string
 	)
 
@@ -25,6 +26,9 @@ func (engine *Engine) sortPaths(fileList fileIO.FileList) ([]string, []string, [
 		if strings.Contains(filePath, engine.PartialExtension) { // Multiple extensions are possible, so simply using path.Ext() is not enough (it only returns the last extension)
 			partialPaths = append(partialPaths, filePath)
 			logger.Debug("Identified as partial file", "path", filePath)
+		} else if engine.isComponent(filePath) { // Components are partials with parameters, and are read along with them
+			partialPaths = append(partialPaths, filePath)
+			logger.Debug("Identified as component file", "path", filePath)
 		} else if strings.Contains(filePath, engine.TemplateExtension) { // Multiple extensions are possible, so simply using path.Ext() is not enough (it only returns the last extension)
 			templatePaths = append(templatePaths, filePath)
 			logger.Debug("Identified as template file", "path", filePath)
@@ -37,7 +41,10 @@ func (engine *Engine) sortPaths(fileList fileIO.FileList) ([]string, []string, [
 		} else if path.Base(filePath) == engine.MarkdownContentFilename { // Making it easier to filter through them later and exclude them from staticPaths - they are not static files that should be copied to the outputDir
 			markdownContentPaths = append(markdownContentPaths, filePath)
 			logger.Debug("Identified as markdown content file", "path", filePath)
-		} else if slices.Contains(engine.ValuesFilePaths, filePath) { // Exclude values files from static files - they should not be copied to the outputDir
+		} else if engine.isMarkdownPage(filePath) { // Whether it has a layout to be rendered with is decided once the partials and front matter are read
+			markdownPagePaths = append(markdownPagePaths, filePath)
+			logger.Debug("Identified as markdown page file", "path", filePath)
+		} else if slices.Contains(engine.ValuesFilePaths, filePath) || (engine.ValuesFilename != "" && path.Base(filePath) == engine.ValuesFilename) { // Exclude values files from static files - they should not be copied to the outputDir
 			logger.Debug("Identified as values file", "path", filePath)
 		} else {
 			staticPaths = append(staticPaths, filePath)
@@ -45,5 +52,15 @@ func (engine *Engine) sortPaths(fileList fileIO.FileList) ([]string, []string, [
 		}
 	}
 
-	return templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths
+	return templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, markdownPagePaths, staticPaths
+}
+
+// isMetaTemplate reports whether filePath is a metatemplate file.
+func (engine *Engine) isMetaTemplate(filePath string) bool {
+	return strings.Contains(filePath, engine.MetaTemplateExtension)
+}
+
+// isComponent reports whether filePath is a component file.
+func (engine *Engine) isComponent(filePath string) bool {
+	return engine.ComponentExtension != "" && strings.Contains(filePath, engine.ComponentExtension)
 }
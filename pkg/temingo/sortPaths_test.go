@@ -22,7 +22,7 @@ func TestSortPathWithAll(t *testing.T) {
 		},
 	}
 
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths := engine.sortPaths(fileList)
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, _, staticPaths := engine.sortPaths(fileList)
 
 	if len(templatePaths) != 1 {
 		t.Fatal("wrong amount of templatePaths returned from sortPaths: expected 1, got", len(templatePaths))
@@ -68,7 +68,7 @@ func TestSortPathWithOnlyTemplate(t *testing.T) {
 		},
 	}
 
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths := engine.sortPaths(fileList)
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, _, staticPaths := engine.sortPaths(fileList)
 
 	if len(templatePaths) != 1 {
 		t.Fatal("wrong amount of templatePaths returned from sortPaths: expected 1, got", len(templatePaths))
@@ -100,7 +100,7 @@ func TestSortPathWithOnlyMetaTemplate(t *testing.T) {
 		},
 	}
 
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths := engine.sortPaths(fileList)
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, _, staticPaths := engine.sortPaths(fileList)
 
 	if len(templatePaths) != 0 {
 		t.Fatal("wrong amount of templatePaths returned from sortPaths: expected 0, got", len(templatePaths))
@@ -132,7 +132,7 @@ func TestSortPathWithOnlyPartials(t *testing.T) {
 		},
 	}
 
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths := engine.sortPaths(fileList)
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, _, staticPaths := engine.sortPaths(fileList)
 
 	if len(templatePaths) != 0 {
 		t.Fatal("wrong amount of templatePaths returned from sortPaths: expected 0, got", len(templatePaths))
@@ -164,7 +164,7 @@ func TestSortPathWithOnlyMeta(t *testing.T) {
 		},
 	}
 
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths := engine.sortPaths(fileList)
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, _, staticPaths := engine.sortPaths(fileList)
 
 	if len(templatePaths) != 0 {
 		t.Fatal("wrong amount of templatePaths returned from sortPaths: expected 0, got", len(templatePaths))
@@ -196,7 +196,7 @@ func TestSortPathWithOnlyMarkdown(t *testing.T) {
 		},
 	}
 
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths := engine.sortPaths(fileList)
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, _, staticPaths := engine.sortPaths(fileList)
 
 	if len(templatePaths) != 0 {
 		t.Fatal("wrong amount of templatePaths returned from sortPaths: expected 0, got", len(templatePaths))
@@ -228,7 +228,7 @@ func TestSortPathWithOnlyStatic(t *testing.T) {
 		},
 	}
 
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths := engine.sortPaths(fileList)
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, _, staticPaths := engine.sortPaths(fileList)
 
 	if len(templatePaths) != 0 {
 		t.Fatal("wrong amount of templatePaths returned from sortPaths: expected 0, got", len(templatePaths))
@@ -258,7 +258,7 @@ func TestSortPathWithEmpty(t *testing.T) {
 		Files: []string{},
 	}
 
-	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, staticPaths := engine.sortPaths(fileList)
+	templatePaths, metaTemplatePaths, partialPaths, metaPaths, markdownContentPaths, _, staticPaths := engine.sortPaths(fileList)
 
 	if len(templatePaths) != 0 {
 		t.Fatal("wrong amount of templatePaths returned from sortPaths: expected 0, got", len(templatePaths))
@@ -275,3 +275,32 @@ func TestSortPathWithEmpty(t *testing.T) {
 	}
 
 }
+
+// Check if standalone markdown files are sorted apart from markdown content files
+func TestSortPathWithMarkdownPage(t *testing.T) {
+
+	engine := DefaultEngine()
+
+	fileList := fileIO.FileList{
+		Files: []string{
+			"src/content.md",
+			"src/docs/intro.md",
+			"src/docs/note.partial.md",
+		},
+	}
+
+	_, _, partialPaths, _, markdownContentPaths, markdownPagePaths, staticPaths := engine.sortPaths(fileList)
+
+	if len(markdownPagePaths) != 1 {
+		t.Fatal("wrong amount of markdownPagePaths returned from sortPaths: expected 1, got", len(markdownPagePaths))
+	} else if markdownPagePaths[0] != "src/docs/intro.md" {
+		t.Fatal("wrong return value of sortPaths markdownPagePaths: expected src/docs/intro.md, got", markdownPagePaths[0])
+	} else if len(markdownContentPaths) != 1 {
+		t.Fatal("wrong amount of markdownContentPaths returned from sortPaths: expected 1, got", len(markdownContentPaths))
+	} else if len(partialPaths) != 1 {
+		t.Fatal("wrong amount of partialPaths returned from sortPaths: expected 1, got", len(partialPaths))
+	} else if len(staticPaths) != 0 {
+		t.Fatal("wrong amount of staticPaths returned from sortPaths: expected 0, got", len(staticPaths))
+	}
+
+}
@@ -0,0 +1,33 @@
+package temingo
+
+import "strings"
+
+// The delimiters that open and close a front matter block, each on a line of
+// its own, for yaml and toml front matter.
+const (
+	yamlFrontMatterDelimiter = "---"
+	tomlFrontMatterDelimiter = "+++"
+)
+
+// splitFrontMatter splits content into the front matter between delimiter lines
+// at its very start and the body after it. lines is the number of lines the front matter takes up,
+// delimiters included, so positions in the body can be mapped back to the file.
+// Content that does not start with a delimiter line has no front matter, and
+// neither has content whose front matter is never closed.
+func splitFrontMatter(content string, delimiter string) (frontMatter string, body string, lines int, ok bool) {
+	contentLines := strings.SplitAfter(content, "\n")
+	if len(contentLines) < 2 || strings.TrimRight(contentLines[0], "\r\n") != delimiter {
+		return "", content, 0, false
+	}
+
+	start := len(contentLines[0])
+	end := start
+	for i, line := range contentLines[1:] {
+		if strings.TrimRight(line, " \t\r\n") == delimiter {
+			return content[start:end], content[end+len(line):], i + 2, true
+		}
+		end += len(line)
+	}
+
+	return "", content, 0, false
+}
@@ -0,0 +1,85 @@
+package temingo
+
+import "testing"
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		delimiter       string
+		wantFrontMatter string
+		wantBody        string
+		wantLines       int
+		wantOK          bool
+	}{
+		{
+			name:            "front matter and body",
+			content:         "---\na: 1\nb: 2\n---\n<p>body</p>\n",
+			wantFrontMatter: "a: 1\nb: 2\n",
+			wantBody:        "<p>body</p>\n",
+			wantLines:       4,
+			wantOK:          true,
+		},
+		{
+			name:            "empty front matter",
+			content:         "---\n---\nbody",
+			wantFrontMatter: "",
+			wantBody:        "body",
+			wantLines:       2,
+			wantOK:          true,
+		},
+		{
+			name:            "windows line endings",
+			content:         "---\r\na: 1\r\n---\r\nbody",
+			wantFrontMatter: "a: 1\r\n",
+			wantBody:        "body",
+			wantLines:       3,
+			wantOK:          true,
+		},
+		{
+			name:            "closing delimiter at the end",
+			content:         "---\na: 1\n---",
+			wantFrontMatter: "a: 1\n",
+			wantBody:        "",
+			wantLines:       3,
+			wantOK:          true,
+		},
+		{
+			name:            "toml front matter",
+			content:         "+++\na = 1\n+++\nbody",
+			delimiter:       tomlFrontMatterDelimiter,
+			wantFrontMatter: "a = 1\n",
+			wantBody:        "body",
+			wantLines:       3,
+			wantOK:          true,
+		},
+		{
+			name:     "other delimiter",
+			content:  "+++\na = 1\n+++\nbody",
+			wantBody: "+++\na = 1\n+++\nbody",
+		},
+		{
+			name:     "no front matter",
+			content:  "<p>---</p>\n---\n",
+			wantBody: "<p>---</p>\n---\n",
+		},
+		{
+			name:     "never closed",
+			content:  "---\na: 1\n<p>body</p>",
+			wantBody: "---\na: 1\n<p>body</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delimiter := tt.delimiter
+			if delimiter == "" {
+				delimiter = yamlFrontMatterDelimiter
+			}
+			frontMatter, body, lines, ok := splitFrontMatter(tt.content, delimiter)
+			if frontMatter != tt.wantFrontMatter || body != tt.wantBody || lines != tt.wantLines || ok != tt.wantOK {
+				t.Errorf("splitFrontMatter(%q) = (%q, %q, %d, %v), want (%q, %q, %d, %v)", tt.content, frontMatter, body, lines, ok, tt.wantFrontMatter, tt.wantBody, tt.wantLines, tt.wantOK)
+			}
+		})
+	}
+}
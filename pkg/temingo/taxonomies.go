@@ -0,0 +1,142 @@
+package temingo
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// Term is one term of a taxonomy, like the tag go, with the pages that have
+// it. Every page gets the terms of every taxonomy as .taxonomies, and the page
+// of a term gets it as .term.
+type Term struct {
+	Name string
+	Slug string // The folder of its page
+	URL  string // Of its page
+	// Pages holds the meta of every page with the term, by the URL of the
+	// page, like .childMeta holds it by folder.
+	Pages map[string]interface{}
+}
+
+// termPage is the page of one term, rendered from the term template of its
+// taxonomy.
+type termPage struct {
+	templatePath string
+	term         *Term
+}
+
+// termTemplatePrefix starts the filename of the template a taxonomy renders
+// the page of each of its terms from, like tags/_term.template.html.
+const termTemplatePrefix = "_term."
+
+// isTermTemplate reports whether filePath is the term template of a taxonomy.
+func (engine *Engine) isTermTemplate(filePath string) bool {
+	return slices.Contains(engine.Taxonomies, path.Dir(filePath)) &&
+		strings.Contains(filePath, engine.TemplateExtension) &&
+		strings.HasPrefix(path.Base(filePath), termTemplatePrefix)
+}
+
+// readTaxonomies collects the terms of every taxonomy from the meta of the
+// html pages the templates, metatemplates and markdown pages render, and the
+// term pages the term templates among templatePaths render for them.
+func (engine *Engine) readTaxonomies(templatePaths []string, metaTemplatePaths []string, metaPaths []string) error {
+	engine.taxonomies = map[string]map[string]*Term{}
+	engine.termPages = map[string]termPage{}
+	for _, taxonomy := range engine.Taxonomies {
+		engine.taxonomies[taxonomy] = map[string]*Term{}
+	}
+	if len(engine.Taxonomies) == 0 {
+		return nil
+	}
+
+	slugs := map[string]string{} // Term names by taxonomy and slug, to tell apart terms that would share a page
+	for _, outputPath := range engine.pageOutputPaths(templatePaths, metaTemplatePaths, metaPaths) {
+		meta, _, err := engine.getMetaForTemplatePath(fileIO.FileList{Files: metaPaths}, outputPath)
+		if err != nil {
+			return err
+		}
+		fields, _ := meta.(map[string]interface{})
+		for _, taxonomy := range engine.Taxonomies {
+			names, err := termNames(fields[taxonomy])
+			if err != nil {
+				return fmt.Errorf("%s of %s: %w", taxonomy, outputPath, err)
+			}
+			for _, name := range names {
+				term, ok := engine.taxonomies[taxonomy][name]
+				if !ok {
+					slug := termSlug(name)
+					if slug == "" {
+						return fmt.Errorf("%s of %s: term %q has no letters or digits to name its page by", taxonomy, outputPath, name)
+					}
+					if other, ok := slugs[taxonomy+"/"+slug]; ok {
+						return fmt.Errorf("%s of %s: terms %q and %q would share the page %s/%s/", taxonomy, outputPath, other, name, taxonomy, slug)
+					}
+					slugs[taxonomy+"/"+slug] = name
+					term = &Term{Name: name, Slug: slug, URL: "/" + taxonomy + "/" + slug + "/", Pages: map[string]interface{}{}}
+					engine.taxonomies[taxonomy][name] = term
+				}
+				term.Pages[pageURL(outputPath, 1)] = meta
+			}
+		}
+	}
+
+	for _, templatePath := range templatePaths {
+		if !engine.isTermTemplate(templatePath) {
+			continue
+		}
+		taxonomy := path.Dir(templatePath)
+		filename := strings.TrimPrefix(strings.ReplaceAll(path.Base(templatePath), engine.TemplateExtension, ""), strings.TrimSuffix(termTemplatePrefix, "."))
+		for _, term := range engine.taxonomies[taxonomy] {
+			engine.termPages[path.Join(taxonomy, term.Slug, "index"+filename)] = termPage{templatePath: templatePath, term: term}
+		}
+	}
+	return nil
+}
+
+// termNames returns the terms a meta value names: a single term, or a list of
+// them.
+func termNames(value interface{}) ([]string, error) {
+	var values []interface{}
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		values = value
+	default:
+		values = []interface{}{value}
+	}
+
+	names := []string{}
+	for _, value := range values {
+		switch value.(type) {
+		case string, int, int64, float64, bool:
+			names = append(names, strings.TrimSpace(fmt.Sprint(value)))
+		default:
+			return nil, fmt.Errorf("must be a term or a list of terms, got %v", value)
+		}
+	}
+	return names, nil
+}
+
+// termSlug returns the folder name of the page of the term name: lowercase
+// letters and digits, with a dash for each run of other characters.
+func termSlug(name string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,121 @@
+package temingo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender_Taxonomies(t *testing.T) {
+	files := map[string]string{
+		"index.template.html":             `{{ range $name, $child := .childMeta }}{{ $name }} {{ end }}`,
+		"blog/first/index.template.html":  "---\ntitle: First\ntags: [Go, web]\n---\n" + `{{ range .meta.tags }}<a href="{{ (index $.taxonomies.tags .).URL }}">{{ . }}</a>{{ end }}`,
+		"blog/second/index.template.html": "---\ntitle: Second\ntags: Go\ncategories: [notes]\n---\n{{ .meta.title }}",
+		"blog/third/content.md":           "---\ntitle: Third\ntags: [Web Dev]\n---\nthird",
+		"blog/index.metatemplate.html":    "{{ .meta.title }}",
+		"tags/index.template.html":        `{{ range $name, $term := .taxonomies.tags }}{{ $name }}={{ $term.URL }}({{ len $term.Pages }}) {{ end }}`,
+		"tags/_term.template.html":        "---\npaginate: 1\nheading: Tagged\n---\n{{ .meta.heading }} {{ .term.Name }}:{{ range .paginator.Items }} {{ .key }}={{ .value.title }}{{ end }} {{ .paginator.Current }}/{{ .paginator.Total }}",
+	}
+	engine, _, outputDir := setupWriteTestEngine(t, files)
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	for outputPath, want := range map[string]string{
+		"index.html":                "", // The term template makes no page of the tags folder
+		"blog/first/index.html":     `<a href="/tags/go/">Go</a><a href="/tags/web/">web</a>`,
+		"tags/index.html":           "Go=/tags/go/(2) Web Dev=/tags/web-dev/(1) web=/tags/web/(1) ",
+		"tags/go/index.html":        "Tagged Go: /blog/first/=First 1/2",
+		"tags/go/page/2/index.html": "Tagged Go: /blog/second/=Second 2/2",
+		"tags/web/index.html":       "Tagged web: /blog/first/=First 1/1",
+		"tags/web-dev/index.html":   "Tagged Web Dev: /blog/third/=Third 1/1",
+	} {
+		if got := readOutput(t, outputDir, outputPath); got != want {
+			t.Errorf("Render() %s = %q, want %q", outputPath, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "categories")); !os.IsNotExist(err) {
+		t.Errorf("Render() rendered categories without a term template (%v)", err)
+	}
+}
+
+func TestRender_TaxonomiesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "terms sharing a page",
+			files: map[string]string{
+				"a/index.template.html": "---\ntags: [Go]\n---\na",
+				"b/index.template.html": "---\ntags: [go]\n---\nb",
+			},
+			wantErr: `terms "Go" and "go" would share the page tags/go/`,
+		},
+		{
+			name:    "term without letters or digits",
+			files:   map[string]string{"index.template.html": "---\ntags: [\"!\"]\n---\nhome"},
+			wantErr: `term "!" has no letters or digits`,
+		},
+		{
+			name:    "map of terms",
+			files:   map[string]string{"index.template.html": "---\ncategories:\n  go: true\n---\nhome"},
+			wantErr: "categories of index.html: must be a term or a list of terms",
+		},
+		{
+			name: "term page that another template renders",
+			files: map[string]string{
+				"index.template.html":         "---\ntags: [go]\n---\nhome",
+				"tags/_term.template.html":    "{{ .term.Name }}",
+				"tags/go/index.template.html": "taken",
+			},
+			wantErr: `tags/go/index.template.html and the page of term "go" of tags/_term.template.html both render to tags/go/index.html`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := setupWriteTestEngine(t, tt.files)
+
+			err := engine.Render()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderChanged_Taxonomies(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"a/index.template.html":    "---\ntags: [go]\n---\na",
+		"b/index.template.html":    "---\ntitle: B\n---\n{{ .meta.title }}",
+		"tags/_term.template.html": `{{ range $url, $page := .term.Pages }}{{ $url }} {{ end }}`,
+	})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	// Tagging a page adds it to the page of the term, and adds new terms
+	writeTestFiles(t, inputDir, map[string]string{"b/index.template.html": "---\ntitle: B\ntags: [go, web]\n---\n{{ .meta.title }}"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "b/index.template.html")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "tags/go/index.html"); got != "/a/ /b/ " {
+		t.Errorf("RenderChanged() tags/go/index.html = %q, want both pages", got)
+	}
+	if got := readOutput(t, outputDir, "tags/web/index.html"); got != "/b/ " {
+		t.Errorf("RenderChanged() tags/web/index.html = %q, want the new term", got)
+	}
+
+	// A change to the term template only re-renders the term pages
+	writeTestFiles(t, inputDir, map[string]string{"tags/_term.template.html": `{{ .term.Name }}`})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "tags/_term.template.html")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "tags/web/index.html"); got != "web" {
+		t.Errorf("RenderChanged() tags/web/index.html = %q, want web", got)
+	}
+}
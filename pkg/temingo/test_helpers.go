@@ -72,7 +72,7 @@ func setupTestProjectFromInitFiles(tmpDir string, projectType string) (string, s
 
 // setupTestProjectFromInitFilesWithEngine sets up a test project and returns an engine configured for it
 // This is useful for tests that need to render the project
-func setupTestProjectFromInitFilesWithEngine(tmpDir string, projectType string, values map[string]string) (*Engine, string, string, error) {
+func setupTestProjectFromInitFilesWithEngine(tmpDir string, projectType string, values map[string]interface{}) (*Engine, string, string, error) {
 	inputDir, outputDir, err := setupTestProjectFromInitFiles(tmpDir, projectType)
 	if err != nil {
 		return nil, "", "", err
@@ -75,7 +75,7 @@ func TestSetupTestProjectFromInitFilesWithEngine(t *testing.T) {
 	// Create a new tmpDir for each test case to ensure isolation
 	tmpDir := t.TempDir()
 
-	values := map[string]string{
+	values := map[string]interface{}{
 		"testKey": "testValue",
 	}
 
@@ -92,7 +92,7 @@ func TestSetupTestProjectFromInitFilesWithEngine(t *testing.T) {
 		t.Errorf("engine.OutputDir = %q, want %q", engine.OutputDir, outputDir+string(filepath.Separator))
 	}
 	if engine.Values["testKey"] != "testValue" {
-		t.Errorf("engine.Values[\"testKey\"] = %q, want %q", engine.Values["testKey"], "testValue")
+		t.Errorf("engine.Values[\"testKey\"] = %v, want %q", engine.Values["testKey"], "testValue")
 	}
 
 	// Verify directories exist
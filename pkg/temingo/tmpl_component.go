@@ -0,0 +1,117 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// maxCallDepth is how deep component and capture calls may nest, so a
+// component that calls itself fails instead of overflowing the stack.
+const maxCallDepth = 100
+
+// unboundTemplateFunc stands in for component, capture, image and paginate in
+// templateFuncMap, where templates are only parsed. renderTemplate replaces
+// them with the functions componentFuncs, imageFuncs and paginateFuncs bind to
+// the template set and the page being rendered.
+func unboundTemplateFunc(name string, args ...interface{}) (string, error) {
+	return "", errors.New("only available while rendering")
+}
+
+// componentFuncs returns the component and capture template functions, which
+// render other templates of templateEngine.
+//
+// component renders a component with the key-value pairs it is passed, checked
+// against the parameters and slots it declares, as its only data:
+//
+//	{{ component "card" "title" .title "href" .url }}
+//
+// capture renders any named template - a partial, or a {{ define }} in the
+// calling template - to a string, which is how a slot is filled:
+//
+//	{{ component "card" "title" .title "slot" (capture "card-body" .) }}
+func (engine *Engine) componentFuncs(templateEngine *template.Template) template.FuncMap {
+	depth := 0
+
+	execute := func(name string, data interface{}) (string, error) {
+		if depth >= maxCallDepth {
+			return "", fmt.Errorf("calls nest more than %d levels deep; does %s call itself?", maxCallDepth, name)
+		}
+		depth++
+		defer func() { depth-- }()
+
+		var output bytes.Buffer
+		if err := templateEngine.ExecuteTemplate(&output, name, data); err != nil {
+			return "", err
+		}
+		return output.String(), nil
+	}
+
+	return template.FuncMap{
+		"component": func(name string, pairs ...interface{}) (string, error) {
+			comp, ok := engine.components[name]
+			if !ok {
+				names := make([]string, 0, len(engine.components))
+				for known := range engine.components {
+					names = append(names, known)
+				}
+				sort.Strings(names)
+				if len(names) == 0 {
+					return "", fmt.Errorf("no component named %q; there are no %s files", name, engine.ComponentExtension)
+				}
+				return "", fmt.Errorf("no component named %q; known components are %s", name, strings.Join(names, ", "))
+			}
+
+			data, err := comp.arguments(pairs)
+			if err != nil {
+				return "", err
+			}
+
+			output, err := execute(comp.path, data)
+			if err != nil {
+				sources := map[string]templateSource{comp.path: {path: comp.path, lineOffset: partialLineOffset}}
+				return "", engine.locateTemplateError(err, sources, "rendering component "+name)
+			}
+			return output, nil
+		},
+		"capture": func(name string, data ...interface{}) (string, error) {
+			if len(data) > 1 {
+				return "", fmt.Errorf("capture takes a template name and at most one data argument, but got %d arguments", len(data)+1)
+			}
+			if templateEngine.Lookup(name) == nil {
+				return "", fmt.Errorf("no template named %q", name)
+			}
+			var dot interface{}
+			if len(data) == 1 {
+				dot = data[0]
+			}
+			return execute(name, dot)
+		},
+	}
+}
+
+// componentCallRe matches the name of a component call with a literal name.
+var componentCallRe = regexp.MustCompile(`\bcomponent\s+"([^"]+)"`)
+
+// calledPartials returns the paths of the partials and components content
+// calls, with {{ template }}, capture or component. A name that is no partial
+// path is returned as it is; it may be defined inside some partial file with an
+// explicit {{ define }}, or in content itself.
+func (engine *Engine) calledPartials(content string) []string {
+	var called []string
+	for _, match := range templateCallRe.FindAllStringSubmatch(content, -1) {
+		called = append(called, match[1])
+	}
+	for _, match := range componentCallRe.FindAllStringSubmatch(content, -1) {
+		if comp, ok := engine.components[match[1]]; ok {
+			called = append(called, comp.path)
+		}
+	}
+	slices.Sort(called)
+	return slices.Compact(called)
+}
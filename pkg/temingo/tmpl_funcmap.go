@@ -3,7 +3,8 @@ package temingo
 import "text/template"
 
 // templateFuncMap returns the functions available to templates. It takes the
-// engine because some functions - sri - need engine-scoped state.
+// engine because some functions - sri, pagesIn and pageAt - need engine-scoped
+// state.
 func templateFuncMap(engine *Engine) template.FuncMap {
 	return template.FuncMap{
 		"concat":                 tmpl_concat,
@@ -13,5 +14,11 @@ func templateFuncMap(engine *Engine) template.FuncMap {
 		"sortBy":                 tmpl_sortBy,
 		"filterBy":               tmpl_filterBy,
 		"sri":                    engine.tmplSRI,
+		"pagesIn":                engine.tmplPagesIn,
+		"pageAt":                 engine.tmplPageAt,
+		"component":              unboundTemplateFunc,
+		"capture":                unboundTemplateFunc,
+		"image":                  unboundTemplateFunc,
+		"paginate":               unboundTemplateFunc,
 	}
 }
@@ -0,0 +1,183 @@
+package temingo
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// imageFuncs returns the image template function, bound to the page at
+// pagePath, which relative image paths and the URLs it emits are relative to.
+//
+// image emits a <picture> for a jpeg or png image, with a WebP source and a
+// fallback <img> in the format of the original, each resized to the widths
+// given, or to the configured ones without:
+//
+//	{{ image "hero.jpg" "800w,1600w" "alt" "A hero" "sizes" "100vw" }}
+//
+// The key-value pairs after the widths become attributes of the <img>;
+// sizes goes on the <source> as well. The variants are generated with the rest
+// of the generated files.
+func (engine *Engine) imageFuncs(pagePath string) template.FuncMap {
+	return template.FuncMap{
+		"image": func(src string, args ...interface{}) (string, error) {
+			widths := engine.Images.Widths
+			if len(args)%2 == 1 { // Widths come before the pairs
+				widthList, ok := args[0].(string)
+				if !ok {
+					return "", fmt.Errorf("image %q: widths must be a string like \"800w,1600w\", got %T", src, args[0])
+				}
+				var err error
+				if widths, err = parseImageWidths(widthList); err != nil {
+					return "", fmt.Errorf("image %q: %w", src, err)
+				}
+				args = args[1:]
+			}
+			attributes := [][2]string{}
+			for i := 0; i < len(args); i += 2 {
+				key, ok := args[i].(string)
+				if !ok || !attributeNameRe.MatchString(key) {
+					return "", fmt.Errorf("image %q: invalid attribute name %v", src, args[i])
+				}
+				if slices.Contains([]string{"src", "srcset", "width", "height"}, key) {
+					return "", fmt.Errorf("image %q: the %s attribute is set from the variants", src, key)
+				}
+				attributes = append(attributes, [2]string{key, fmt.Sprint(args[i+1])})
+			}
+
+			sourcePath := path.Join(path.Dir(pagePath), src)
+			if strings.HasPrefix(src, "/") {
+				sourcePath = path.Clean(strings.TrimPrefix(src, "/"))
+			}
+			if sourcePath == ".." || strings.HasPrefix(sourcePath, "../") {
+				return "", fmt.Errorf("image %q: resolves to %s, outside the input directory", src, sourcePath)
+			}
+
+			variants, err := engine.addImageVariants(pagePath, sourcePath, widths)
+			if err != nil {
+				return "", fmt.Errorf("image %q: %w", src, err)
+			}
+			engine.imageReferences[pagePath] = append(engine.imageReferences[pagePath], sourcePath)
+
+			return imageHTML(variants, path.Dir(pagePath), attributes), nil
+		},
+	}
+}
+
+// attributeNameRe matches the html attribute names image accepts.
+var attributeNameRe = regexp.MustCompile(`^[a-zA-Z_:][-a-zA-Z0-9_:.]*$`)
+
+// parseImageWidths parses a comma separated list of widths, each with an
+// optional w suffix, like "800w,1600w".
+func parseImageWidths(widthList string) ([]int, error) {
+	widths := []int{}
+	for _, field := range strings.Split(widthList, ",") {
+		field = strings.TrimSuffix(strings.TrimSpace(field), "w")
+		width, err := strconv.Atoi(field)
+		if err != nil || width < 1 {
+			return nil, fmt.Errorf("invalid width %q in %q", field, widthList)
+		}
+		widths = append(widths, width)
+	}
+	return widths, nil
+}
+
+// addImageVariants records the variants of the image at sourcePath at widths,
+// in WebP and in the format of the image, as asked for by the page at pagePath,
+// and returns them by ascending width. Widths beyond the width of the image are
+// generated at its width instead, so no image is scaled up.
+func (engine *Engine) addImageVariants(pagePath string, sourcePath string, widths []int) ([]imageVariant, error) {
+	file, err := os.Open(engine.inputFilePath(sourcePath))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	config, format, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", sourcePath, err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, fmt.Errorf("%s is a %s image, but only jpeg and png images are supported", sourcePath, format)
+	}
+
+	if len(widths) == 0 {
+		widths = []int{config.Width}
+	}
+	clamped := []int{}
+	for _, width := range widths {
+		clamped = append(clamped, min(width, config.Width))
+	}
+	slices.Sort(clamped)
+	clamped = slices.Compact(clamped)
+
+	ext := path.Ext(sourcePath)
+	base := strings.TrimSuffix(sourcePath, ext)
+	variants := []imageVariant{}
+	for _, width := range clamped {
+		height := max((config.Height*width+config.Width/2)/config.Width, 1)
+		for _, variant := range []imageVariant{
+			{outputPath: fmt.Sprintf("%s-%dw.webp", base, width), format: "webp"},
+			{outputPath: fmt.Sprintf("%s-%dw%s", base, width, ext), format: format},
+		} {
+			variant.sourcePath, variant.width, variant.height = sourcePath, width, height
+			variants = append(variants, variant)
+		}
+	}
+	engine.imageVariants[pagePath] = append(engine.imageVariants[pagePath], variants...)
+	return variants, nil
+}
+
+// imageHTML returns the <picture> element for variants, with URLs relative to
+// pageFolder.
+func imageHTML(variants []imageVariant, pageFolder string, attributes [][2]string) string {
+	var webpSrcset, fallbackSrcset []string
+	var (
+		largest    imageVariant
+		largestURL string
+	)
+	for _, variant := range variants {
+		url := "/" + variant.outputPath
+		if rel, err := filepath.Rel(pageFolder, variant.outputPath); err == nil { // Both are relative to the input directory, so this cannot fail
+			url = filepath.ToSlash(rel)
+		}
+		candidate := fmt.Sprintf("%s %dw", url, variant.width)
+		if variant.format == "webp" {
+			webpSrcset = append(webpSrcset, candidate)
+		} else {
+			fallbackSrcset = append(fallbackSrcset, candidate)
+			largest, largestURL = variant, url
+		}
+	}
+
+	var sizes string
+	hasAlt := false
+	for _, attribute := range attributes {
+		switch attribute[0] {
+		case "sizes":
+			sizes = fmt.Sprintf(" sizes=\"%s\"", html.EscapeString(attribute[1]))
+		case "alt":
+			hasAlt = true
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("<picture>")
+	fmt.Fprintf(&builder, "<source type=\"image/webp\" srcset=\"%s\"%s>", html.EscapeString(strings.Join(webpSrcset, ", ")), sizes)
+	fmt.Fprintf(&builder, "<img src=\"%s\" srcset=\"%s\" width=\"%d\" height=\"%d\"", html.EscapeString(largestURL), html.EscapeString(strings.Join(fallbackSrcset, ", ")), largest.width, largest.height)
+	if !hasAlt { // An image without alt text is decorative
+		builder.WriteString(" alt=\"\"")
+	}
+	for _, attribute := range attributes {
+		fmt.Fprintf(&builder, " %s=\"%s\"", attribute[0], html.EscapeString(attribute[1]))
+	}
+	builder.WriteString("></picture>")
+	return builder.String()
+}
@@ -0,0 +1,265 @@
+package temingo
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// encodeTestImage returns a w x h gradient, encoded as jpeg or png.
+func encodeTestImage(t *testing.T, format string, w, h int) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRender_Image(t *testing.T) {
+	files := map[string]string{
+		"img/hero.jpg":             encodeTestImage(t, "jpeg", 200, 100),
+		"blog/logo.png":            encodeTestImage(t, "png", 40, 40),
+		"blog/index.template.html": `{{ image "../img/hero.jpg" "50w,100w,400w" "alt" "A \"hero\"" "sizes" "50vw" }}`,
+		"blog/logo.template.html":  `{{ image "logo.png" "class" "logo" }}`,
+		"absolute.template.html":   `{{ image "/img/hero.jpg" "100" }}`,
+	}
+
+	engine, _, outputDir := setupWriteTestEngine(t, files)
+	engine.Images.Widths = []int{20}
+	engine.CacheDir = filepath.Join(t.TempDir(), "cache")
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	want := `<picture><source type="image/webp" srcset="../img/hero-50w.webp 50w, ../img/hero-100w.webp 100w, ../img/hero-200w.webp 200w" sizes="50vw">` +
+		`<img src="../img/hero-200w.jpg" srcset="../img/hero-50w.jpg 50w, ../img/hero-100w.jpg 100w, ../img/hero-200w.jpg 200w" width="200" height="100" alt="A &#34;hero&#34;" sizes="50vw"></picture>`
+	if got := readOutput(t, outputDir, "blog/index.html"); got != want {
+		t.Errorf("Render() blog/index.html =\n%s\nwant\n%s", got, want)
+	}
+	want = `<picture><source type="image/webp" srcset="logo-20w.webp 20w"><img src="logo-20w.png" srcset="logo-20w.png 20w" width="20" height="20" alt="" class="logo"></picture>`
+	if got := readOutput(t, outputDir, "blog/logo.html"); got != want {
+		t.Errorf("Render() blog/logo.html =\n%s\nwant\n%s", got, want)
+	}
+	if got := readOutput(t, outputDir, "absolute.html"); !strings.Contains(got, `srcset="img/hero-100w.webp 100w"`) {
+		t.Errorf("Render() absolute.html = %s, want the variant relative to the page", got)
+	}
+
+	// The variants decode at their sizes, next to the original
+	for outputPath, wantSize := range map[string]image.Point{
+		"img/hero-50w.webp":  {50, 25},
+		"img/hero-200w.webp": {200, 100},
+		"img/hero-100w.jpg":  {100, 50},
+		"blog/logo-20w.png":  {20, 20},
+		"blog/logo-20w.webp": {20, 20},
+	} {
+		content := readOutput(t, outputDir, outputPath)
+		var config image.Config
+		var err error
+		if strings.HasSuffix(outputPath, ".webp") {
+			config, err = webp.DecodeConfig(strings.NewReader(content))
+		} else {
+			config, _, err = image.DecodeConfig(strings.NewReader(content))
+		}
+		if err != nil {
+			t.Errorf("decoding %s: %v", outputPath, err)
+			continue
+		}
+		if got := (image.Point{config.Width, config.Height}); got != wantSize {
+			t.Errorf("%s is %v, want %v", outputPath, got, wantSize)
+		}
+	}
+	if readOutput(t, outputDir, "img/hero.jpg") != files["img/hero.jpg"] {
+		t.Errorf("Render() did not copy the original image")
+	}
+
+	// A later build takes the variants from the cache, keyed by the source,
+	// where the 100w variants of hero.jpg are one pair
+	cached, err := os.ReadDir(filepath.Join(engine.CacheDir, "images"))
+	if err != nil || len(cached) != 8 {
+		t.Fatalf("image cache holds %d files (%v), want 8", len(cached), err)
+	}
+	marker := []byte("cached")
+	for _, entry := range cached {
+		if err := os.WriteFile(filepath.Join(engine.CacheDir, "images", entry.Name()), marker, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fresh := DefaultEngine()
+	fresh.InputDir, fresh.OutputDir, fresh.TemingoignorePath = engine.InputDir, engine.OutputDir, engine.TemingoignorePath
+	fresh.NoRemoteChecks, fresh.Images, fresh.CacheDir = true, engine.Images, engine.CacheDir
+	if err := fresh.Render(); err != nil {
+		t.Fatalf("second Render() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "img/hero-50w.webp"); got != "cached" {
+		t.Errorf("second Render() img/hero-50w.webp = %q, want the cached variant", got)
+	}
+}
+
+func TestRender_ImageErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing image",
+			files:   map[string]string{"index.template.html": `{{ image "hero.jpg" }}`},
+			wantErr: `image "hero.jpg"`,
+		},
+		{
+			name: "not an image",
+			files: map[string]string{
+				"hero.jpg":            "text",
+				"index.template.html": `{{ image "hero.jpg" }}`,
+			},
+			wantErr: "reading hero.jpg",
+		},
+		{
+			name: "invalid width",
+			files: map[string]string{
+				"hero.jpg":            encodeTestImage(t, "jpeg", 10, 10),
+				"index.template.html": `{{ image "hero.jpg" "wide" }}`,
+			},
+			wantErr: `invalid width "wide"`,
+		},
+		{
+			name: "attribute set from the variants",
+			files: map[string]string{
+				"hero.jpg":            encodeTestImage(t, "jpeg", 10, 10),
+				"index.template.html": `{{ image "hero.jpg" "width" "5" }}`,
+			},
+			wantErr: "the width attribute is set from the variants",
+		},
+		{
+			name: "outside the input directory",
+			files: map[string]string{
+				"index.template.html": `{{ image "../hero.jpg" }}`,
+			},
+			wantErr: "outside the input directory",
+		},
+		{
+			name: "variant that exists in the input",
+			files: map[string]string{
+				"hero.jpg":            encodeTestImage(t, "jpeg", 10, 10),
+				"hero-10w.webp":       "hand-made",
+				"index.template.html": `{{ image "hero.jpg" "10w" }}`,
+			},
+			wantErr: "hero-10w.webp is generated, but the input directory has one as well",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := setupWriteTestEngine(t, tt.files)
+
+			err := engine.Render()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender_ImagePrunesCache(t *testing.T) {
+	engine, inputDir, _ := setupWriteTestEngine(t, map[string]string{
+		"hero.png":            encodeTestImage(t, "png", 40, 20),
+		"index.template.html": `{{ image "hero.png" "10w,20w" }}`,
+	})
+	engine.CacheDir = filepath.Join(t.TempDir(), "cache")
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	writeTestFiles(t, inputDir, map[string]string{"index.template.html": `{{ image "hero.png" "10w" }}`})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("second Render() unexpected error: %v", err)
+	}
+
+	cached, err := os.ReadDir(filepath.Join(engine.CacheDir, "images"))
+	if err != nil || len(cached) != 2 {
+		t.Errorf("image cache holds %d files (%v), want the 2 variants at 10w", len(cached), err)
+	}
+	if len(engine.imageCache) != 2 {
+		t.Errorf("image cache holds %d variants in memory, want the 2 at 10w", len(engine.imageCache))
+	}
+}
+
+func TestRenderChanged_Image(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"hero.png":            encodeTestImage(t, "png", 40, 20),
+		"index.template.html": `{{ image "hero.png" "30w" }}`,
+	})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if deps := engine.lastBuild.outputs["index.html"].dependencies; !slices.Contains(deps, "hero.png") {
+		t.Fatalf("dependencies of index.html = %v, want hero.png among them", deps)
+	}
+
+	changed := filepath.Join(inputDir, "hero.png")
+	writeTestFiles(t, inputDir, map[string]string{"hero.png": encodeTestImage(t, "png", 20, 20)})
+	if err := engine.RenderChanged(changed); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "index.html"); !strings.Contains(got, `srcset="hero-20w.png 20w" width="20" height="20"`) {
+		t.Errorf("RenderChanged() index.html = %s, want the variants of the smaller image", got)
+	}
+}
+
+func TestRenderChanged_ImageVariantsFollowTheOutputs(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"hero.png":                  encodeTestImage(t, "png", 80, 40),
+		"index.template.html":       `{{ image "hero.png" "50w" }}`,
+		"about/index.template.html": "about",
+	})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	markOutputs(t, outputDir, "about/index.html")
+
+	for _, step := range []struct {
+		template string
+		want     []string
+	}{
+		{`{{ image "hero.png" "30w" }}`, []string{"hero-30w.png", "hero-30w.webp"}},
+		{"no image", nil},
+	} {
+		writeTestFiles(t, inputDir, map[string]string{"index.template.html": step.template})
+		if err := engine.RenderChanged(filepath.Join(inputDir, "index.template.html")); err != nil {
+			t.Fatalf("RenderChanged() unexpected error: %v", err)
+		}
+
+		for _, variantPath := range []string{"hero-50w.png", "hero-50w.webp", "hero-30w.png", "hero-30w.webp"} {
+			_, err := os.Stat(filepath.Join(outputDir, variantPath))
+			if want := slices.Contains(step.want, variantPath); want != (err == nil) {
+				t.Errorf("RenderChanged() to %q: %s exists = %v, want %v", step.template, variantPath, err == nil, want)
+			}
+		}
+	}
+	if got := readOutput(t, outputDir, "about/index.html"); got != "untouched" {
+		t.Errorf("RenderChanged() rewrote about/index.html, want incremental rebuilds")
+	}
+}
@@ -0,0 +1,33 @@
+package temingo
+
+import (
+	"errors"
+	"text/template"
+)
+
+// paginateFuncs returns the paginate template function, bound to the
+// .paginator of meta.
+//
+// paginate lists the items of a paginated page from any list or map, instead
+// of from .childMeta, so the listing can be filtered and sorted first:
+//
+//	{{ range (paginate (reverse (sortBy "date" (filterBy "publish" true .childMeta)))).Items }}
+//
+// It replaces .paginator with the page of that list, and the number of pages
+// rendered follows it.
+func (engine *Engine) paginateFuncs(meta map[string]interface{}) template.FuncMap {
+	return template.FuncMap{
+		"paginate": func(items interface{}) (*Paginator, error) {
+			current, ok := meta["paginator"].(*Paginator)
+			if !ok {
+				return nil, errors.New("paginate needs a page size; declare one with paginate: <size> in the front matter or meta of the page")
+			}
+			p, err := newPaginator(current.outputPath, current.Current, current.Size, items)
+			if err != nil {
+				return nil, err
+			}
+			*current = *p // Later uses of .paginator, and the page count, see this listing
+			return current, nil
+		},
+	}
+}
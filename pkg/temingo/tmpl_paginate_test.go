@@ -0,0 +1,160 @@
+package temingo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// paginatedBlog returns a blog whose index lists five posts, two per page,
+// with list as the template of each page.
+func paginatedBlog(list string) map[string]string {
+	files := map[string]string{
+		"blog/index.template.html": "---\npaginate: 2\n---\n" + list,
+	}
+	for i, title := range []string{"A", "B", "C", "D", "E"} {
+		files["blog/"+strings.ToLower(title)+"/index.template.html"] = "---\ntitle: " + title + "\ndate: 2024-01-0" + string(rune('1'+i)) + "\n---\n" + title
+	}
+	return files
+}
+
+func TestRender_Paginate(t *testing.T) {
+	tests := []struct {
+		name  string
+		list  string
+		files map[string]string // On top of the blog
+		want  map[string]string // By output path
+	}{
+		{
+			name: "childMeta by key",
+			list: `{{ range .paginator.Items }}{{ .value.title }}{{ end }} {{ .paginator.Current }}/{{ .paginator.Total }} [{{ .paginator.Prev }}|{{ .paginator.Next }}]`,
+			want: map[string]string{
+				"blog/index.html":        "AB 1/3 [|/blog/page/2/]",
+				"blog/page/2/index.html": "CD 2/3 [/blog/|/blog/page/3/]",
+				"blog/page/3/index.html": "E 3/3 [/blog/page/2/|]",
+			},
+		},
+		{
+			name: "sorted and reversed",
+			list: `{{ range (paginate (reverse (sortBy "date" .childMeta))).Items }}{{ .value.title }}{{ end }}`,
+			want: map[string]string{
+				"blog/index.html":        "ED",
+				"blog/page/2/index.html": "CB",
+				"blog/page/3/index.html": "A",
+			},
+		},
+		{
+			name: "filtered, with fewer pages",
+			list: `{{ $p := paginate (filterBy "title" "B" .childMeta) }}{{ range $p.Items }}{{ .value.title }}{{ end }} {{ $p.Total }} {{ range $p.Pages }}{{ .Number }}={{ .URL }} {{ end }}`,
+			want: map[string]string{
+				"blog/index.html": "B 1 1=/blog/ ",
+			},
+		},
+		{
+			name:  "page size from meta yaml",
+			list:  `{{ .paginator.Current }}`,
+			files: map[string]string{"meta.yaml": "paginate: 4\n"},
+			want: map[string]string{
+				"blog/index.html":        "1",
+				"blog/page/2/index.html": "2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := paginatedBlog(tt.list)
+			for filePath, content := range tt.files {
+				files[filePath] = content
+			}
+			if tt.files != nil { // The meta yaml declares the page size instead
+				files["blog/index.template.html"] = tt.list
+			}
+			engine, _, outputDir := setupWriteTestEngine(t, files)
+
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+			for outputPath, want := range tt.want {
+				if got := readOutput(t, outputDir, outputPath); got != want {
+					t.Errorf("Render() %s = %q, want %q", outputPath, got, want)
+				}
+			}
+			pageCount := 0
+			entries, _ := os.ReadDir(filepath.Join(outputDir, "blog", "page"))
+			for range entries {
+				pageCount++
+			}
+			if pageCount != len(tt.want)-1 {
+				t.Errorf("Render() made %d later pages, want %d", pageCount, len(tt.want)-1)
+			}
+		})
+	}
+}
+
+func TestRender_PaginateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "no page size",
+			files:   map[string]string{"index.template.html": `{{ paginate .childMeta }}`},
+			wantErr: "paginate needs a page size",
+		},
+		{
+			name:    "invalid page size",
+			files:   map[string]string{"index.template.html": "---\npaginate: none\n---\n{{ .paginator.Current }}"},
+			wantErr: "paginate must be a positive number of items per page",
+		},
+		{
+			name: "later page that another template renders",
+			files: map[string]string{
+				"index.template.html":        "---\npaginate: 1\n---\n{{ .paginator.Current }}",
+				"a/index.template.html":      "---\ntitle: A\n---\na",
+				"b/index.template.html":      "---\ntitle: B\n---\nb",
+				"page/2/index.template.html": "taken",
+			},
+			wantErr: "both render to page/2/index.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, _, _ := setupWriteTestEngine(t, tt.files)
+
+			err := engine.Render()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderChanged_Paginate(t *testing.T) {
+	files := paginatedBlog(`{{ range .paginator.Items }}{{ .value.title }}{{ end }}`)
+	engine, inputDir, outputDir := setupWriteTestEngine(t, files)
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	// A changed title re-renders the page it is listed on
+	writeTestFiles(t, inputDir, map[string]string{"blog/c/index.template.html": "---\ntitle: Z\ndate: 2024-01-03\n---\nC"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "blog/c/index.template.html")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := readOutput(t, outputDir, "blog/page/2/index.html"); got != "ZD" {
+		t.Errorf("RenderChanged() blog/page/2/index.html = %q, want ZD", got)
+	}
+
+	// A removed post takes the last page with it
+	os.RemoveAll(filepath.Join(inputDir, "blog", "e"))
+	if err := engine.RenderChanged(filepath.Join(inputDir, "blog/e/index.template.html")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "blog/page/3/index.html")); !os.IsNotExist(err) {
+		t.Errorf("RenderChanged() kept blog/page/3/index.html, want it removed (%v)", err)
+	}
+}
@@ -0,0 +1,50 @@
+package temingo
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// tmplPagesIn returns the meta of every page in folder and the folders below
+// it, except its own index page, by the URL of the page - like .childMeta, but
+// across the whole tree, so it composes with sortBy, filterBy and paginate.
+// An empty folder or "/" lists every page of the site.
+// Usage: {{ range reverse (sortBy "date" (pagesIn "blog")) }}{{ .value.title }}{{ end }}
+func (engine *Engine) tmplPagesIn(folder string) map[string]interface{} {
+	folder = strings.Trim(folder, "/")
+	prefix := ""
+	if folder != "" {
+		prefix = folder + "/"
+	}
+
+	pages := map[string]interface{}{}
+	if engine.site == nil {
+		return pages
+	}
+	for url, page := range engine.site.Pages {
+		if strings.HasPrefix(page.Path, prefix) && page.Path != path.Join(folder, "index.html") {
+			pages[url] = page.Meta
+		}
+	}
+	return pages
+}
+
+// tmplPageAt returns the page in the site index at the site-absolute URL,
+// given with or without its index.html.
+// Usage: {{ with pageAt "/about/" }}<a href="{{ .URL }}">{{ .Meta.title }}</a>{{ end }}
+func (engine *Engine) tmplPageAt(url string) (*Page, error) {
+	url = "/" + strings.TrimPrefix(url, "/")
+	if path.Base(url) == "index.html" {
+		url = strings.TrimSuffix(url, "index.html")
+	} else if path.Ext(url) == "" && !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+
+	if engine.site != nil {
+		if page, ok := engine.site.Pages[url]; ok {
+			return page, nil
+		}
+	}
+	return nil, fmt.Errorf("pageAt: no page renders at %s", url)
+}
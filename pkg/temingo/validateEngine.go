@@ -1,6 +1,12 @@
 package temingo
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
 
 func (engine *Engine) validateEngine() error {
 	if engine.Beautify && engine.Minify {
@@ -15,5 +21,55 @@ func (engine *Engine) validateEngine() error {
 	if engine.MetaTemplateExtension == engine.PartialExtension {
 		return fmt.Errorf("metaTemplateExtension and partialExtension must be different: %q", engine.MetaTemplateExtension)
 	}
+	if engine.ComponentExtension != "" && slices.Contains([]string{engine.TemplateExtension, engine.MetaTemplateExtension, engine.PartialExtension}, engine.ComponentExtension) {
+		return fmt.Errorf("componentExtension must differ from templateExtension, metaTemplateExtension and partialExtension: %q", engine.ComponentExtension)
+	}
+	if engine.ValuesFilename != "" && (engine.ValuesFilename == engine.MetaFilename || engine.ValuesFilename == engine.MarkdownContentFilename) {
+		return fmt.Errorf("valuesFilename must differ from metaFilename and markdownFilename: %q", engine.ValuesFilename)
+	}
+	if engine.CacheDir != "" && engine.OutputDir != "" {
+		cacheDir, err := filepath.Abs(engine.cacheDir())
+		if err != nil {
+			return fmt.Errorf("resolving cacheDir %q: %w", engine.CacheDir, err)
+		}
+		outputDir, err := filepath.Abs(engine.OutputDir)
+		if err != nil {
+			return fmt.Errorf("resolving outputDir %q: %w", engine.OutputDir, err)
+		}
+		if rel, err := filepath.Rel(outputDir, cacheDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("cacheDir %q cannot be inside the outputDir %q, which is cleared of files temingo did not write", engine.CacheDir, engine.OutputDir)
+		}
+	}
+	if engine.BaseURL != "" {
+		baseURL, err := url.Parse(engine.BaseURL)
+		if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+			return fmt.Errorf("baseURL must be an absolute http or https URL: %q", engine.BaseURL)
+		}
+	}
+	if engine.Sitemap && engine.BaseURL == "" {
+		return fmt.Errorf("sitemap requires a baseURL, since sitemaps list absolute URLs")
+	}
+	if err := engine.Markdown.Validate(); err != nil {
+		return fmt.Errorf("markdown: %w", err)
+	}
+	if engine.Markdown.HighlightStyle != "" && engine.HighlightStylesheet == "" {
+		return fmt.Errorf("markdown highlighting requires a highlightStylesheet path to write the classes' css to")
+	}
+	if engine.Images.Quality < 0 || engine.Images.Quality > 100 {
+		return fmt.Errorf("images: quality must be between 0 and 100, got %d", engine.Images.Quality)
+	}
+	for _, width := range engine.Images.Widths {
+		if width < 1 {
+			return fmt.Errorf("images: widths must be positive, got %d", width)
+		}
+	}
+	for i, taxonomy := range engine.Taxonomies {
+		if taxonomy == "" || strings.ContainsAny(taxonomy, "/"+invalidFolderChars) {
+			return fmt.Errorf("taxonomies: %q cannot name a folder", taxonomy)
+		}
+		if slices.Contains(engine.Taxonomies[:i], taxonomy) {
+			return fmt.Errorf("taxonomies: %q is listed twice", taxonomy)
+		}
+	}
 	return nil
 }
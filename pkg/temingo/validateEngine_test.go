@@ -53,6 +53,74 @@ func TestValidateEngine(t *testing.T) {
 			}(),
 			wantErr: true,
 		},
+		{
+			name: "values filename equal to meta filename",
+			engine: func() Engine {
+				e := DefaultEngine()
+				e.ValuesFilename = e.MetaFilename
+				return e
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "values file discovery disabled",
+			engine: func() Engine {
+				e := DefaultEngine()
+				e.ValuesFilename = ""
+				return e
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "sitemap without base url",
+			engine: func() Engine {
+				e := DefaultEngine()
+				e.Sitemap = true
+				return e
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "relative base url",
+			engine: func() Engine {
+				e := DefaultEngine()
+				e.BaseURL = "/docs/"
+				return e
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "sitemap with base url",
+			engine: func() Engine {
+				e := DefaultEngine()
+				e.Sitemap = true
+				e.BaseURL = "https://example.com/docs/"
+				return e
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "cacheDir inside the outputDir",
+			engine: func() Engine {
+				e := DefaultEngine()
+				e.InputDir = "site/src/"
+				e.OutputDir = "site/output/"
+				e.CacheDir = "output/.cache/"
+				return e
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "cacheDir next to the outputDir",
+			engine: func() Engine {
+				e := DefaultEngine()
+				e.InputDir = "site/src/"
+				e.OutputDir = "site/output/"
+				e.CacheDir = ".temingo-cache/"
+				return e
+			}(),
+			wantErr: false,
+		},
 		{
 			name: "all extensions distinct",
 			engine: func() Engine {
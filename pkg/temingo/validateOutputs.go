@@ -0,0 +1,71 @@
+package temingo
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/thetillhoff/temingo/internal/refcheck"
+	"github.com/thetillhoff/temingo/internal/validate"
+)
+
+// validateOutputs checks every rendered output and static file of a type the
+// validator knows for syntax and structure errors, and reports what it finds.
+//
+// It is meant to run on the rendered content before it is beautified, since
+// the beautifier parses html into a document and would repair the very markup
+// errors this is looking for. A finding in a rendered output names the
+// template it was rendered from, and its position in the output as `output`;
+// a static file is its own source. Under Strict, any finding is returned as an
+// error so the process exits non-zero.
+func (engine *Engine) validateOutputs(rendered map[string][]byte, staticPaths []string, outputs map[string]renderedOutput) error {
+	if !engine.Validate {
+		return nil
+	}
+
+	var findings []refcheck.Finding
+	for p, content := range rendered {
+		findings = append(findings, validate.File(p, content)...)
+	}
+	for _, p := range staticPaths {
+		if !slices.Contains(validate.Extensions, path.Ext(p)) {
+			continue
+		}
+		content, err := os.ReadFile(path.Join(engine.InputDir, p))
+		if err != nil {
+			engine.Logger.Debug("Skipping unreadable static file during validation", "path", p, "error", err)
+			continue
+		}
+		findings = append(findings, validate.File(p, content)...)
+	}
+
+	findings = engine.Allow.Filter(findings)
+	refcheck.SortFindings(findings)
+
+	for _, f := range findings {
+		outputPosition := Position{File: f.Ref.File, Line: f.Ref.Line, Col: f.Ref.Col}
+		var args []any
+		if output, ok := outputs[f.Ref.File]; ok {
+			args = append(args, "file", path.Join(engine.InputDir, output.sourcePath), "output", outputPosition.String())
+		} else if _, ok := rendered[f.Ref.File]; ok { // Rendered, but from unknown sources
+			outputPosition.File = path.Join(engine.OutputDir, f.Ref.File)
+			args = append(args, "file", outputPosition.String())
+		} else { // A static file is its own source
+			outputPosition.File = path.Join(engine.InputDir, f.Ref.File)
+			args = append(args, "file", outputPosition.String())
+		}
+		args = append(args,
+			"role", f.Ref.Role,
+			"category", string(f.Category),
+			"reason", f.Reason,
+		)
+		engine.Logger.Warn("Validation finding", args...)
+	}
+
+	if engine.Strict && len(findings) > 0 {
+		return fmt.Errorf("%d validation findings, and strict mode is enabled", len(findings))
+	}
+
+	return nil
+}
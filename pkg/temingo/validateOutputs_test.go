@@ -0,0 +1,130 @@
+package temingo
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newValidatingEngine writes files into a fresh input directory and returns an
+// engine validating it, logging into buf.
+func newValidatingEngine(t *testing.T, files map[string]string, buf *bytes.Buffer) *Engine {
+	t.Helper()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine := DefaultEngine()
+	engine.InputDir = src + string(filepath.Separator)
+	engine.OutputDir = filepath.Join(dir, "output") + string(filepath.Separator)
+	engine.TemingoignorePath = filepath.Join(dir, ".temingoignore")
+	engine.Logger = slog.New(slog.NewTextHandler(buf, nil))
+	engine.NoRemoteChecks = true
+	engine.Validate = true
+	return &engine
+}
+
+// TestRenderValidatesBeforeBeautifying is the regression test for a partial
+// leaving an element unclosed: the beautifier would quietly close it, so the
+// broken markup has to be found in the output as rendered.
+func TestRenderValidatesBeforeBeautifying(t *testing.T) {
+	var buf bytes.Buffer
+	engine := newValidatingEngine(t, map[string]string{
+		"header.partial.html": `<header><nav>`,
+		"index.template.html": "<html>\n<body>\n{{ template \"header.partial.html\" }}</header>\n<p>x</p>\n</body>\n</html>\n",
+		"data.template.json":  `{"a": 1,}`,
+		"static.svg":          `<svg><g></svg>`,
+	}, &buf)
+	engine.Beautify = true
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() = %v, want nil - findings must not fail a non-strict build", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"category=malformed-html", "role=nav", "file=" + filepath.Join(engine.InputDir, "index.template.html"), "output=index.html:3:",
+		"category=invalid-json", "output=data.json:1:",
+		"category=invalid-xml", "file=" + filepath.Join(engine.InputDir, "static.svg") + ":1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the log, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderValidation(t *testing.T) {
+	page := `<html><body><img src="/a.png"><div id="a"></div><div id="a"></div></body></html>`
+	files := map[string]string{"index.template.html": page, "a.png": "png"}
+
+	t.Run("off by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		engine := newValidatingEngine(t, files, &buf)
+		engine.Validate = false
+		engine.Strict = true
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() = %v, want nil without validation", err)
+		}
+		if strings.Contains(buf.String(), "Validation finding") {
+			t.Errorf("expected no validation findings, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("strict fails the build", func(t *testing.T) {
+		var buf bytes.Buffer
+		engine := newValidatingEngine(t, files, &buf)
+		engine.Strict = true
+		err := engine.Render()
+		if err == nil || !strings.Contains(err.Error(), "2 validation findings") {
+			t.Fatalf("Render() = %v, want an error about 2 validation findings", err)
+		}
+		if _, statErr := os.Stat(filepath.Join(engine.OutputDir, "index.html")); statErr == nil {
+			t.Errorf("output was written, although the strict build failed")
+		}
+	})
+
+	t.Run("allowlist accepts categories by output path", func(t *testing.T) {
+		var buf bytes.Buffer
+		engine := newValidatingEngine(t, files, &buf)
+		engine.Strict = true
+		engine.Allow = Allowlist{
+			{URL: "/index.html", Checks: []Category{"missing-alt", "duplicate-id"}},
+		}
+		if err := engine.Render(); err != nil {
+			t.Fatalf("Render() = %v, want nil with every finding allowed", err)
+		}
+	})
+}
+
+// TestRenderValidation_ExampleProjects keeps the projects temingo init creates
+// free of validation findings.
+func TestRenderValidation_ExampleProjects(t *testing.T) {
+	for _, projectType := range ProjectTypes() {
+		t.Run(projectType, func(t *testing.T) {
+			engine, _, _, err := setupTestProjectFromInitFilesWithEngine(t.TempDir(), projectType, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			engine.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+			engine.NoRemoteChecks = true
+			engine.Validate = true
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() = %v", err)
+			}
+			if strings.Contains(buf.String(), "Validation finding") {
+				t.Errorf("expected no validation findings, got:\n%s", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,513 @@
+package temingo
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"text/template"
+	"text/template/parse"
+)
+
+// valueReads records which keys of the global values, the values files, the
+// meta yamls and front matter the templates read while they are executed, so
+// checkUnusedValues can tell the ones nobody reads.
+//
+// The maps those keys end up in are tracked by their address, together with
+// the files they come from. Templates are rewritten by instrumentReads so that
+// every field access first goes through the read functions, which record each
+// key of a tracked map they pass.
+type valueReads struct {
+	maps  map[uintptr]*trackedMap
+	read  map[string]map[string]bool // The key paths read, like "author.name", by source file
+	whole map[string]map[string]bool // The key paths read with everything below them, like "author." or "" for the whole file, by source file
+}
+
+// trackedMap is a map the templates see with keys from source files.
+type trackedMap struct {
+	value   reflect.Value // Keeps the map alive, so its address is not reused during the build
+	origins []valueOrigin
+}
+
+// valueOrigin is where the keys of a tracked map are set: in any of files, at
+// the key path prefix.
+type valueOrigin struct {
+	files  []string // "" stands for the global values
+	prefix string   // Like "author.", or "" for the top level
+}
+
+func newValueReads() *valueReads {
+	return &valueReads{
+		maps:  map[uintptr]*trackedMap{},
+		read:  map[string]map[string]bool{},
+		whole: map[string]map[string]bool{},
+	}
+}
+
+// register tracks the keys of the map value, if it is one, as set in files at
+// prefix. The maps below it are not tracked; see track.
+func (reads *valueReads) register(value interface{}, files []string, prefix string) {
+	if reads == nil {
+		return
+	}
+	m := indirectInterface(reflect.ValueOf(value))
+	if m.Kind() != reflect.Map || m.IsNil() {
+		return
+	}
+	tracked, ok := reads.maps[m.Pointer()]
+	if !ok {
+		tracked = &trackedMap{value: m}
+		reads.maps[m.Pointer()] = tracked
+	}
+	for _, origin := range tracked.origins {
+		if origin.prefix == prefix && slices.Equal(origin.files, files) {
+			return
+		}
+	}
+	tracked.origins = append(tracked.origins, valueOrigin{files: files, prefix: prefix})
+}
+
+// track tracks the keys of the map value and of every map below it, as set in
+// files at prefix.
+func (reads *valueReads) track(value interface{}, files []string, prefix string) {
+	if reads == nil {
+		return
+	}
+	m := indirectInterface(reflect.ValueOf(value))
+	if m.Kind() != reflect.Map || m.IsNil() {
+		return
+	}
+	reads.register(value, files, prefix)
+	for iter := m.MapRange(); iter.Next(); {
+		reads.track(iter.Value().Interface(), files, prefix+fmt.Sprint(iter.Key().Interface())+".")
+	}
+}
+
+// access records that key of the map m was read.
+func (reads *valueReads) access(m reflect.Value, key string) {
+	tracked, ok := reads.maps[m.Pointer()]
+	if !ok {
+		return
+	}
+	for _, origin := range tracked.origins {
+		for _, file := range origin.files {
+			mark(reads.read, file, origin.prefix+key)
+		}
+	}
+}
+
+// accessWhole records that value was read with everything below it, as when
+// it is printed or passed to a function.
+func (reads *valueReads) accessWhole(value reflect.Value, seen map[uintptr]bool) {
+	value = indirectInterface(value)
+	switch value.Kind() {
+	case reflect.Map:
+		if value.IsNil() || seen[value.Pointer()] {
+			return
+		}
+		seen[value.Pointer()] = true
+		if tracked, ok := reads.maps[value.Pointer()]; ok {
+			for _, origin := range tracked.origins {
+				for _, file := range origin.files {
+					mark(reads.whole, file, origin.prefix)
+				}
+			}
+		}
+		for iter := value.MapRange(); iter.Next(); {
+			reads.accessWhole(iter.Value(), seen)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			reads.accessWhole(value.Index(i), seen)
+		}
+	}
+}
+
+// unused returns the key paths below prefix of fields, the values set in
+// file, that were not read. Those in always count as read with everything
+// below them. A key below one that was not read is not returned itself.
+func (reads *valueReads) unused(file string, fields interface{}, prefix string, always map[string]bool) []string {
+	m := indirectInterface(reflect.ValueOf(fields))
+	if m.Kind() != reflect.Map || reads.whole[file][prefix] {
+		return nil
+	}
+	var unused []string
+	for iter := m.MapRange(); iter.Next(); {
+		keyPath := prefix + fmt.Sprint(iter.Key().Interface())
+		if always[keyPath] {
+			continue
+		}
+		if !reads.read[file][keyPath] {
+			unused = append(unused, keyPath)
+			continue
+		}
+		unused = append(unused, reads.unused(file, iter.Value().Interface(), keyPath+".", always)...)
+	}
+	return unused
+}
+
+func mark(set map[string]map[string]bool, file string, keyPath string) {
+	if set[file] == nil {
+		set[file] = map[string]bool{}
+	}
+	set[file][keyPath] = true
+}
+
+// readMode is what a template does with a value it reads.
+type readMode int
+
+const (
+	readPath    readMode = iota // Looks further into it, as dot or a variable
+	readWhole                   // Prints it or passes it to a function
+	readEntries                 // Ranges over it
+)
+
+// readFuncNames are the names of the functions instrumented templates call for
+// each readMode.
+var readFuncNames = map[readMode]string{
+	readPath:    "_read",
+	readWhole:   "_readWhole",
+	readEntries: "_readEntries",
+}
+
+// receiverFuncSuffix marks the read functions that return the receiver of a
+// field access held under receiverKey, as in (_readReceiver "a" $v)._.a.
+// Returned on its own, text/template would take an interface value out of its
+// interface before the access, so it would fail with a different type.
+const (
+	receiverFuncSuffix = "Receiver"
+	receiverKey        = "_"
+)
+
+// funcs returns the functions instrumentReads has templates call. Each takes
+// the keys read from a value and then the value, and returns the value
+// unchanged, so the access itself happens as written. The value comes last so
+// it is evaluated last, as it would have been.
+func (reads *valueReads) funcs() template.FuncMap {
+	funcs := template.FuncMap{}
+	for mode, name := range readFuncNames {
+		funcs[name] = func(args ...reflect.Value) reflect.Value {
+			keys, receiver := args[:len(args)-1], args[len(args)-1]
+			reads.readKeys(receiver, keys, mode)
+			return receiver
+		}
+		funcs[name+receiverFuncSuffix] = func(args ...reflect.Value) reflect.Value {
+			keys, receiver := args[:len(args)-1], args[len(args)-1]
+			reads.readKeys(receiver, keys, mode)
+			if !receiver.IsValid() { // Accessing a field of it yields no value, as it would have
+				return receiver
+			}
+			holder := reflect.MakeMap(reflect.MapOf(reflect.TypeOf(receiverKey), receiver.Type()))
+			holder.SetMapIndex(reflect.ValueOf(receiverKey), receiver)
+			return holder
+		}
+	}
+	return funcs
+}
+
+// readKeys records the keys read from receiver, following keys through the
+// maps below it as far as they are maps.
+func (reads *valueReads) readKeys(receiver reflect.Value, keys []reflect.Value, mode readMode) {
+	value := receiver
+	for _, key := range keys {
+		value = indirectInterface(value)
+		key = indirectInterface(key)
+		if value.Kind() != reflect.Map || value.IsNil() || !key.IsValid() {
+			return
+		}
+		reads.access(value, fmt.Sprint(key.Interface()))
+		if !key.Type().AssignableTo(value.Type().Key()) {
+			return
+		}
+		if value = value.MapIndex(key); !value.IsValid() {
+			return
+		}
+	}
+
+	switch mode {
+	case readWhole:
+		reads.accessWhole(value, map[uintptr]bool{})
+	case readEntries:
+		if value = indirectInterface(value); value.Kind() == reflect.Map && !value.IsNil() {
+			for _, key := range value.MapKeys() {
+				reads.access(value, fmt.Sprint(key.Interface()))
+			}
+		}
+	}
+}
+
+// instrumentReads rewrites every field access in the templates of tmpl, like
+// .meta.title, $post.title or (index . "x").y, so it first passes its receiver
+// and keys through the read functions. It returns the error context of every
+// node it changed or added, as written, for restoreErrorContexts.
+func instrumentReads(tmpl *template.Template) map[string]string {
+	rewriter := readRewriter{contexts: map[string]string{}}
+	seen := map[*parse.Tree]bool{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil || seen[t.Tree] {
+			continue
+		}
+		seen[t.Tree] = true
+		rewriter.tree = t.Tree
+		rewriter.original = map[parse.Node]errorContext{}
+		walkNodes(t.Tree.Root, func(node parse.Node) {
+			location, context := t.Tree.ErrorContext(node)
+			rewriter.original[node] = errorContext{location, context}
+		})
+
+		rewriter.list(t.Tree.Root)
+
+		for node, original := range rewriter.original { // The nodes containing a rewritten one read differently now, too
+			if _, context := t.Tree.ErrorContext(node); context != original.context {
+				rewriter.contexts[original.location+" "+context] = original.context
+			}
+		}
+	}
+	return rewriter.contexts
+}
+
+type readRewriter struct {
+	tree     *parse.Tree
+	original map[parse.Node]errorContext // The error context of every node of tree as written
+	contexts map[string]string           // The error context of each original node, by location and the context of the node replacing it
+}
+
+// errorContext is where text/template reports an error at a node, and the
+// node as it shows it.
+type errorContext struct {
+	location string
+	context  string
+}
+
+// walkNodes calls visit for node and every node below it.
+func walkNodes(node parse.Node, visit func(parse.Node)) {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return
+	}
+	visit(node)
+	switch node := node.(type) {
+	case *parse.ListNode:
+		for _, child := range node.Nodes {
+			walkNodes(child, visit)
+		}
+	case *parse.ActionNode:
+		walkNodes(node.Pipe, visit)
+	case *parse.IfNode:
+		walkBranch(&node.BranchNode, visit)
+	case *parse.RangeNode:
+		walkBranch(&node.BranchNode, visit)
+	case *parse.WithNode:
+		walkBranch(&node.BranchNode, visit)
+	case *parse.TemplateNode:
+		walkNodes(node.Pipe, visit)
+	case *parse.PipeNode:
+		for _, variable := range node.Decl {
+			walkNodes(variable, visit)
+		}
+		for _, cmd := range node.Cmds {
+			walkNodes(cmd, visit)
+		}
+	case *parse.CommandNode:
+		for _, arg := range node.Args {
+			walkNodes(arg, visit)
+		}
+	case *parse.ChainNode:
+		walkNodes(node.Node, visit)
+	}
+}
+
+func walkBranch(branch *parse.BranchNode, visit func(parse.Node)) {
+	walkNodes(branch.Pipe, visit)
+	walkNodes(branch.List, visit)
+	walkNodes(branch.ElseList, visit)
+}
+
+func (r *readRewriter) list(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, node := range list.Nodes {
+		switch node := node.(type) {
+		case *parse.ActionNode:
+			if len(node.Pipe.Decl) > 0 {
+				r.pipe(node.Pipe, readPath)
+			} else {
+				r.pipe(node.Pipe, readWhole)
+			}
+		case *parse.IfNode:
+			r.pipe(node.Pipe, readPath)
+			r.list(node.List)
+			r.list(node.ElseList)
+		case *parse.WithNode:
+			r.pipe(node.Pipe, readPath)
+			r.list(node.List)
+			r.list(node.ElseList)
+		case *parse.RangeNode:
+			r.pipe(node.Pipe, readEntries)
+			r.list(node.List)
+			r.list(node.ElseList)
+		case *parse.TemplateNode:
+			r.pipe(node.Pipe, readPath)
+		}
+	}
+}
+
+// pipe rewrites the commands of pipe, whose value is used as mode says. Every
+// command but the last passes its value on to a function.
+func (r *readRewriter) pipe(pipe *parse.PipeNode, mode readMode) {
+	if pipe == nil {
+		return
+	}
+	for i, cmd := range pipe.Cmds {
+		if i < len(pipe.Cmds)-1 {
+			r.command(cmd, readWhole)
+		} else {
+			r.command(cmd, mode)
+		}
+	}
+}
+
+func (r *readRewriter) command(cmd *parse.CommandNode, mode readMode) {
+	argMode := readWhole // Arguments are passed to a function or method
+	switch first := cmd.Args[0].(type) {
+	case *parse.IdentifierNode:
+		switch first.Ident {
+		case "index": // Reads its keys from its first argument
+			if len(cmd.Args) > 2 {
+				for i := 2; i < len(cmd.Args); i++ {
+					cmd.Args[i] = r.arg(cmd.Args[i], readWhole)
+				}
+				receiver := r.arg(cmd.Args[1], readPath)
+				cmd.Args[1] = r.call(cmd.Args[1], readFuncNames[mode], receiver, cmd.Args[2:])
+				return
+			}
+		case "capture": // Executes a template with its second argument as dot
+			argMode = readPath
+		}
+		for i := 1; i < len(cmd.Args); i++ {
+			cmd.Args[i] = r.arg(cmd.Args[i], argMode)
+		}
+	default:
+		if len(cmd.Args) > 1 { // A method call, like .date.Format "2006"
+			mode = readPath
+		}
+		cmd.Args[0] = r.arg(cmd.Args[0], mode)
+		for i := 1; i < len(cmd.Args); i++ {
+			cmd.Args[i] = r.arg(cmd.Args[i], argMode)
+		}
+	}
+}
+
+// arg returns node rewritten to record its reads, for a value used as mode
+// says.
+func (r *readRewriter) arg(node parse.Node, mode readMode) parse.Node {
+	switch node := node.(type) {
+	case *parse.FieldNode:
+		receiver := &parse.DotNode{NodeType: parse.NodeDot, Pos: node.Pos}
+		r.remember(node, receiver)
+		return r.field(node, receiver, node.Ident, mode)
+	case *parse.VariableNode:
+		if len(node.Ident) > 1 {
+			receiver := &parse.VariableNode{NodeType: parse.NodeVariable, Pos: node.Pos, Ident: node.Ident[:1]}
+			r.remember(node, receiver)
+			return r.field(node, receiver, node.Ident[1:], mode)
+		}
+		if mode != readPath {
+			return r.call(node, readFuncNames[mode], node, nil)
+		}
+	case *parse.ChainNode:
+		return r.field(node, r.arg(node.Node, readPath), node.Field, mode)
+	case *parse.DotNode:
+		if mode != readPath {
+			return r.call(node, readFuncNames[mode], node, nil)
+		}
+	case *parse.PipeNode:
+		r.pipe(node, mode)
+	}
+	return node
+}
+
+// field returns the field access of node, reading fields from receiver, as
+// a chain on the read function: (_readReceiver "a" "b" receiver)._.a.b
+func (r *readRewriter) field(node parse.Node, receiver parse.Node, fields []string, mode readMode) parse.Node {
+	keys := make([]parse.Node, 0, len(fields))
+	for _, field := range fields {
+		key := &parse.StringNode{NodeType: parse.NodeString, Pos: node.Position(), Quoted: strconv.Quote(field), Text: field}
+		r.remember(node, key) // Errors of the call can name the key
+		keys = append(keys, key)
+	}
+	function := readFuncNames[mode] + receiverFuncSuffix
+	chain := &parse.ChainNode{NodeType: parse.NodeChain, Pos: node.Position(), Node: r.call(node, function, receiver, keys), Field: append([]string{receiverKey}, fields...)}
+	r.remember(node, chain)
+	return chain
+}
+
+// call returns a pipeline calling the read function name with receiver and
+// keys, in place of node.
+func (r *readRewriter) call(node parse.Node, name string, receiver parse.Node, keys []parse.Node) *parse.PipeNode {
+	function := &parse.IdentifierNode{NodeType: parse.NodeIdentifier, Pos: node.Position(), Ident: name}
+	pipe := &parse.PipeNode{NodeType: parse.NodePipe, Pos: node.Position(), Cmds: []*parse.CommandNode{{
+		NodeType: parse.NodeCommand,
+		Pos:      node.Position(),
+		Args:     append(append([]parse.Node{function}, keys...), receiver),
+	}}}
+	r.remember(node, pipe)
+	r.remember(node, function)
+	return pipe
+}
+
+// remember keeps the error context of node, as written, for errors at
+// replacement.
+func (r *readRewriter) remember(node parse.Node, replacement parse.Node) {
+	original, ok := r.original[node]
+	if !ok {
+		original.location, original.context = r.tree.ErrorContext(node)
+	}
+	_, replacementContext := r.tree.ErrorContext(replacement)
+	r.contexts[original.location+" "+replacementContext] = original.context
+}
+
+// executingRe matches the start of an error of text/template while executing,
+// with its location and context.
+var executingRe = regexp.MustCompile(`template: (\S+): executing ("(?:[^"\\]|\\.)*") at <(.*?)>: `)
+
+// restoreErrorContexts returns err with the nodes instrumentReads rewrote
+// shown as they were written, so errors read the same as without it. The
+// original error is wrapped, so it can still be inspected with errors.As.
+func restoreErrorContexts(err error, contexts map[string]string) error {
+	message := err.Error()
+	restored := executingRe.ReplaceAllStringFunc(message, func(match string) string {
+		submatch := executingRe.FindStringSubmatch(match)
+		context, ok := contexts[submatch[1]+" "+submatch[3]]
+		if !ok {
+			return match
+		}
+		return fmt.Sprintf("template: %s: executing %s at <%s>: ", submatch[1], submatch[2], context)
+	})
+	if restored == message {
+		return err
+	}
+	if execErr, ok := err.(template.ExecError); ok {
+		return template.ExecError{Name: execErr.Name, Err: &restoredError{message: restored, err: execErr.Err}}
+	}
+	return &restoredError{message: restored, err: err}
+}
+
+// restoredError is an error whose message restoreErrorContexts restored.
+type restoredError struct {
+	message string
+	err     error
+}
+
+func (e *restoredError) Error() string { return e.message }
+
+func (e *restoredError) Unwrap() error { return e.err }
+
+// indirectInterface returns the value inside the interface value, if it is one.
+func indirectInterface(value reflect.Value) reflect.Value {
+	if value.Kind() == reflect.Interface && !value.IsNil() {
+		return value.Elem()
+	}
+	return value
+}
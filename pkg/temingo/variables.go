@@ -5,5 +5,6 @@ var (
 	defaultTemplateExtension     string = ".template"
 	defaultMetaTemplateExtension string = ".metatemplate"
 	defaultPartialExtension      string = ".partial"
+	defaultComponentExtension    string = ".component"
 	defaultMetaFilename          string = "meta.yaml"
 )
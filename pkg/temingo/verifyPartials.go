@@ -29,7 +29,8 @@ func (engine *Engine) verifyPartials(partialFiles map[string]string) error {
 
 		_, err = temporaryTemplateEngine.Parse(content) // Parse the partial into the temporary template engine
 		if err != nil {
-			return err
+			sources := map[string]templateSource{temporaryTemplateEngineName: {path: partialPath, lineOffset: partialLineOffset}}
+			return engine.locateTemplateError(err, sources, "parsing partial "+partialPath)
 		}
 		partialName = strings.TrimPrefix(temporaryTemplateEngine.DefinedTemplates(), "; defined templates are: ") // Prefix comes from the offical text.template library
 		partialName = strings.ReplaceAll(partialName, "\"", "")                                                   // remove '"'
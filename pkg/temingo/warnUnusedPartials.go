@@ -2,27 +2,36 @@ package temingo
 
 import "regexp"
 
-var templateCallRe = regexp.MustCompile(`\{\{-?\s*template\s+"([^"]+)"`)
+// templateCallRe matches the name of a {{ template }} action or a capture call
+// with a literal name.
+var templateCallRe = regexp.MustCompile(`(?:\{\{-?\s*template|\bcapture)\s+"([^"]+)"`)
 
 // warnUnusedPartials logs a warning for each defined partial that is never referenced
 // by any template or other partial content.
 func (engine *Engine) warnUnusedPartials(partialFiles map[string]string, allTemplateContents []string) {
 	referenced := map[string]bool{}
 	for _, content := range allTemplateContents {
-		for _, match := range templateCallRe.FindAllStringSubmatch(content, -1) {
-			referenced[match[1]] = true
+		for _, name := range engine.calledPartials(content) {
+			referenced[name] = true
 		}
 	}
 	// Also scan partial content itself so partials-calling-partials are counted
 	for _, content := range partialFiles {
-		for _, match := range templateCallRe.FindAllStringSubmatch(content, -1) {
-			referenced[match[1]] = true
+		for _, name := range engine.calledPartials(content) {
+			referenced[name] = true
 		}
 	}
 
 	for partialPath := range partialFiles {
+		if _, ok := engine.libraryFiles[partialPath]; ok {
+			continue // A library ships partials for many sites, not all of which use each
+		}
 		if !referenced[partialPath] {
-			engine.Logger.Warn("Unused partial", "path", partialPath)
+			if engine.isComponent(partialPath) {
+				engine.Logger.Warn("Unused component", "path", partialPath)
+			} else {
+				engine.Logger.Warn("Unused partial", "path", partialPath)
+			}
 		}
 	}
 }
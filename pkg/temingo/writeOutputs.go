@@ -0,0 +1,274 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/thetillhoff/fileIO"
+)
+
+// writeOutputs brings the outputDir in line with the build: it writes the
+// rendered templates and static files whose content changed, and deletes the
+// outputs that no longer exist.
+//
+// Unless noDeleteOutputDir is set, anything in the outputDir the build did not
+// produce is deleted, which leaves the same tree the old delete-and-recreate
+// did without touching unchanged files. With noDeleteOutputDir only the outputs
+// of a previous build are deleted, and static files are not copied at all.
+func (engine *Engine) writeOutputs(renderedTemplates map[string][]byte, staticPaths []string) error {
+	logger := engine.Logger
+
+	var (
+		err    error
+		wanted = map[string]bool{}
+	)
+
+	if engine.manifest == nil {
+		if engine.manifest, err = engine.loadOutputManifest(); err != nil {
+			return err
+		}
+	}
+
+	// Ensure output directory permissions match input directory
+	inputDirInfo, err := os.Stat(engine.InputDir)
+	if err != nil {
+		return fmt.Errorf("error getting input directory info: %w", err)
+	}
+	if err = os.MkdirAll(engine.OutputDir, inputDirInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("recreating output directory %s: %w", engine.OutputDir, err)
+	}
+	if err = os.Chmod(engine.OutputDir, inputDirInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("error setting output directory permissions: %w", err)
+	}
+
+	if !engine.NoDeleteOutputDir {
+		for _, staticPath := range staticPaths {
+			wanted[staticPath] = true
+			if err = engine.writeStaticFile(staticPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	for templatePath, renderedTemplate := range renderedTemplates { // includes both templates and metaTemplates
+		wanted[templatePath] = true
+		if err = engine.writeRenderedTemplate(templatePath, renderedTemplate); err != nil {
+			return err
+		}
+	}
+
+	if !engine.NoDeleteOutputDir {
+		if err = engine.deleteUnwantedOutputs(wanted); err != nil {
+			return err
+		}
+	} else if engine.manifest.owned {
+		for outputPath := range engine.manifest.files {
+			if wanted[outputPath] {
+				continue
+			}
+			err = os.Remove(path.Join(engine.OutputDir, outputPath))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("removing stale output file %s: %w", outputPath, err)
+			}
+			engine.changedOutputs = append(engine.changedOutputs, outputPath)
+			logger.Debug("Deleting stale output file", "path", outputPath)
+		}
+	}
+
+	// From here on the manifest lists exactly this build's outputs
+	for outputPath := range engine.manifest.files {
+		if !wanted[outputPath] {
+			delete(engine.manifest.files, outputPath)
+		}
+	}
+	engine.manifest.owned = true
+
+	return engine.saveOutputManifest()
+}
+
+// deleteUnwantedOutputs deletes every file in the outputDir that is not
+// wanted, and then every directory that was left empty.
+func (engine *Engine) deleteUnwantedOutputs(wanted map[string]bool) error {
+	var directories []string
+
+	err := filepath.WalkDir(engine.OutputDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(engine.OutputDir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if entry.IsDir() {
+			directories = append(directories, filePath)
+			return nil
+		}
+		if wanted[rel] {
+			return nil
+		}
+		if err = os.Remove(filePath); err != nil {
+			return fmt.Errorf("removing stale output file %s: %w", rel, err)
+		}
+		engine.changedOutputs = append(engine.changedOutputs, rel)
+		engine.Logger.Debug("Deleting stale output file", "path", rel)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("clearing output directory %s: %w", engine.OutputDir, err)
+	}
+
+	// Deepest first, so a directory holding only empty directories goes as well
+	for i := len(directories) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(directories[i])
+		if err != nil {
+			return fmt.Errorf("clearing output directory %s: %w", engine.OutputDir, err)
+		}
+		if len(entries) == 0 {
+			if err = os.Remove(directories[i]); err != nil {
+				return fmt.Errorf("clearing output directory %s: %w", engine.OutputDir, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// deleteOutputs deletes the files at outputPaths from the outputDir, and then
+// their directories if that left them empty.
+func (engine *Engine) deleteOutputs(outputPaths []string) error {
+	for _, outputPath := range outputPaths {
+		err := os.Remove(path.Join(engine.OutputDir, outputPath))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("removing stale output file %s: %w", outputPath, err)
+		}
+		delete(engine.manifest.files, outputPath)
+		engine.changedOutputs = append(engine.changedOutputs, outputPath)
+		engine.Logger.Debug("Deleting stale output file", "path", outputPath)
+
+		for folder := path.Dir(outputPath); folder != "."; folder = path.Dir(folder) {
+			entries, err := os.ReadDir(path.Join(engine.OutputDir, folder))
+			if err != nil || len(entries) > 0 {
+				break
+			}
+			if err = os.Remove(path.Join(engine.OutputDir, folder)); err != nil {
+				return fmt.Errorf("clearing output directory %s: %w", engine.OutputDir, err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeStaticFile copies one static file into the outputDir, unless the copy
+// there already has the same content. With Minify, a static file of a type
+// minify handles is minified on the way, and keeps its permissions.
+func (engine *Engine) writeStaticFile(staticPath string) error {
+	content, err := fileIO.ReadFile(path.Join(engine.InputDir, staticPath))
+	if err != nil {
+		return fmt.Errorf("reading static file %s: %w", staticPath, err)
+	}
+	minify := engine.Minify && slices.Contains(minifiedExtensions, path.Ext(staticPath))
+	if minify {
+		content = engine.minify(content, path.Ext(staticPath))
+	}
+	if engine.manifest.unchanged(engine.OutputDir, staticPath, content) {
+		engine.Logger.Debug("Skipping unchanged static file", "path", path.Join(engine.OutputDir, staticPath))
+		return nil
+	}
+
+	if minify {
+		err = engine.writeMinifiedStaticFile(staticPath, content)
+	} else {
+		err = fileIO.CopyFile(path.Join(engine.InputDir, staticPath), path.Join(engine.OutputDir, staticPath))
+	}
+	if err != nil {
+		return fmt.Errorf("copying static file %s: %w", staticPath, err)
+	}
+	if err = engine.manifest.record(engine.OutputDir, staticPath, content); err != nil {
+		return fmt.Errorf("recording static file %s: %w", staticPath, err)
+	}
+	engine.changedOutputs = append(engine.changedOutputs, staticPath)
+	engine.Logger.Debug("Writing static file", "path", path.Join(engine.OutputDir, staticPath))
+	return nil
+}
+
+// writeMinifiedStaticFile writes the minified content of a static file into
+// the outputDir, with the permissions of the static file.
+func (engine *Engine) writeMinifiedStaticFile(staticPath string, content []byte) error {
+	info, err := os.Stat(path.Join(engine.InputDir, staticPath))
+	if err != nil {
+		return err
+	}
+	outputFilePath := path.Join(engine.OutputDir, staticPath)
+	if err = fileIO.WriteFile(outputFilePath, content); err != nil {
+		return err
+	}
+	return os.Chmod(outputFilePath, info.Mode().Perm())
+}
+
+// writeRenderedTemplate writes one rendered file into the outputDir, with the
+// permissions of the inputDir, unless the file there already has the same
+// content.
+func (engine *Engine) writeRenderedTemplate(templatePath string, renderedTemplate []byte) error {
+	logger := engine.Logger
+
+	if engine.manifest.unchanged(engine.OutputDir, templatePath, renderedTemplate) {
+		logger.Debug("Skipping unchanged rendered template", "path", path.Join(engine.OutputDir, templatePath))
+		return nil
+	}
+
+	if engine.NoDeleteOutputDir {
+		if _, err := os.Stat(path.Join(engine.OutputDir, templatePath)); err == nil {
+			err = os.Remove(path.Join(engine.OutputDir, templatePath))
+			if err != nil {
+				return fmt.Errorf("removing existing output file %s: %w", path.Join(engine.OutputDir, templatePath), err)
+			}
+			logger.Debug("Deleting existing rendered template", "path", path.Join(engine.OutputDir, templatePath))
+		}
+	}
+
+	// Get permissions from input directory (used for both files and parent directories)
+	// For template files, we use input directory permissions as the source of truth
+	inputDirInfo, err := os.Stat(engine.InputDir)
+	if err != nil {
+		return fmt.Errorf("error getting input directory info: %w", err)
+	}
+	fileMode := inputDirInfo.Mode().Perm()
+
+	// Ensure parent directory exists with same permissions as input directory
+	outputFilePath := path.Join(engine.OutputDir, templatePath)
+	outputDirPath := path.Dir(outputFilePath)
+	if err := os.MkdirAll(outputDirPath, fileMode); err != nil {
+		return fmt.Errorf("error creating output directory %s: %w", outputDirPath, err)
+	}
+
+	// Use Chmod to ensure exact permissions (MkdirAll may be affected by umask)
+	if err := os.Chmod(outputDirPath, fileMode); err != nil {
+		return fmt.Errorf("error setting output directory permissions %s: %w", outputDirPath, err)
+	}
+
+	err = fileIO.WriteFile(outputFilePath, renderedTemplate)
+	if err != nil {
+		return fmt.Errorf("writing output file %s: %w", outputFilePath, err)
+	}
+
+	// Set file permissions to match input directory permissions
+	if err := os.Chmod(outputFilePath, fileMode); err != nil {
+		return fmt.Errorf("error setting permissions for %s: %w", outputFilePath, err)
+	}
+
+	if err = engine.manifest.record(engine.OutputDir, templatePath, renderedTemplate); err != nil {
+		return fmt.Errorf("recording output file %s: %w", outputFilePath, err)
+	}
+	engine.changedOutputs = append(engine.changedOutputs, templatePath)
+	logger.Debug("Writing rendered template", "path", outputFilePath)
+	return nil
+}
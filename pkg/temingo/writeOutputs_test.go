@@ -0,0 +1,212 @@
+package temingo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupWriteTestEngine returns an engine over a fresh input and output
+// directory holding the given input files.
+func setupWriteTestEngine(t *testing.T, files map[string]string) (*Engine, string, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+	writeTestFiles(t, inputDir, files)
+
+	engine := DefaultEngine()
+	engine.InputDir = inputDir + string(filepath.Separator)
+	engine.OutputDir = outputDir + string(filepath.Separator)
+	engine.TemingoignorePath = filepath.Join(tmpDir, ".temingoignore")
+	engine.NoRemoteChecks = true
+
+	return &engine, inputDir, outputDir
+}
+
+// backdate sets the mtime of the output files to a fixed time in the past, so
+// a rewrite is visible even within the filesystem's timestamp granularity.
+func backdate(t *testing.T, outputDir string, outputPaths ...string) time.Time {
+	t.Helper()
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, outputPath := range outputPaths {
+		if err := os.Chtimes(filepath.Join(outputDir, outputPath), past, past); err != nil {
+			t.Fatalf("Failed to backdate %s: %v", outputPath, err)
+		}
+	}
+	return past
+}
+
+func modTime(t *testing.T, outputDir string, outputPath string) time.Time {
+	t.Helper()
+	info, err := os.Stat(filepath.Join(outputDir, outputPath))
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", outputPath, err)
+	}
+	return info.ModTime()
+}
+
+func TestRender_SkipsUnchangedFiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		freshEngine bool
+	}{
+		{name: "same engine, as in watch mode", freshEngine: false},
+		{name: "fresh engine seeded from the output directory", freshEngine: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+				"index.template.html": "home",
+				"about.template.html": "about",
+				"static/style.css":    "body {}",
+			})
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+			past := backdate(t, outputDir, "index.html", "about.html", "static/style.css")
+
+			if tt.freshEngine {
+				fresh := *engine
+				fresh.manifest = nil
+				fresh.lastBuild = nil
+				engine = &fresh
+			} else {
+				// The backdating itself is a change the manifest has to notice, so it
+				// is recorded as though temingo had written those timestamps.
+				engine.manifest = nil
+			}
+
+			writeTestFiles(t, inputDir, map[string]string{"about.template.html": "about us"})
+			if err := engine.Render(); err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+
+			for _, unchanged := range []string{"index.html", "static/style.css"} {
+				if got := modTime(t, outputDir, unchanged); !got.Equal(past) {
+					t.Errorf("Render() rewrote unchanged %s (mtime %v)", unchanged, got)
+				}
+			}
+			if got := modTime(t, outputDir, "about.html"); got.Equal(past) {
+				t.Errorf("Render() did not rewrite changed about.html")
+			}
+			if got := readOutput(t, outputDir, "about.html"); got != "about us" {
+				t.Errorf("Render() about.html = %q, want %q", got, "about us")
+			}
+		})
+	}
+}
+
+func TestRender_RewritesOutputEditedByHand(t *testing.T) {
+	engine, _, outputDir := setupWriteTestEngine(t, map[string]string{"index.template.html": "home"})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	markOutputs(t, outputDir, "index.html")
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	if got := readOutput(t, outputDir, "index.html"); got != "home" {
+		t.Errorf("Render() index.html = %q, want the edit replaced with %q", got, "home")
+	}
+}
+
+func TestRender_DeletesOutputsThatNoLongerExist(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html":     "home",
+		"old/index.template.html": "old",
+	})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	writeTestFiles(t, outputDir, map[string]string{"leftover.txt": "not from this build"})
+
+	if err := os.RemoveAll(filepath.Join(inputDir, "old")); err != nil {
+		t.Fatalf("Failed to remove input folder: %v", err)
+	}
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	for _, gone := range []string{"old/index.html", "old", "leftover.txt"} {
+		if _, err := os.Stat(filepath.Join(outputDir, gone)); !os.IsNotExist(err) {
+			t.Errorf("Render() should have deleted %s", gone)
+		}
+	}
+	if got := readOutput(t, outputDir, "index.html"); got != "home" {
+		t.Errorf("Render() index.html = %q, want %q", got, "home")
+	}
+}
+
+func TestRender_PersistedManifestWithNoDeleteOutputDir(t *testing.T) {
+	engine, inputDir, outputDir := setupWriteTestEngine(t, map[string]string{
+		"index.template.html": "home",
+		"old.template.html":   "old",
+	})
+	engine.NoDeleteOutputDir = true
+	engine.CacheDir = ".temingo-cache/"
+	writeTestFiles(t, outputDir, map[string]string{"foreign.txt": "kept"})
+
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	// A relative cache directory sits next to the inputDir, not in the working directory
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.Clean(inputDir)), ".temingo-cache", outputManifestFilename)); err != nil {
+		t.Fatalf("Render() should persist the manifest in the cache directory: %v", err)
+	}
+
+	// A new process only knows which outputs are its own from the manifest
+	fresh := *engine
+	fresh.manifest = nil
+	fresh.lastBuild = nil
+	if err := os.Remove(filepath.Join(inputDir, "old.template.html")); err != nil {
+		t.Fatalf("Failed to remove template: %v", err)
+	}
+	if err := fresh.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "old.html")); !os.IsNotExist(err) {
+		t.Errorf("Render() should delete the output of a removed template")
+	}
+	if got := readOutput(t, outputDir, "foreign.txt"); got != "kept" {
+		t.Errorf("Render() with NoDeleteOutputDir must keep files it did not produce, got %q", got)
+	}
+}
+
+func TestChangedOutputs(t *testing.T) {
+	engine, inputDir, _ := setupWriteTestEngine(t, map[string]string{
+		"index.template.html": "home",
+		"about.template.html": "about",
+		"style.css":           "body {}",
+	})
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if got := strings.Join(engine.ChangedOutputs(), ","); got != "about.html,index.html,style.css" {
+		t.Errorf("ChangedOutputs() after first build = %v, want every output", got)
+	}
+
+	writeTestFiles(t, inputDir, map[string]string{"style.css": "body { color: red; }"})
+	if err := engine.RenderChanged(filepath.Join(inputDir, "style.css")); err != nil {
+		t.Fatalf("RenderChanged() unexpected error: %v", err)
+	}
+	if got := strings.Join(engine.ChangedOutputs(), ","); got != "style.css" {
+		t.Errorf("ChangedOutputs() after a stylesheet change = %v, want [style.css]", got)
+	}
+
+	if err := os.Remove(filepath.Join(inputDir, "about.template.html")); err != nil {
+		t.Fatalf("Failed to remove template: %v", err)
+	}
+	if err := engine.Render(); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if got := strings.Join(engine.ChangedOutputs(), ","); got != "about.html" {
+		t.Errorf("ChangedOutputs() after removing a template = %v, want the deleted [about.html]", got)
+	}
+}